@@ -2,10 +2,13 @@ package main
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
 	"net/http"
 	"os"
 	"os/signal"
+	"path/filepath"
+	"strings"
 	"syscall"
 	"time"
 
@@ -14,10 +17,18 @@ import (
 	"go.uber.org/zap"
 	"go.uber.org/zap/zapcore"
 
+	"github.com/grafana-cloud-k6-prometheus-exporter/internal/annotations"
+	"github.com/grafana-cloud-k6-prometheus-exporter/internal/api"
 	"github.com/grafana-cloud-k6-prometheus-exporter/internal/collector"
 	"github.com/grafana-cloud-k6-prometheus-exporter/internal/config"
+	"github.com/grafana-cloud-k6-prometheus-exporter/internal/guard"
 	"github.com/grafana-cloud-k6-prometheus-exporter/internal/k6client"
+	"github.com/grafana-cloud-k6-prometheus-exporter/internal/notify"
+	"github.com/grafana-cloud-k6-prometheus-exporter/internal/otlp"
+	"github.com/grafana-cloud-k6-prometheus-exporter/internal/probe"
+	"github.com/grafana-cloud-k6-prometheus-exporter/internal/remotewrite"
 	"github.com/grafana-cloud-k6-prometheus-exporter/internal/state"
+	"github.com/grafana-cloud-k6-prometheus-exporter/internal/web"
 )
 
 var (
@@ -43,38 +54,160 @@ func main() {
 		logger.Fatal("failed to load configuration", zap.Error(err))
 	}
 
-	// Validate API token is not empty
-	if cfg.K6APIToken == "" {
-		logger.Fatal("K6_API_TOKEN environment variable is required")
-	}
-
 	logger.Info("configuration loaded",
-		zap.String("api_url", cfg.K6APIURL),
-		zap.String("stack_id", cfg.GrafanaStackID),
+		zap.Int("stacks", len(cfg.GetStacks())),
 		zap.Int("port", cfg.Port),
 		zap.Duration("test_cache_ttl", cfg.TestCacheTTL),
 		zap.Duration("state_cleanup_interval", cfg.StateCleanupInterval),
-		zap.Strings("projects", cfg.Projects),
+		zap.Duration("ready_max_staleness", cfg.ReadyMaxStaleness),
+		zap.Bool("guard_enabled", cfg.GuardEnabled),
 	)
 
-	// Create k6 API client
-	apiClient := k6client.NewClient(cfg.GetAPIBaseURL(), cfg.GrafanaStackID, cfg.K6APIToken, logger)
+	// Create the HTTP client for the k6 API, shared by the stacks
+	httpClient, err := k6client.NewHTTPClient(k6client.TransportConfig{
+		ProxyURL:       cfg.K6APIProxyURL,
+		NoProxy:        cfg.K6APINoProxy,
+		CAFile:         cfg.K6APICAFile,
+		ClientCertFile: cfg.K6APIClientCertFile,
+		ClientKeyFile:  cfg.K6APIClientKeyFile,
+		MaxIdleConns:   cfg.K6APIMaxIdleConns,
+		KeepAlive:      cfg.K6APIKeepAlive,
+		Timeout:        cfg.APITimeout,
+	})
+	if err != nil {
+		logger.Fatal("failed to configure the k6 API transport", zap.Error(err))
+	}
 
-	// Create state manager
+	// Create state manager, shared by the stacks
 	stateManager := state.NewManager(logger)
 
-	// Create collector
-	k6Collector := collector.NewCollector(apiClient, stateManager, cfg, logger)
+	// Create a client and collector per stack. With multiple stacks every metric of a
+	// stack gets a stack label and a failing stack does not affect the others.
+	stacks := cfg.GetStacks()
+	stackConfigs := make([]*config.Config, 0, len(stacks))
+	stackRegisterers := make([]prometheus.Registerer, 0, len(stacks))
+	stackHTTPClients := make([]*http.Client, 0, len(stacks))
+	collectors := make([]*collector.Collector, 0, len(stacks))
+	for _, stack := range stacks {
+		stackCfg := cfg.ForStack(stack)
+		stackLogger := logger
+		reg := prometheus.DefaultRegisterer
+		if stack.Name != "" {
+			stackLogger = logger.With(zap.String("stack", stack.Name))
+			reg = prometheus.WrapRegistererWith(prometheus.Labels{"stack": stack.Name}, prometheus.DefaultRegisterer)
+		}
+
+		stackLogger.Info("monitoring stack",
+			zap.String("api_url", stack.K6APIURL),
+			zap.String("stack_id", stack.GrafanaStackID),
+			zap.Strings("projects", stack.Projects),
+		)
+
+		// Create k6 API client, reading a rotating token from its file if configured
+		apiClient := k6client.NewClient(stackCfg.GetAPIBaseURL(), stack.GrafanaStackID, stack.K6APIToken, stackLogger)
+		if stack.K6APITokenFile != "" {
+			tokenFile, err := k6client.NewTokenFile(stack.K6APITokenFile, stackLogger, reg)
+			if err != nil {
+				stackLogger.Fatal("failed to read API token file", zap.Error(err))
+			}
+			apiClient = k6client.NewClientWithTokenFile(stackCfg.GetAPIBaseURL(), stack.GrafanaStackID, tokenFile, stackLogger)
+		}
+		// Record the API responses of the stack, or replay recorded ones instead of calling the API
+		stackHTTPClient, err := fixtureHTTPClient(cfg, stack.Name, httpClient, stackLogger)
+		if err != nil {
+			stackLogger.Fatal("failed to configure API fixtures", zap.Error(err))
+		}
+		limiter := k6client.NewRateLimiter(cfg.K6APIRateLimit, cfg.K6APIRateLimitBurst, reg)
+		apiClient.WithHTTPClient(stackHTTPClient).WithRateLimiter(limiter)
+
+		// Stop hammering the k6 API during outages, the last snapshot is served meanwhile
+		if cfg.BreakerEnabled() {
+			breaker := k6client.NewCircuitBreaker(k6client.BreakerConfig{
+				ConsecutiveFailures: cfg.K6APIBreakerFailures,
+				ErrorRatio:          cfg.K6APIBreakerErrorRatio,
+				Window:              cfg.K6APIBreakerWindow,
+				OpenTimeout:         cfg.K6APIBreakerOpenTimeout,
+			}, stackLogger, reg)
+			apiClient.WithCircuitBreaker(breaker)
+		}
+
+		// Revalidate unchanged lists instead of downloading them again
+		if cfg.K6APICacheSize > 0 {
+			apiClient.WithCache(k6client.NewResponseCache(cfg.K6APICacheSize, reg))
+		}
+
+		// Create collector and register it with Prometheus
+		k6Collector := collector.NewCollectorWithRegistry(apiClient, stateManager, stackCfg, stackLogger, reg)
+		if replayer, ok := stackHTTPClient.Transport.(*k6client.Replayer); ok {
+			// Replays compute the fetch window and run durations at the time of the recording
+			k6Collector.WithClock(replayer.Now)
+		}
+		reg.MustRegister(k6Collector)
+
+		stackConfigs = append(stackConfigs, stackCfg)
+		stackRegisterers = append(stackRegisterers, reg)
+		stackHTTPClients = append(stackHTTPClients, stackHTTPClient)
+		collectors = append(collectors, k6Collector)
+	}
 
-	// Register collector with Prometheus
-	prometheus.MustRegister(k6Collector)
+	// Create the API before polling starts so its event stream sees the first updates
+	apiHandler := api.NewHandler(collectors, stateManager, cfg, logger)
 
 	// Create context for background tasks
 	ctx, cancel := context.WithCancel(context.Background())
 	defer cancel()
 
+	// Send test run events to the configured notification receivers
+	if cfg.NotificationsConfigFile != "" {
+		notifyConfig, err := notify.LoadConfig(cfg.NotificationsConfigFile)
+		if err != nil {
+			logger.Fatal("failed to load notifications config", zap.Error(err))
+		}
+		dispatcher := notify.NewDispatcher(notifyConfig, logger, prometheus.DefaultRegisterer).
+			WithStarterName(cfg.StarterName)
+		stateManager.Subscribe(dispatcher.Handle)
+		dispatcher.Start(ctx)
+	}
+
+	// Mark test run windows on Grafana dashboards
+	if cfg.GrafanaAnnotationsURL != "" {
+		annotator := annotations.NewAnnotator(cfg, stateManager, logger, prometheus.DefaultRegisterer)
+		stateManager.Subscribe(annotator.Handle)
+		annotator.Start(ctx)
+	}
+
 	// Start background tasks
-	k6Collector.StartBackgroundTasks(ctx)
+	for _, k6Collector := range collectors {
+		k6Collector.StartBackgroundTasks(ctx)
+	}
+
+	// Push the same series served on /metrics for environments that cannot be scraped
+	if cfg.RemoteWriteURL != "" {
+		pusher := remotewrite.NewPusher(cfg, prometheus.DefaultGatherer, logger, prometheus.DefaultRegisterer)
+		pusher.Start(ctx)
+	}
+
+	// Export the same series to an OpenTelemetry collector
+	if cfg.OTLPEndpoint != "" {
+		otlpExporter := otlp.NewExporter(cfg, prometheus.DefaultGatherer, version, logger, prometheus.DefaultRegisterer)
+		otlpExporter.Start(ctx)
+	}
+
+	// Start a guard per stack that aborts runaway test runs, using its own token. The guard
+	// bypasses the rate limiter and circuit breaker of the stack, so aborts neither wait
+	// behind polling nor count towards its failures.
+	if cfg.GuardEnabled {
+		for i, stackCfg := range stackConfigs {
+			guardLogger := logger
+			if stackCfg.StackName != "" {
+				guardLogger = logger.With(zap.String("stack", stackCfg.StackName))
+			}
+			guardClient := k6client.NewClient(stackCfg.GetAPIBaseURL(), stackCfg.GrafanaStackID, stackCfg.GuardAPIToken, guardLogger).
+				WithHTTPClient(stackHTTPClients[i])
+			runGuard := guard.NewGuard(guardClient, stateManager, stackCfg, guardLogger, stackRegisterers[i])
+			runGuard.Start(ctx)
+		}
+	}
 
 	// Setup HTTP server
 	mux := http.NewServeMux()
@@ -82,12 +215,20 @@ func main() {
 	// Metrics endpoint
 	mux.Handle("/metrics", promhttp.Handler())
 
-	// Health check endpoint
+	// Multi-target endpoint, the metrics of a single stack or project per scrape
+	mux.Handle(probe.Path, probe.NewHandler(collectors, logger))
+
+	// Liveness endpoint, always OK while the process serves requests
 	mux.HandleFunc("/health", func(w http.ResponseWriter, r *http.Request) {
-		w.WriteHeader(http.StatusOK)
-		fmt.Fprintf(w, "OK\n")
+		writeHealth(w, http.StatusOK, newHealthResponse("ok", "", collectors))
 	})
 
+	// Readiness endpoint, OK once the k6 API of any stack was fetched successfully and recently
+	mux.HandleFunc("/ready", readyHandler(collectors, cfg.ReadyMaxStaleness))
+
+	// Read-only JSON API and event stream for test runs and tests
+	mux.Handle(api.Prefix, apiHandler)
+
 	// Version endpoint
 	mux.HandleFunc("/version", func(w http.ResponseWriter, r *http.Request) {
 		w.Header().Set("Content-Type", "application/json")
@@ -103,10 +244,26 @@ func main() {
 		IdleTimeout:  60 * time.Second,
 	}
 
+	// Apply TLS and basic auth from the web config file
+	var webConfig *web.Config
+	if cfg.WebConfigFile != "" {
+		webConfig, err = web.LoadConfig(cfg.WebConfigFile)
+		if err != nil {
+			logger.Fatal("failed to load web config", zap.Error(err))
+		}
+		if err := web.Apply(srv, webConfig, logger); err != nil {
+			logger.Fatal("failed to apply web config", zap.Error(err))
+		}
+	}
+
 	// Start server in goroutine
 	go func() {
-		logger.Info("starting HTTP server", zap.String("addr", srv.Addr))
-		if err := srv.ListenAndServe(); err != nil && err != http.ErrServerClosed {
+		logger.Info("starting HTTP server",
+			zap.String("addr", srv.Addr),
+			zap.Bool("tls", webConfig != nil && webConfig.TLSEnabled()),
+			zap.Bool("basic_auth", webConfig != nil && len(webConfig.BasicAuthUsers) > 0),
+		)
+		if err := web.ListenAndServe(srv, webConfig); err != nil && err != http.ErrServerClosed {
 			logger.Fatal("failed to start HTTP server", zap.Error(err))
 		}
 	}()
@@ -132,6 +289,94 @@ func main() {
 	logger.Info("exporter stopped")
 }
 
+// healthResponse is the body of the /health and /ready endpoints. With a single
+// stack its health is inlined, with multiple stacks it is reported per stack.
+type healthResponse struct {
+	Status string `json:"status"`
+	Reason string `json:"reason,omitempty"`
+	*collector.Health
+	Stacks map[string]collector.Health `json:"stacks,omitempty"`
+}
+
+// fixtureHTTPClient returns the HTTP client of a stack, wrapped to record or replay the
+// k6 API responses if configured. Each named stack uses its own subdirectory.
+func fixtureHTTPClient(cfg *config.Config, stackName string, httpClient *http.Client, logger *zap.Logger) (*http.Client, error) {
+	var transport http.RoundTripper
+	switch {
+	case cfg.K6APIRecordDir != "":
+		recorder, err := k6client.NewRecorder(filepath.Join(cfg.K6APIRecordDir, stackName), httpClient.Transport)
+		if err != nil {
+			return nil, err
+		}
+		logger.Warn("recording k6 API responses", zap.String("dir", filepath.Join(cfg.K6APIRecordDir, stackName)))
+		transport = recorder
+	case cfg.K6APIReplayDir != "":
+		replayer, err := k6client.NewReplayer(filepath.Join(cfg.K6APIReplayDir, stackName), logger)
+		if err != nil {
+			return nil, err
+		}
+		logger.Warn("replaying recorded k6 API responses, the k6 API is not called")
+		transport = replayer
+	default:
+		return httpClient, nil
+	}
+
+	fixtureClient := *httpClient
+	fixtureClient.Transport = transport
+	return &fixtureClient, nil
+}
+
+// newHealthResponse creates a health response for the collectors
+func newHealthResponse(status, reason string, collectors []*collector.Collector) healthResponse {
+	response := healthResponse{Status: status, Reason: reason}
+	if len(collectors) == 1 && collectors[0].Stack() == "" {
+		health := collectors[0].Health()
+		response.Health = &health
+		return response
+	}
+
+	response.Stacks = make(map[string]collector.Health, len(collectors))
+	for _, c := range collectors {
+		response.Stacks[c.Stack()] = c.Health()
+	}
+	return response
+}
+
+// readyHandler serves 200 once the k6 API of any stack was fetched successfully and recently, 503 otherwise
+func readyHandler(collectors []*collector.Collector, maxStaleness time.Duration) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if ready, reason := stacksReady(collectors, maxStaleness, time.Now()); !ready {
+			writeHealth(w, http.StatusServiceUnavailable, newHealthResponse("not_ready", reason, collectors))
+			return
+		}
+		writeHealth(w, http.StatusOK, newHealthResponse("ready", "", collectors))
+	}
+}
+
+// stacksReady reports whether any stack is ready, so one failing stack does not
+// take the exporter out of service. Otherwise it returns the reason of each stack.
+func stacksReady(collectors []*collector.Collector, maxStaleness time.Duration, now time.Time) (bool, string) {
+	reasons := make([]string, 0, len(collectors))
+	for _, c := range collectors {
+		ready, reason := c.Health().Ready(maxStaleness, now)
+		if ready {
+			return true, ""
+		}
+		if c.Stack() != "" {
+			reason = c.Stack() + ": " + reason
+		}
+		reasons = append(reasons, reason)
+	}
+	return false, strings.Join(reasons, "; ")
+}
+
+// writeHealth writes a health response as JSON
+func writeHealth(w http.ResponseWriter, statusCode int, body healthResponse) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(statusCode)
+	json.NewEncoder(w).Encode(body)
+}
+
 // initLogger initializes the zap logger
 func initLogger() *zap.Logger {
 	// Check if we're in production mode
@@ -0,0 +1,48 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap/zaptest"
+
+	"github.com/grafana-cloud-k6-prometheus-exporter/internal/collector"
+	"github.com/grafana-cloud-k6-prometheus-exporter/internal/config"
+	"github.com/grafana-cloud-k6-prometheus-exporter/internal/k6client"
+	"github.com/grafana-cloud-k6-prometheus-exporter/internal/k6fake"
+	"github.com/grafana-cloud-k6-prometheus-exporter/internal/state"
+)
+
+func TestReadyWithRejectedToken(t *testing.T) {
+	logger := zaptest.NewLogger(t)
+	server := k6fake.NewServer("token", "stack")
+	defer server.Close()
+	server.AddProject(k6client.Project{ID: 100, Name: "Checkout"})
+	server.AddTest(k6client.Test{ID: 1, ProjectID: 100, Name: "Checkout flow"})
+
+	cfg := &config.Config{
+		Projects:             []string{"100"},
+		TestCacheTTL:         time.Hour,
+		StateCleanupInterval: 5 * time.Minute,
+		APITimeout:           5 * time.Second,
+	}
+	client := k6client.NewClient(server.URL, "stack", "revoked", logger)
+	k6Collector := collector.NewCollectorWithRegistry(client, state.NewManager(logger), cfg, logger, prometheus.NewRegistry())
+	require.Error(t, k6Collector.Refresh(context.Background()))
+
+	rec := httptest.NewRecorder()
+	readyHandler([]*collector.Collector{k6Collector}, 5*time.Minute)(rec, httptest.NewRequest(http.MethodGet, "/ready", nil))
+	assert.Equal(t, http.StatusServiceUnavailable, rec.Code)
+
+	var body map[string]any
+	require.NoError(t, json.NewDecoder(rec.Body).Decode(&body))
+	assert.Equal(t, "not_ready", body["status"])
+	assert.Equal(t, k6client.ErrorClassUnauthorized, body["last_error_class"])
+}
@@ -0,0 +1,361 @@
+package annotations
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"go.uber.org/zap"
+
+	"github.com/grafana-cloud-k6-prometheus-exporter/internal/config"
+	"github.com/grafana-cloud-k6-prometheus-exporter/internal/state"
+)
+
+// Events waiting to be annotated before new ones are dropped
+const queueSize = 100
+
+// Timeout of a single Grafana API request
+const requestTimeout = 10 * time.Second
+
+// Operations reported in metrics
+const (
+	OperationStart  = "start"
+	OperationFinish = "finish"
+)
+
+// Outcomes reported in metrics
+const (
+	OutcomeSuccess = "success"
+	OutcomeFailure = "failure"
+	OutcomeDropped = "dropped"
+)
+
+// Annotation is a Grafana annotation as sent to and returned by the HTTP API
+type Annotation struct {
+	ID           int64    `json:"id,omitempty"`
+	DashboardUID string   `json:"dashboardUID,omitempty"`
+	Time         int64    `json:"time"`              // Unix milliseconds
+	TimeEnd      int64    `json:"timeEnd,omitempty"` // Unix milliseconds
+	Tags         []string `json:"tags"`
+	Text         string   `json:"text"`
+}
+
+// Metrics holds the annotation metrics
+type Metrics struct {
+	AnnotationsTotal *prometheus.CounterVec
+}
+
+// NewMetrics creates the annotation metrics and registers them if a registerer is provided
+func NewMetrics(reg prometheus.Registerer) *Metrics {
+	metrics := &Metrics{
+		AnnotationsTotal: prometheus.NewCounterVec(
+			prometheus.CounterOpts{
+				Name: "k6_exporter_grafana_annotations_total",
+				Help: "Total number of Grafana annotation updates by operation and outcome",
+			},
+			[]string{"operation", "outcome"},
+		),
+	}
+
+	if reg != nil {
+		reg.MustRegister(metrics.AnnotationsTotal)
+	}
+
+	return metrics
+}
+
+// Annotator marks test runs on Grafana dashboards. An annotation is created
+// when a run starts and gets its end time, result and tags when it finishes.
+type Annotator struct {
+	baseURL       string
+	token         string
+	dashboardUIDs []string
+	client        *http.Client
+	stateManager  *state.Manager
+	queue         chan state.Event
+	metrics       *Metrics
+	logger        *zap.Logger
+}
+
+// NewAnnotator creates an annotator for the Grafana instance of the configuration
+func NewAnnotator(cfg *config.Config, stateManager *state.Manager, logger *zap.Logger, reg prometheus.Registerer) *Annotator {
+	dashboardUIDs := cfg.GrafanaAnnotationsDashboardUIDs
+	if len(dashboardUIDs) == 0 {
+		// A single organization-wide annotation
+		dashboardUIDs = []string{""}
+	}
+
+	return &Annotator{
+		baseURL:       strings.TrimRight(cfg.GrafanaAnnotationsURL, "/"),
+		token:         cfg.GrafanaAnnotationsToken,
+		dashboardUIDs: dashboardUIDs,
+		client:        &http.Client{Timeout: requestTimeout},
+		stateManager:  stateManager,
+		queue:         make(chan state.Event, queueSize),
+		metrics:       NewMetrics(reg),
+		logger:        logger.Named("annotations"),
+	}
+}
+
+// Handle queues run start and finish events. It never blocks and can be
+// registered as a state.Listener.
+func (a *Annotator) Handle(event state.Event) {
+	operation := operationFor(event.Type)
+	if operation == "" {
+		return
+	}
+
+	select {
+	case a.queue <- event:
+	default:
+		a.metrics.AnnotationsTotal.WithLabelValues(operation, OutcomeDropped).Inc()
+		a.logger.Warn("annotation queue is full, dropping event",
+			zap.String("event", event.Type),
+			zap.Int("run_id", event.RunID),
+		)
+	}
+}
+
+// Start annotates the queued events in order until the context is cancelled
+func (a *Annotator) Start(ctx context.Context) {
+	a.logger.Info("grafana annotations enabled",
+		zap.String("url", a.baseURL),
+		zap.Strings("dashboard_uids", a.dashboardUIDs),
+	)
+
+	go func() {
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case event := <-a.queue:
+				a.annotate(ctx, event)
+			}
+		}
+	}()
+}
+
+// annotate applies a single event and records its outcome
+func (a *Annotator) annotate(ctx context.Context, event state.Event) {
+	operation := operationFor(event.Type)
+
+	var err error
+	switch operation {
+	case OperationStart:
+		err = a.start(ctx, event)
+	case OperationFinish:
+		err = a.finish(ctx, event)
+	}
+
+	outcome := OutcomeSuccess
+	if err != nil {
+		outcome = OutcomeFailure
+		a.logger.Error("failed to annotate test run",
+			zap.String("operation", operation),
+			zap.Int("run_id", event.RunID),
+			zap.Error(err),
+		)
+	}
+	a.metrics.AnnotationsTotal.WithLabelValues(operation, outcome).Inc()
+}
+
+// start creates the annotations of a started run that it does not have yet
+func (a *Annotator) start(ctx context.Context, event state.Event) error {
+	existing := a.stateManager.GetAnnotationIDs(event.Stack, event.RunID)
+
+	for _, dashboardUID := range a.dashboardUIDs {
+		if _, exists := existing[dashboardUID]; exists {
+			continue
+		}
+
+		annotation := Annotation{
+			DashboardUID: dashboardUID,
+			Time:         event.Created.UnixMilli(),
+			Tags:         runTags(event, ""),
+			Text:         startText(event),
+		}
+		id, err := a.create(ctx, annotation)
+		if err != nil {
+			return err
+		}
+		a.stateManager.SetAnnotationID(event.Stack, event.RunID, dashboardUID, id)
+	}
+	return nil
+}
+
+// finish sets the end of the annotations of a finished run, creating the ones
+// that are missing. Annotations created before a restart are found by their run tag.
+func (a *Annotator) finish(ctx context.Context, event state.Event) error {
+	ids := a.stateManager.GetAnnotationIDs(event.Stack, event.RunID)
+	if len(ids) == 0 {
+		found, err := a.find(ctx, event)
+		if err != nil {
+			return err
+		}
+		ids = found
+	}
+
+	for _, dashboardUID := range a.dashboardUIDs {
+		annotation := Annotation{
+			DashboardUID: dashboardUID,
+			Time:         event.Created.UnixMilli(),
+			TimeEnd:      event.Time.UnixMilli(),
+			Tags:         runTags(event, event.Result),
+			Text:         finishText(event),
+		}
+
+		if id, exists := ids[dashboardUID]; exists {
+			if err := a.update(ctx, id, annotation); err != nil {
+				return err
+			}
+			continue
+		}
+		if _, err := a.create(ctx, annotation); err != nil {
+			return err
+		}
+	}
+
+	a.stateManager.RemoveAnnotationIDs(event.Stack, event.RunID)
+	return nil
+}
+
+// create posts a new annotation and returns its ID
+func (a *Annotator) create(ctx context.Context, annotation Annotation) (int64, error) {
+	var created struct {
+		ID int64 `json:"id"`
+	}
+	if err := a.do(ctx, http.MethodPost, "/api/annotations", annotation, &created); err != nil {
+		return 0, fmt.Errorf("create annotation: %w", err)
+	}
+	return created.ID, nil
+}
+
+// update replaces the time range, tags and text of an annotation
+func (a *Annotator) update(ctx context.Context, id int64, annotation Annotation) error {
+	annotation.DashboardUID = ""
+	if err := a.do(ctx, http.MethodPatch, fmt.Sprintf("/api/annotations/%d", id), annotation, nil); err != nil {
+		return fmt.Errorf("update annotation %d: %w", id, err)
+	}
+	return nil
+}
+
+// find returns the existing annotations of a run by dashboard UID. Runs of other
+// stacks with the same ID are told apart by their stack tag.
+func (a *Annotator) find(ctx context.Context, event state.Event) (map[string]int64, error) {
+	query := url.Values{}
+	query.Add("tags", runTag(event.RunID))
+	if event.Stack != "" {
+		query.Add("tags", "stack:"+event.Stack)
+	}
+	query.Set("type", "annotation")
+	query.Set("limit", "100")
+
+	var found []Annotation
+	if err := a.do(ctx, http.MethodGet, "/api/annotations?"+query.Encode(), nil, &found); err != nil {
+		return nil, fmt.Errorf("find annotations: %w", err)
+	}
+
+	ids := make(map[string]int64, len(found))
+	for _, annotation := range found {
+		ids[annotation.DashboardUID] = annotation.ID
+	}
+	return ids, nil
+}
+
+// do sends a request to the Grafana HTTP API and decodes the response into result if set
+func (a *Annotator) do(ctx context.Context, method, path string, body, result interface{}) error {
+	var reader io.Reader
+	if body != nil {
+		data, err := json.Marshal(body)
+		if err != nil {
+			return fmt.Errorf("encode request: %w", err)
+		}
+		reader = bytes.NewReader(data)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, method, a.baseURL+path, reader)
+	if err != nil {
+		return fmt.Errorf("create request: %w", err)
+	}
+	req.Header.Set("Authorization", "Bearer "+a.token)
+	req.Header.Set("Accept", "application/json")
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+
+	resp, err := a.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("perform request: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		message, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+		return fmt.Errorf("unexpected status %d: %s", resp.StatusCode, strings.TrimSpace(string(message)))
+	}
+
+	if result != nil {
+		if err := json.NewDecoder(resp.Body).Decode(result); err != nil {
+			return fmt.Errorf("decode response: %w", err)
+		}
+	}
+	return nil
+}
+
+// operationFor returns the operation of an event type, or "" if it is not annotated
+func operationFor(eventType string) string {
+	switch eventType {
+	case state.EventRunStarted:
+		return OperationStart
+	case state.EventRunFinished:
+		return OperationFinish
+	}
+	return ""
+}
+
+// runTags returns the tags of a run annotation, the result is only known once it finished
+func runTags(event state.Event, result string) []string {
+	tags := []string{
+		"k6",
+		fmt.Sprintf("project:%d", event.ProjectID),
+		"test:" + event.TestName,
+		runTag(event.RunID),
+	}
+	if event.Stack != "" {
+		tags = append(tags, "stack:"+event.Stack)
+	}
+	if result != "" {
+		tags = append(tags, "result:"+result)
+	}
+	return tags
+}
+
+// runTag identifies the annotations of a run
+func runTag(runID int) string {
+	return fmt.Sprintf("k6_run:%d", runID)
+}
+
+// startText describes a running test
+func startText(event state.Event) string {
+	text := fmt.Sprintf("k6 test %s started", event.TestName)
+	if event.StartedBy != "" {
+		text += " by " + event.StartedBy
+	}
+	return text
+}
+
+// finishText describes a finished test
+func finishText(event state.Event) string {
+	text := fmt.Sprintf("k6 test %s %s", event.TestName, event.Result)
+	if event.StartedBy != "" {
+		text += ", started by " + event.StartedBy
+	}
+	return text
+}
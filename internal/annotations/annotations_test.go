@@ -0,0 +1,192 @@
+package annotations
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap/zaptest"
+
+	"github.com/grafana-cloud-k6-prometheus-exporter/internal/config"
+	"github.com/grafana-cloud-k6-prometheus-exporter/internal/state"
+)
+
+// fakeGrafana stores annotations like the Grafana HTTP API
+type fakeGrafana struct {
+	mu          sync.Mutex
+	nextID      int64
+	annotations map[int64]Annotation
+	creates     int
+	updates     int
+}
+
+func newFakeGrafana() *fakeGrafana {
+	return &fakeGrafana{nextID: 1, annotations: make(map[int64]Annotation)}
+}
+
+func (g *fakeGrafana) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	if r.Header.Get("Authorization") != "Bearer glsa_token" {
+		w.WriteHeader(http.StatusUnauthorized)
+		return
+	}
+
+	switch {
+	case r.Method == http.MethodPost && r.URL.Path == "/api/annotations":
+		var annotation Annotation
+		json.NewDecoder(r.Body).Decode(&annotation)
+		annotation.ID = g.nextID
+		g.nextID++
+		g.annotations[annotation.ID] = annotation
+		g.creates++
+		json.NewEncoder(w).Encode(map[string]interface{}{"id": annotation.ID, "message": "Annotation added"})
+
+	case r.Method == http.MethodPatch && strings.HasPrefix(r.URL.Path, "/api/annotations/"):
+		id, _ := strconv.ParseInt(strings.TrimPrefix(r.URL.Path, "/api/annotations/"), 10, 64)
+		existing, ok := g.annotations[id]
+		if !ok {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		var patch Annotation
+		json.NewDecoder(r.Body).Decode(&patch)
+		existing.TimeEnd = patch.TimeEnd
+		existing.Tags = patch.Tags
+		existing.Text = patch.Text
+		g.annotations[id] = existing
+		g.updates++
+		json.NewEncoder(w).Encode(map[string]string{"message": "Annotation patched"})
+
+	case r.Method == http.MethodGet && r.URL.Path == "/api/annotations":
+		tag := r.URL.Query().Get("tags")
+		found := make([]Annotation, 0)
+		for _, annotation := range g.annotations {
+			for _, t := range annotation.Tags {
+				if t == tag {
+					found = append(found, annotation)
+				}
+			}
+		}
+		json.NewEncoder(w).Encode(found)
+
+	default:
+		w.WriteHeader(http.StatusNotFound)
+	}
+}
+
+func newTestAnnotator(t *testing.T, url string, stateManager *state.Manager, dashboardUIDs ...string) *Annotator {
+	cfg := &config.Config{
+		GrafanaAnnotationsURL:           url,
+		GrafanaAnnotationsToken:         "glsa_token",
+		GrafanaAnnotationsDashboardUIDs: dashboardUIDs,
+	}
+	return NewAnnotator(cfg, stateManager, zaptest.NewLogger(t), prometheus.NewRegistry())
+}
+
+func runEvents() (state.Event, state.Event) {
+	created := time.Date(2024, 1, 1, 10, 0, 0, 0, time.UTC)
+	started := state.Event{
+		Type:      state.EventRunStarted,
+		RunID:     42,
+		TestName:  "checkout",
+		ProjectID: 100,
+		StartedBy: "alice@example.com",
+		Created:   created,
+		Time:      created,
+	}
+	finished := started
+	finished.Type = state.EventRunFinished
+	finished.Result = "failed"
+	finished.Time = created.Add(10 * time.Minute)
+	return started, finished
+}
+
+func TestAnnotatorStartAndFinish(t *testing.T) {
+	grafana := newFakeGrafana()
+	server := httptest.NewServer(grafana)
+	defer server.Close()
+
+	stateManager := state.NewManager(zaptest.NewLogger(t))
+	annotator := newTestAnnotator(t, server.URL, stateManager, "checkout-dash", "platform-dash")
+	ctx := context.Background()
+
+	started, finished := runEvents()
+	annotator.annotate(ctx, started)
+	annotator.annotate(ctx, started) // Duplicate events do not create new annotations
+
+	assert.Equal(t, 2, grafana.creates)
+	assert.Len(t, stateManager.GetAnnotationIDs("", 42), 2)
+
+	annotator.annotate(ctx, finished)
+
+	assert.Equal(t, 2, grafana.creates)
+	assert.Equal(t, 2, grafana.updates)
+	assert.Empty(t, stateManager.GetAnnotationIDs("", 42))
+	for _, annotation := range grafana.annotations {
+		assert.Equal(t, finished.Time.UnixMilli(), annotation.TimeEnd)
+		assert.Contains(t, annotation.Tags, "project:100")
+		assert.Contains(t, annotation.Tags, "test:checkout")
+		assert.Contains(t, annotation.Tags, "result:failed")
+	}
+	assert.Equal(t, float64(1), testutil.ToFloat64(annotator.metrics.AnnotationsTotal.WithLabelValues(OperationFinish, OutcomeSuccess)))
+}
+
+func TestAnnotatorFinishAfterRestart(t *testing.T) {
+	grafana := newFakeGrafana()
+	server := httptest.NewServer(grafana)
+	defer server.Close()
+
+	started, finished := runEvents()
+	annotator := newTestAnnotator(t, server.URL, state.NewManager(zaptest.NewLogger(t)))
+	annotator.annotate(context.Background(), started)
+
+	// A new state manager has no annotation IDs, the annotation is found by its run tag
+	annotator = newTestAnnotator(t, server.URL, state.NewManager(zaptest.NewLogger(t)))
+	annotator.annotate(context.Background(), finished)
+
+	assert.Equal(t, 1, grafana.creates)
+	assert.Equal(t, 1, grafana.updates)
+}
+
+func TestAnnotatorFinishWithoutStart(t *testing.T) {
+	grafana := newFakeGrafana()
+	server := httptest.NewServer(grafana)
+	defer server.Close()
+
+	_, finished := runEvents()
+	annotator := newTestAnnotator(t, server.URL, state.NewManager(zaptest.NewLogger(t)))
+	annotator.annotate(context.Background(), finished)
+
+	require.Len(t, grafana.annotations, 1)
+	annotation := grafana.annotations[1]
+	assert.Empty(t, annotation.DashboardUID, "organization-wide without dashboard UIDs")
+	assert.Equal(t, finished.Created.UnixMilli(), annotation.Time)
+	assert.Equal(t, finished.Time.UnixMilli(), annotation.TimeEnd)
+}
+
+func TestAnnotatorFailure(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusForbidden)
+	}))
+	defer server.Close()
+
+	started, _ := runEvents()
+	stateManager := state.NewManager(zaptest.NewLogger(t))
+	annotator := newTestAnnotator(t, server.URL, stateManager)
+	annotator.annotate(context.Background(), started)
+
+	assert.Empty(t, stateManager.GetAnnotationIDs("", 42))
+	assert.Equal(t, float64(1), testutil.ToFloat64(annotator.metrics.AnnotationsTotal.WithLabelValues(OperationStart, OutcomeFailure)))
+}
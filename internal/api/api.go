@@ -0,0 +1,275 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"go.uber.org/zap"
+
+	"github.com/grafana-cloud-k6-prometheus-exporter/internal/collector"
+	"github.com/grafana-cloud-k6-prometheus-exporter/internal/config"
+	"github.com/grafana-cloud-k6-prometheus-exporter/internal/k6client"
+	"github.com/grafana-cloud-k6-prometheus-exporter/internal/state"
+)
+
+// Prefix is the path all API endpoints are served under
+const Prefix = "/api/v1/"
+
+// Handler serves the read-only JSON API for test runs and tests
+type Handler struct {
+	collectors   []*collector.Collector
+	stateManager *state.Manager
+	config       *config.Config
+	events       *EventStream
+	logger       *zap.Logger
+	mux          *http.ServeMux
+}
+
+// NewHandler creates the API handler for the collectors of all stacks and subscribes
+// its event stream to the state manager. Run starters are exposed according to
+// STARTED_BY_MODE.
+func NewHandler(collectors []*collector.Collector, stateManager *state.Manager, cfg *config.Config, logger *zap.Logger) *Handler {
+	logger = logger.Named("api")
+	h := &Handler{
+		collectors:   collectors,
+		stateManager: stateManager,
+		config:       cfg,
+		events:       NewEventStream(cfg.EventsBufferSize, cfg.EventsHeartbeatInterval, logger),
+		logger:       logger,
+		mux:          http.NewServeMux(),
+	}
+	stateManager.Subscribe(func(event state.Event) {
+		event.StartedBy = cfg.StarterName(event.StartedBy)
+		h.events.Publish(event)
+	})
+
+	h.mux.HandleFunc(Prefix+"runs", h.handleRuns)
+	h.mux.HandleFunc(Prefix+"runs/", h.handleRun)
+	h.mux.HandleFunc(Prefix+"tests", h.handleTests)
+	h.mux.Handle(Prefix+"events", h.events)
+	h.mux.HandleFunc(Prefix, func(w http.ResponseWriter, r *http.Request) {
+		h.writeError(w, http.StatusNotFound, "not found")
+	})
+
+	return h
+}
+
+// ServeHTTP implements http.Handler
+func (h *Handler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		w.Header().Set("Allow", http.MethodGet)
+		h.writeError(w, http.StatusMethodNotAllowed, "method not allowed")
+		return
+	}
+	h.mux.ServeHTTP(w, r)
+}
+
+// handleRuns lists the active and recent test runs
+func (h *Handler) handleRuns(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+
+	filter, err := parseRunFilter(query)
+	if err != nil {
+		h.writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	page, err := parsePage(query)
+	if err != nil {
+		h.writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	runs := make([]Run, 0)
+	for _, run := range h.runs() {
+		if filter.matches(run) {
+			runs = append(runs, run)
+		}
+	}
+
+	start, end := page.bounds(len(runs))
+	h.writeJSON(w, http.StatusOK, RunList{Runs: runs[start:end], Page: page})
+}
+
+// handleRun returns a single test run
+func (h *Handler) handleRun(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.Atoi(strings.TrimPrefix(r.URL.Path, Prefix+"runs/"))
+	if err != nil {
+		h.writeError(w, http.StatusBadRequest, "invalid run id")
+		return
+	}
+
+	for _, run := range h.runs() {
+		if run.ID == id {
+			h.writeJSON(w, http.StatusOK, run)
+			return
+		}
+	}
+	h.writeError(w, http.StatusNotFound, "run not found")
+}
+
+// handleTests lists the tests of the monitored projects
+func (h *Handler) handleTests(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+
+	projects, err := parseIntSet(query, "project")
+	if err != nil {
+		h.writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	stacks := parseStringSet(query, "stack")
+	page, err := parsePage(query)
+	if err != nil {
+		h.writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	activeRuns := make(map[stackID]int)
+	for _, runState := range h.stateManager.GetAllStates() {
+		activeRuns[stackID{runState.Stack, runState.TestID}]++
+	}
+
+	tests := make([]Test, 0)
+	for _, c := range h.collectors {
+		if stacks != nil && !stacks[c.Stack()] {
+			continue
+		}
+		for _, summary := range c.Tests() {
+			if projects != nil && !projects[summary.Test.ProjectID] {
+				continue
+			}
+			tests = append(tests, Test{
+				ID:                  summary.Test.ID,
+				Name:                summary.Test.Name,
+				ProjectID:           summary.Test.ProjectID,
+				Stack:               c.Stack(),
+				Created:             summary.Test.Created,
+				Updated:             summary.Test.Updated,
+				LastRunStart:        optionalTime(summary.LastRunStart),
+				LastRunEnd:          optionalTime(summary.LastRunEnd),
+				LastResult:          summary.LastResult,
+				ConsecutiveFailures: summary.ConsecutiveFailures,
+				ActiveRuns:          activeRuns[stackID{c.Stack(), summary.Test.ID}],
+			})
+		}
+	}
+
+	start, end := page.bounds(len(tests))
+	h.writeJSON(w, http.StatusOK, TestList{Tests: tests[start:end], Page: page})
+}
+
+// stackID identifies a test or test run within its stack
+type stackID struct {
+	stack string
+	id    int
+}
+
+// runs returns the runs of the last snapshot of every stack merged with the tracked
+// state, newest first. Tracked runs are included even before the first snapshot.
+func (h *Handler) runs() []Run {
+	now := time.Now()
+	seen := make(map[stackID]bool)
+	runs := make([]Run, 0)
+
+	for _, c := range h.collectors {
+		snapshot := c.Snapshot()
+		if snapshot == nil {
+			continue
+		}
+		for i := range snapshot.Runs {
+			run := &snapshot.Runs[i]
+			apiRun := Run{
+				ID:              run.ID,
+				TestID:          run.TestID,
+				TestName:        c.TestName(run),
+				ProjectID:       run.ProjectID,
+				Stack:           c.Stack(),
+				Status:          run.Status,
+				Active:          !k6client.IsTerminalStatus(run.Status),
+				Created:         run.Created,
+				Ended:           run.Ended,
+				DurationSeconds: run.GetDuration(),
+				StartedBy:       h.config.StarterName(run.StartedBy),
+				VUH:             run.GetVUH(),
+				StatusHistory:   run.GetStatusTimes(),
+			}
+			if k6client.IsTerminalStatus(run.Status) {
+				apiRun.Result = run.GetResult()
+			}
+			if runState := h.stateManager.GetTestRunState(c.Stack(), run.ID); runState != nil {
+				apiRun.StatusHistory = runState.StatusHistory
+			}
+
+			runs = append(runs, apiRun)
+			seen[stackID{c.Stack(), run.ID}] = true
+		}
+	}
+
+	for _, runState := range h.stateManager.GetAllStates() {
+		if seen[stackID{runState.Stack, runState.TestRunID}] {
+			continue
+		}
+		runs = append(runs, h.runFromState(runState, now))
+	}
+
+	sort.SliceStable(runs, func(i, j int) bool {
+		if !runs[i].Created.Equal(runs[j].Created) {
+			return runs[i].Created.After(runs[j].Created)
+		}
+		return runs[i].ID > runs[j].ID
+	})
+	return runs
+}
+
+// runFromState converts a tracked test run
+func (h *Handler) runFromState(runState *state.TestRunState, now time.Time) Run {
+	run := Run{
+		ID:            runState.TestRunID,
+		TestID:        runState.TestID,
+		TestName:      runState.TestName,
+		ProjectID:     runState.ProjectID,
+		Stack:         runState.Stack,
+		Status:        runState.CurrentStatus,
+		Active:        !k6client.IsTerminalStatus(runState.CurrentStatus),
+		Created:       runState.Created,
+		Ended:         runState.Ended,
+		StartedBy:     h.config.StarterName(runState.StartedBy),
+		VUH:           runState.VUH,
+		StatusHistory: runState.StatusHistory,
+	}
+	if runState.Result != nil {
+		run.Result = *runState.Result
+	}
+
+	end := now
+	if runState.Ended != nil {
+		end = *runState.Ended
+	}
+	run.DurationSeconds = end.Sub(runState.Created).Seconds()
+	return run
+}
+
+// optionalTime returns nil for the zero time
+func optionalTime(t time.Time) *time.Time {
+	if t.IsZero() {
+		return nil
+	}
+	return &t
+}
+
+// writeJSON writes the body as JSON
+func (h *Handler) writeJSON(w http.ResponseWriter, statusCode int, body interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(statusCode)
+	if err := json.NewEncoder(w).Encode(body); err != nil {
+		h.logger.Debug("failed to write response", zap.Error(err))
+	}
+}
+
+// writeError writes an error response
+func (h *Handler) writeError(w http.ResponseWriter, statusCode int, message string) {
+	h.writeJSON(w, statusCode, errorResponse{Error: message})
+}
@@ -0,0 +1,247 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap/zaptest"
+
+	"github.com/grafana-cloud-k6-prometheus-exporter/internal/collector"
+	"github.com/grafana-cloud-k6-prometheus-exporter/internal/config"
+	"github.com/grafana-cloud-k6-prometheus-exporter/internal/k6client"
+	"github.com/grafana-cloud-k6-prometheus-exporter/internal/state"
+)
+
+func newTestHandler(t *testing.T, now time.Time) *Handler {
+	return newTestHandlerWithStarterMode(t, now, "")
+}
+
+// newTestHandlerWithStarterMode creates the handler with the given STARTED_BY_MODE
+func newTestHandlerWithStarterMode(t *testing.T, now time.Time, mode string) *Handler {
+	logger := zaptest.NewLogger(t)
+	cfg := &config.Config{
+		TestCacheTTL:         60 * time.Second,
+		StateCleanupInterval: 5 * time.Minute,
+		APITimeout:           30 * time.Second,
+		EventsBufferSize:     10,
+		StartedByMode:        mode,
+	}
+
+	passed := k6client.ResultPassed
+	client := k6client.NewMockClient()
+	client.Tests = []k6client.Test{
+		{ID: 1, Name: "Smoke", ProjectID: 100},
+		{ID: 2, Name: "Soak", ProjectID: 200},
+	}
+	client.TestRuns[1] = []k6client.TestRun{
+		{ID: 10, TestID: 1, ProjectID: 100, Status: k6client.StatusRunning, Created: now.Add(-10 * time.Minute), StartedBy: "alice@example.com"},
+		{ID: 11, TestID: 1, ProjectID: 100, Status: k6client.StatusCompleted, Result: &passed, Created: now.Add(-2 * time.Hour), Ended: timePtr(now.Add(-time.Hour))},
+	}
+	client.TestRuns[2] = []k6client.TestRun{
+		{ID: 20, TestID: 2, ProjectID: 200, Status: k6client.StatusInitializing, Created: now.Add(-time.Minute)},
+	}
+
+	stateManager := state.NewManager(logger)
+	c := collector.NewCollectorWithRegistry(client, stateManager, cfg, logger, prometheus.NewRegistry())
+	require.NoError(t, c.Refresh(context.Background()))
+
+	return NewHandler([]*collector.Collector{c}, stateManager, cfg, logger)
+}
+
+func timePtr(t time.Time) *time.Time {
+	return &t
+}
+
+func get(t *testing.T, h http.Handler, target string, body interface{}) int {
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, target, nil))
+	assert.Equal(t, "application/json", rec.Header().Get("Content-Type"))
+	if body != nil {
+		require.NoError(t, json.Unmarshal(rec.Body.Bytes(), body))
+	}
+	return rec.Code
+}
+
+func runIDs(list RunList) []int {
+	ids := make([]int, 0, len(list.Runs))
+	for _, run := range list.Runs {
+		ids = append(ids, run.ID)
+	}
+	return ids
+}
+
+func TestListRuns(t *testing.T) {
+	now := time.Now()
+	h := newTestHandler(t, now)
+
+	tests := []struct {
+		name  string
+		query string
+		want  []int
+	}{
+		{name: "all_newest_first", query: "", want: []int{20, 10, 11}},
+		{name: "by_project", query: "?project=100", want: []int{10, 11}},
+		{name: "by_test_and_status", query: "?test=1,2&status=running", want: []int{10}},
+		{name: "active_only", query: "?active=true", want: []int{20, 10}},
+		{name: "time_range", query: "?since=" + now.Add(-30*time.Minute).UTC().Format(time.RFC3339) + "&until=" + now.Add(-5*time.Minute).UTC().Format(time.RFC3339), want: []int{10}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var list RunList
+			require.Equal(t, http.StatusOK, get(t, h, "/api/v1/runs"+tt.query, &list))
+			assert.Equal(t, tt.want, runIDs(list))
+			assert.Equal(t, len(tt.want), list.Total)
+		})
+	}
+}
+
+func TestListRunsPagination(t *testing.T) {
+	h := newTestHandler(t, time.Now())
+
+	var first RunList
+	require.Equal(t, http.StatusOK, get(t, h, "/api/v1/runs?limit=2", &first))
+	assert.Equal(t, []int{20, 10}, runIDs(first))
+	assert.Equal(t, 3, first.Total)
+	require.NotNil(t, first.NextOffset)
+	assert.Equal(t, 2, *first.NextOffset)
+
+	var second RunList
+	require.Equal(t, http.StatusOK, get(t, h, "/api/v1/runs?limit=2&offset=2", &second))
+	assert.Equal(t, []int{11}, runIDs(second))
+	assert.Nil(t, second.NextOffset)
+}
+
+func TestListRunsInvalidQuery(t *testing.T) {
+	h := newTestHandler(t, time.Now())
+
+	for _, query := range []string{"?project=abc", "?since=yesterday", "?limit=0", "?offset=-1", "?active=maybe"} {
+		var resp errorResponse
+		assert.Equal(t, http.StatusBadRequest, get(t, h, "/api/v1/runs"+query, &resp), query)
+		assert.NotEmpty(t, resp.Error)
+	}
+}
+
+func TestGetRun(t *testing.T) {
+	h := newTestHandler(t, time.Now())
+
+	var run Run
+	require.Equal(t, http.StatusOK, get(t, h, "/api/v1/runs/10", &run))
+	assert.Equal(t, "Smoke", run.TestName)
+	assert.True(t, run.Active)
+	assert.Equal(t, "alice@example.com", run.StartedBy)
+	assert.Contains(t, run.StatusHistory, k6client.StatusRunning)
+
+	require.Equal(t, http.StatusOK, get(t, h, "/api/v1/runs/11", &run))
+	assert.False(t, run.Active)
+	assert.Equal(t, k6client.ResultPassed, run.Result)
+
+	assert.Equal(t, http.StatusNotFound, get(t, h, "/api/v1/runs/999", nil))
+	assert.Equal(t, http.StatusBadRequest, get(t, h, "/api/v1/runs/abc", nil))
+}
+
+func TestStarterPrivacy(t *testing.T) {
+	for _, mode := range []string{config.StartedByHash, config.StartedByRedact} {
+		t.Run(mode, func(t *testing.T) {
+			h := newTestHandlerWithStarterMode(t, time.Now(), mode)
+
+			var run Run
+			require.Equal(t, http.StatusOK, get(t, h, "/api/v1/runs/10", &run))
+			assert.NotEmpty(t, run.StartedBy)
+			assert.Equal(t, h.config.StarterName("alice@example.com"), run.StartedBy)
+
+			var list RunList
+			require.Equal(t, http.StatusOK, get(t, h, "/api/v1/runs", &list))
+			for _, run := range list.Runs {
+				assert.NotContains(t, run.StartedBy, "alice")
+			}
+
+			h.stateManager.UpdateTestRun(&state.TestRunState{
+				TestRunID:     30,
+				TestID:        1,
+				CurrentStatus: k6client.StatusRunning,
+				Created:       time.Now(),
+				StartedBy:     "alice@example.com",
+			})
+			h.events.mu.Lock()
+			defer h.events.mu.Unlock()
+			require.Len(t, h.events.buffer, 1)
+			assert.Equal(t, run.StartedBy, h.events.buffer[0].StartedBy)
+		})
+	}
+}
+
+func TestListTests(t *testing.T) {
+	h := newTestHandler(t, time.Now())
+
+	var list TestList
+	require.Equal(t, http.StatusOK, get(t, h, "/api/v1/tests", &list))
+	require.Len(t, list.Tests, 2)
+	assert.Equal(t, "Smoke", list.Tests[0].Name)
+	assert.Equal(t, 1, list.Tests[0].ActiveRuns)
+	assert.Equal(t, k6client.ResultPassed, list.Tests[0].LastResult)
+	assert.NotNil(t, list.Tests[0].LastRunEnd)
+
+	require.Equal(t, http.StatusOK, get(t, h, "/api/v1/tests?project=200", &list))
+	require.Len(t, list.Tests, 1)
+	assert.Equal(t, "Soak", list.Tests[0].Name)
+}
+
+func TestMultipleStacks(t *testing.T) {
+	logger := zaptest.NewLogger(t)
+	now := time.Now()
+	stateManager := state.NewManager(logger)
+
+	collectors := make([]*collector.Collector, 0, 2)
+	for i, stack := range []string{"prod", "staging"} {
+		client := k6client.NewMockClient()
+		client.Tests = []k6client.Test{{ID: i + 1, Name: "Smoke", ProjectID: 100}}
+		client.TestRuns[i+1] = []k6client.TestRun{
+			{ID: 10 + i, TestID: i + 1, ProjectID: 100, Status: k6client.StatusRunning, Created: now.Add(-time.Duration(i+1) * time.Minute)},
+		}
+
+		cfg := &config.Config{TestCacheTTL: 60 * time.Second, APITimeout: 30 * time.Second, StackName: stack}
+		c := collector.NewCollectorWithRegistry(client, stateManager, cfg, logger, prometheus.NewRegistry())
+		require.NoError(t, c.Refresh(context.Background()))
+		collectors = append(collectors, c)
+	}
+	h := NewHandler(collectors, stateManager, &config.Config{}, logger)
+
+	var list RunList
+	require.Equal(t, http.StatusOK, get(t, h, "/api/v1/runs", &list))
+	require.Len(t, list.Runs, 2)
+	assert.Equal(t, "prod", list.Runs[0].Stack)
+	assert.Equal(t, "staging", list.Runs[1].Stack)
+
+	require.Equal(t, http.StatusOK, get(t, h, "/api/v1/runs?stack=staging", &list))
+	assert.Equal(t, []int{11}, runIDs(list))
+
+	var tests TestList
+	require.Equal(t, http.StatusOK, get(t, h, "/api/v1/tests?stack=prod", &tests))
+	require.Len(t, tests.Tests, 1)
+	assert.Equal(t, "prod", tests.Tests[0].Stack)
+	assert.Equal(t, 1, tests.Tests[0].ActiveRuns)
+}
+
+func TestMethodNotAllowed(t *testing.T) {
+	h := newTestHandler(t, time.Now())
+
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/api/v1/runs", nil))
+	assert.Equal(t, http.StatusMethodNotAllowed, rec.Code)
+}
+
+func TestUnknownEndpoint(t *testing.T) {
+	h := newTestHandler(t, time.Now())
+
+	var resp errorResponse
+	assert.Equal(t, http.StatusNotFound, get(t, h, "/api/v1/unknown", &resp))
+	assert.Equal(t, "not found", resp.Error)
+}
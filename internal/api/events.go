@@ -0,0 +1,302 @@
+package api
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"sync"
+	"time"
+
+	"go.uber.org/zap"
+
+	"github.com/grafana-cloud-k6-prometheus-exporter/internal/state"
+)
+
+// Buffered events per connected client, slower clients are disconnected and resume with Last-Event-ID
+const subscriberBuffer = 64
+
+// Heartbeat interval used if none is configured
+const defaultHeartbeat = 15 * time.Second
+
+// Event is the API representation of a test run lifecycle event
+type Event struct {
+	ID             int64     `json:"id"`
+	Type           string    `json:"type"`
+	RunID          int       `json:"run_id"`
+	TestID         int       `json:"test_id"`
+	TestName       string    `json:"test_name"`
+	ProjectID      int       `json:"project_id"`
+	Stack          string    `json:"stack,omitempty"`
+	Status         string    `json:"status"`
+	PreviousStatus string    `json:"previous_status,omitempty"`
+	Result         string    `json:"result,omitempty"`
+	StartedBy      string    `json:"started_by,omitempty"`
+	VUH            float64   `json:"vuh"`
+	Reason         string    `json:"reason,omitempty"`
+	Time           time.Time `json:"time"`
+}
+
+// EventStream keeps the recent test run events in a ring buffer and streams them as Server-Sent Events
+type EventStream struct {
+	heartbeat time.Duration
+	logger    *zap.Logger
+
+	mu          sync.Mutex
+	buffer      []Event // Ring buffer, oldest event at start once full
+	start       int
+	lastID      int64
+	subscribers map[chan Event]struct{}
+}
+
+// NewEventStream creates an event stream keeping up to size events for resuming clients
+func NewEventStream(size int, heartbeat time.Duration, logger *zap.Logger) *EventStream {
+	if heartbeat <= 0 {
+		heartbeat = defaultHeartbeat
+	}
+	return &EventStream{
+		heartbeat:   heartbeat,
+		logger:      logger,
+		buffer:      make([]Event, 0, size),
+		subscribers: make(map[chan Event]struct{}),
+	}
+}
+
+// Publish assigns the next ID to the event, buffers it and sends it to the connected clients.
+// It can be registered as a state.Listener.
+func (s *EventStream) Publish(stateEvent state.Event) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.lastID++
+	event := Event{
+		ID:             s.lastID,
+		Type:           stateEvent.Type,
+		RunID:          stateEvent.RunID,
+		TestID:         stateEvent.TestID,
+		TestName:       stateEvent.TestName,
+		ProjectID:      stateEvent.ProjectID,
+		Stack:          stateEvent.Stack,
+		Status:         stateEvent.Status,
+		PreviousStatus: stateEvent.PreviousStatus,
+		Result:         stateEvent.Result,
+		StartedBy:      stateEvent.StartedBy,
+		VUH:            stateEvent.VUH,
+		Reason:         stateEvent.Reason,
+		Time:           stateEvent.Time,
+	}
+
+	if cap(s.buffer) > 0 {
+		if len(s.buffer) < cap(s.buffer) {
+			s.buffer = append(s.buffer, event)
+		} else {
+			s.buffer[s.start] = event
+			s.start = (s.start + 1) % len(s.buffer)
+		}
+	}
+
+	for ch := range s.subscribers {
+		select {
+		case ch <- event:
+		default:
+			// The client is too slow, disconnect it
+			delete(s.subscribers, ch)
+			close(ch)
+		}
+	}
+}
+
+// subscribe registers a client and returns the buffered events after lastID. Without
+// a lastID only new events are streamed. A lastID ahead of the stream is from before a
+// restart, the whole buffer is returned in that case.
+func (s *EventStream) subscribe(lastID *int64) (chan Event, []Event, int64) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	ch := make(chan Event, subscriberBuffer)
+	s.subscribers[ch] = struct{}{}
+
+	backlog := make([]Event, 0)
+	if lastID == nil {
+		return ch, backlog, s.lastID
+	}
+	after := *lastID
+	if after > s.lastID {
+		after = 0
+	}
+
+	for i := 0; i < len(s.buffer); i++ {
+		event := s.buffer[(s.start+i)%len(s.buffer)]
+		if event.ID > after {
+			backlog = append(backlog, event)
+		}
+	}
+	return ch, backlog, s.lastID
+}
+
+// unsubscribe removes a client
+func (s *EventStream) unsubscribe(ch chan Event) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, exists := s.subscribers[ch]; exists {
+		delete(s.subscribers, ch)
+		close(ch)
+	}
+}
+
+// ServeHTTP streams the events matching the query filters
+func (s *EventStream) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	filter, err := parseEventFilter(r.URL.Query())
+	if err != nil {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(errorResponse{Error: err.Error()})
+		return
+	}
+
+	lastID, err := parseLastEventID(r)
+	if err != nil {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(errorResponse{Error: err.Error()})
+		return
+	}
+
+	// The server write timeout does not apply to the stream
+	controller := http.NewResponseController(w)
+	if err := controller.SetWriteDeadline(time.Time{}); err != nil {
+		s.logger.Debug("failed to clear write deadline", zap.Error(err))
+	}
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("Connection", "keep-alive")
+	w.WriteHeader(http.StatusOK)
+
+	ch, backlog, lastSent := s.subscribe(lastID)
+	defer s.unsubscribe(ch)
+
+	for _, event := range backlog {
+		if filter.matches(event) {
+			if err := writeEvent(w, event); err != nil {
+				return
+			}
+		}
+	}
+	if err := controller.Flush(); err != nil {
+		return
+	}
+
+	heartbeat := time.NewTicker(s.heartbeat)
+	defer heartbeat.Stop()
+
+	for {
+		select {
+		case <-r.Context().Done():
+			return
+		case <-heartbeat.C:
+			if _, err := fmt.Fprint(w, ": heartbeat\n\n"); err != nil {
+				return
+			}
+		case event, ok := <-ch:
+			if !ok {
+				return
+			}
+			// Skip events already sent from the backlog
+			if event.ID <= lastSent || !filter.matches(event) {
+				continue
+			}
+			if err := writeEvent(w, event); err != nil {
+				return
+			}
+			lastSent = event.ID
+		}
+		if err := controller.Flush(); err != nil {
+			return
+		}
+	}
+}
+
+// writeEvent writes a single event in the Server-Sent Events format
+func writeEvent(w http.ResponseWriter, event Event) error {
+	data, err := json.Marshal(event)
+	if err != nil {
+		return err
+	}
+	_, err = fmt.Fprintf(w, "id: %d\nevent: %s\ndata: %s\n\n", event.ID, event.Type, data)
+	return err
+}
+
+// parseLastEventID returns the ID of the last event the client received, from the
+// Last-Event-ID header or the last_event_id query parameter, or nil for new clients
+func parseLastEventID(r *http.Request) (*int64, error) {
+	value := r.Header.Get("Last-Event-ID")
+	if value == "" {
+		value = r.URL.Query().Get("last_event_id")
+	}
+	if value == "" {
+		return nil, nil
+	}
+
+	id, err := strconv.ParseInt(value, 10, 64)
+	if err != nil || id < 0 {
+		return nil, fmt.Errorf("invalid last event id %q", value)
+	}
+	return &id, nil
+}
+
+// eventFilter selects events from the query string
+type eventFilter struct {
+	Types    map[string]bool
+	Projects map[int]bool
+	Tests    map[int]bool
+	Statuses map[string]bool
+	Stacks   map[string]bool
+}
+
+// parseEventFilter parses the type, project, test, status and stack query parameters
+func parseEventFilter(query url.Values) (eventFilter, error) {
+	var filter eventFilter
+	var err error
+
+	filter.Types = parseStringSet(query, "type")
+	for eventType := range filter.Types {
+		switch eventType {
+		case state.EventRunStarted, state.EventStatusChanged, state.EventRunFinished, state.EventRunStuck:
+		default:
+			return filter, fmt.Errorf("invalid type %q", eventType)
+		}
+	}
+	if filter.Projects, err = parseIntSet(query, "project"); err != nil {
+		return filter, err
+	}
+	if filter.Tests, err = parseIntSet(query, "test"); err != nil {
+		return filter, err
+	}
+	filter.Statuses = parseStringSet(query, "status")
+	filter.Stacks = parseStringSet(query, "stack")
+
+	return filter, nil
+}
+
+// matches returns true if the event passes the filter
+func (f eventFilter) matches(event Event) bool {
+	if f.Types != nil && !f.Types[event.Type] {
+		return false
+	}
+	if f.Projects != nil && !f.Projects[event.ProjectID] {
+		return false
+	}
+	if f.Tests != nil && !f.Tests[event.TestID] {
+		return false
+	}
+	if f.Statuses != nil && !f.Statuses[event.Status] {
+		return false
+	}
+	if f.Stacks != nil && !f.Stacks[event.Stack] {
+		return false
+	}
+	return true
+}
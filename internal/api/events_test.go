@@ -0,0 +1,153 @@
+package api
+
+import (
+	"bufio"
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap/zaptest"
+
+	"github.com/grafana-cloud-k6-prometheus-exporter/internal/state"
+)
+
+// sseEvent is an event read from the stream
+type sseEvent struct {
+	ID   string
+	Type string
+	Data string
+}
+
+// openStream connects to the event stream and returns a function reading the next event
+func openStream(t *testing.T, url string, lastEventID string) (func() sseEvent, context.CancelFunc) {
+	ctx, cancel := context.WithCancel(context.Background())
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	require.NoError(t, err)
+	if lastEventID != "" {
+		req.Header.Set("Last-Event-ID", lastEventID)
+	}
+
+	resp, err := http.DefaultClient.Do(req)
+	require.NoError(t, err)
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.Equal(t, "text/event-stream", resp.Header.Get("Content-Type"))
+
+	reader := bufio.NewReader(resp.Body)
+	next := func() sseEvent {
+		var event sseEvent
+		for {
+			line, err := reader.ReadString('\n')
+			require.NoError(t, err)
+			line = strings.TrimSuffix(line, "\n")
+
+			switch {
+			case line == "" && event.ID != "":
+				return event
+			case strings.HasPrefix(line, "id: "):
+				event.ID = strings.TrimPrefix(line, "id: ")
+			case strings.HasPrefix(line, "event: "):
+				event.Type = strings.TrimPrefix(line, "event: ")
+			case strings.HasPrefix(line, "data: "):
+				event.Data = strings.TrimPrefix(line, "data: ")
+			}
+		}
+	}
+
+	return next, func() {
+		cancel()
+		resp.Body.Close()
+	}
+}
+
+// waitForSubscribers waits until the stream has the given number of clients
+func waitForSubscribers(t *testing.T, stream *EventStream, count int) {
+	require.Eventually(t, func() bool {
+		stream.mu.Lock()
+		defer stream.mu.Unlock()
+		return len(stream.subscribers) == count
+	}, time.Second, 5*time.Millisecond)
+}
+
+func TestEventStream(t *testing.T) {
+	stream := NewEventStream(10, time.Hour, zaptest.NewLogger(t))
+	server := httptest.NewServer(stream)
+	defer server.Close()
+
+	next, closeStream := openStream(t, server.URL+"?project=100", "")
+	waitForSubscribers(t, stream, 1)
+
+	stream.Publish(state.Event{Type: state.EventRunStarted, RunID: 1, ProjectID: 100, Status: "created"})
+	stream.Publish(state.Event{Type: state.EventRunStarted, RunID: 2, ProjectID: 200, Status: "created"})
+	stream.Publish(state.Event{Type: state.EventStatusChanged, RunID: 1, ProjectID: 100, Status: "running", PreviousStatus: "created"})
+
+	event := next()
+	assert.Equal(t, "1", event.ID)
+	assert.Equal(t, state.EventRunStarted, event.Type)
+	assert.Contains(t, event.Data, `"run_id":1`)
+
+	// Event of the other project is filtered out
+	event = next()
+	assert.Equal(t, "3", event.ID)
+	assert.Equal(t, state.EventStatusChanged, event.Type)
+	assert.Contains(t, event.Data, `"previous_status":"created"`)
+	closeStream()
+	waitForSubscribers(t, stream, 0)
+
+	// Reconnecting clients resume after the last event they received
+	stream.Publish(state.Event{Type: state.EventRunFinished, RunID: 1, ProjectID: 100, Status: "completed", Result: "passed"})
+	next, closeStream = openStream(t, server.URL, "3")
+	defer closeStream()
+
+	event = next()
+	assert.Equal(t, "4", event.ID)
+	assert.Equal(t, state.EventRunFinished, event.Type)
+	assert.Contains(t, event.Data, `"result":"passed"`)
+}
+
+func TestEventStreamRingBuffer(t *testing.T) {
+	stream := NewEventStream(3, time.Hour, zaptest.NewLogger(t))
+	for runID := 1; runID <= 5; runID++ {
+		stream.Publish(state.Event{Type: state.EventRunStarted, RunID: runID})
+	}
+
+	lastID := int64(0)
+	ch, backlog, _ := stream.subscribe(&lastID)
+	defer stream.unsubscribe(ch)
+
+	require.Len(t, backlog, 3)
+	assert.Equal(t, int64(3), backlog[0].ID)
+	assert.Equal(t, int64(5), backlog[2].ID)
+
+	// IDs from before a restart replay the whole buffer
+	future := int64(100)
+	ch2, backlog, _ := stream.subscribe(&future)
+	defer stream.unsubscribe(ch2)
+	assert.Len(t, backlog, 3)
+}
+
+func TestEventStreamHeartbeat(t *testing.T) {
+	stream := NewEventStream(10, 10*time.Millisecond, zaptest.NewLogger(t))
+	server := httptest.NewServer(stream)
+	defer server.Close()
+
+	resp, err := http.Get(server.URL)
+	require.NoError(t, err)
+	defer resp.Body.Close()
+
+	line, err := bufio.NewReader(resp.Body).ReadString('\n')
+	require.NoError(t, err)
+	assert.Equal(t, ": heartbeat\n", line)
+}
+
+func TestEventStreamInvalidFilter(t *testing.T) {
+	stream := NewEventStream(10, time.Hour, zaptest.NewLogger(t))
+
+	rec := httptest.NewRecorder()
+	stream.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/api/v1/events?type=exploded", nil))
+	assert.Equal(t, http.StatusBadRequest, rec.Code)
+}
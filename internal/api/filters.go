@@ -0,0 +1,175 @@
+package api
+
+import (
+	"fmt"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Page size limits
+const (
+	defaultLimit = 50
+	maxLimit     = 500
+)
+
+// runFilter selects test runs from the query string
+type runFilter struct {
+	Projects map[int]bool
+	Tests    map[int]bool
+	Statuses map[string]bool
+	Stacks   map[string]bool
+	Since    *time.Time
+	Until    *time.Time
+	Active   *bool
+}
+
+// parseRunFilter parses the project, test, status, stack, since, until and active query parameters
+func parseRunFilter(query url.Values) (runFilter, error) {
+	var filter runFilter
+	var err error
+
+	if filter.Projects, err = parseIntSet(query, "project"); err != nil {
+		return filter, err
+	}
+	if filter.Tests, err = parseIntSet(query, "test"); err != nil {
+		return filter, err
+	}
+	filter.Statuses = parseStringSet(query, "status")
+	filter.Stacks = parseStringSet(query, "stack")
+	if filter.Since, err = parseTime(query, "since"); err != nil {
+		return filter, err
+	}
+	if filter.Until, err = parseTime(query, "until"); err != nil {
+		return filter, err
+	}
+
+	if value := query.Get("active"); value != "" {
+		active, err := strconv.ParseBool(value)
+		if err != nil {
+			return filter, fmt.Errorf("invalid active %q", value)
+		}
+		filter.Active = &active
+	}
+
+	return filter, nil
+}
+
+// matches returns true if the run passes the filter. The time range applies to the creation time.
+func (f runFilter) matches(run Run) bool {
+	if f.Projects != nil && !f.Projects[run.ProjectID] {
+		return false
+	}
+	if f.Tests != nil && !f.Tests[run.TestID] {
+		return false
+	}
+	if f.Statuses != nil && !f.Statuses[run.Status] {
+		return false
+	}
+	if f.Stacks != nil && !f.Stacks[run.Stack] {
+		return false
+	}
+	if f.Since != nil && run.Created.Before(*f.Since) {
+		return false
+	}
+	if f.Until != nil && run.Created.After(*f.Until) {
+		return false
+	}
+	if f.Active != nil && run.Active != *f.Active {
+		return false
+	}
+	return true
+}
+
+// parsePage parses the limit and offset query parameters
+func parsePage(query url.Values) (Page, error) {
+	page := Page{Limit: defaultLimit}
+
+	if value := query.Get("limit"); value != "" {
+		limit, err := strconv.Atoi(value)
+		if err != nil || limit < 1 || limit > maxLimit {
+			return page, fmt.Errorf("limit must be between 1 and %d", maxLimit)
+		}
+		page.Limit = limit
+	}
+
+	if value := query.Get("offset"); value != "" {
+		offset, err := strconv.Atoi(value)
+		if err != nil || offset < 0 {
+			return page, fmt.Errorf("offset must not be negative")
+		}
+		page.Offset = offset
+	}
+
+	return page, nil
+}
+
+// bounds sets the total and next offset of the page and returns the slice bounds of its items
+func (p *Page) bounds(total int) (int, int) {
+	p.Total = total
+
+	start := p.Offset
+	if start > total {
+		start = total
+	}
+	end := start + p.Limit
+	if end >= total {
+		end = total
+	} else {
+		p.NextOffset = &end
+	}
+	return start, end
+}
+
+// parseIntSet parses a comma-separated list of integers, repeated parameters are merged.
+// It returns nil if the parameter is not set.
+func parseIntSet(query url.Values, name string) (map[int]bool, error) {
+	values := parseStringSet(query, name)
+	if values == nil {
+		return nil, nil
+	}
+
+	set := make(map[int]bool, len(values))
+	for value := range values {
+		i, err := strconv.Atoi(value)
+		if err != nil {
+			return nil, fmt.Errorf("invalid %s %q", name, value)
+		}
+		set[i] = true
+	}
+	return set, nil
+}
+
+// parseStringSet parses a comma-separated list, repeated parameters are merged.
+// It returns nil if the parameter is not set.
+func parseStringSet(query url.Values, name string) map[string]bool {
+	var set map[string]bool
+	for _, param := range query[name] {
+		for _, value := range strings.Split(param, ",") {
+			value = strings.TrimSpace(value)
+			if value == "" {
+				continue
+			}
+			if set == nil {
+				set = make(map[string]bool)
+			}
+			set[value] = true
+		}
+	}
+	return set
+}
+
+// parseTime parses an RFC 3339 timestamp
+func parseTime(query url.Values, name string) (*time.Time, error) {
+	value := query.Get(name)
+	if value == "" {
+		return nil, nil
+	}
+
+	t, err := time.Parse(time.RFC3339, value)
+	if err != nil {
+		return nil, fmt.Errorf("invalid %s %q, expected RFC 3339", name, value)
+	}
+	return &t, nil
+}
@@ -0,0 +1,63 @@
+package api
+
+import (
+	"time"
+)
+
+// Run is the API representation of a test run
+type Run struct {
+	ID              int                  `json:"id"`
+	TestID          int                  `json:"test_id"`
+	TestName        string               `json:"test_name"`
+	ProjectID       int                  `json:"project_id"`
+	Stack           string               `json:"stack,omitempty"`
+	Status          string               `json:"status"`
+	Result          string               `json:"result,omitempty"`
+	Active          bool                 `json:"active"`
+	Created         time.Time            `json:"created"`
+	Ended           *time.Time           `json:"ended,omitempty"`
+	DurationSeconds float64              `json:"duration_seconds"`
+	StartedBy       string               `json:"started_by,omitempty"`
+	VUH             float64              `json:"vuh"`
+	StatusHistory   map[string]time.Time `json:"status_history,omitempty"`
+}
+
+// Test is the API representation of a test
+type Test struct {
+	ID                  int        `json:"id"`
+	Name                string     `json:"name"`
+	ProjectID           int        `json:"project_id"`
+	Stack               string     `json:"stack,omitempty"`
+	Created             time.Time  `json:"created"`
+	Updated             time.Time  `json:"updated"`
+	LastRunStart        *time.Time `json:"last_run_start,omitempty"`
+	LastRunEnd          *time.Time `json:"last_run_end,omitempty"`
+	LastResult          string     `json:"last_result,omitempty"`
+	ConsecutiveFailures int        `json:"consecutive_failures"`
+	ActiveRuns          int        `json:"active_runs"`
+}
+
+// RunList is a page of test runs
+type RunList struct {
+	Runs []Run `json:"runs"`
+	Page
+}
+
+// TestList is a page of tests
+type TestList struct {
+	Tests []Test `json:"tests"`
+	Page
+}
+
+// Page describes the position of a page within the full result
+type Page struct {
+	Total      int  `json:"total"`
+	Limit      int  `json:"limit"`
+	Offset     int  `json:"offset"`
+	NextOffset *int `json:"next_offset,omitempty"`
+}
+
+// errorResponse is returned for failed requests
+type errorResponse struct {
+	Error string `json:"error"`
+}
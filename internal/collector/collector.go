@@ -2,6 +2,7 @@ package collector
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"strconv"
 	"sync"
@@ -22,11 +23,36 @@ type Collector struct {
 	config       *config.Config
 	logger       *zap.Logger
 	metrics      *OperationalMetrics
+	now          func() time.Time // Clock of the fetch window, run durations and stuck detection
 
 	// Cache for test data
 	testCache      map[int]*k6client.Test
 	testCacheMutex sync.RWMutex
 	lastTestFetch  time.Time
+	missingTests   map[int]bool // Unknown tests not found by an on-demand refresh, retried after the next full refresh
+
+	// Last run statistics per test, keyed by test ID
+	testStats      map[int]*testStats
+	testStatsMutex sync.RWMutex
+	seededTests    map[int]bool // Tests whose latest run was looked up, guarded by testStatsMutex
+
+	// Cache for schedules, keyed by schedule ID
+	schedules         map[int]*scheduleState
+	schedulesMutex    sync.RWMutex
+	lastScheduleFetch time.Time
+
+	// Load zone usage accumulated from finished runs
+	loadZones      map[loadZoneKey]*loadZoneTotals
+	loadZonesMutex sync.RWMutex
+
+	// Result of the last successful fetch, refreshes are serialized
+	snapshot      *Snapshot
+	snapshotMutex sync.RWMutex
+	refreshMutex  sync.Mutex
+
+	// k6 API health as seen by the fetches
+	health      Health
+	healthMutex sync.RWMutex
 }
 
 // NewCollector creates a new k6 metrics collector
@@ -38,6 +64,12 @@ func NewCollector(client k6client.ClientInterface, stateManager *state.Manager,
 		logger:       logger,
 		metrics:      NewOperationalMetrics(),
 		testCache:    make(map[int]*k6client.Test),
+		missingTests: make(map[int]bool),
+		testStats:    make(map[int]*testStats),
+		seededTests:  make(map[int]bool),
+		schedules:    make(map[int]*scheduleState),
+		loadZones:    make(map[loadZoneKey]*loadZoneTotals),
+		now:          time.Now,
 	}
 }
 
@@ -50,9 +82,27 @@ func NewCollectorWithRegistry(client k6client.ClientInterface, stateManager *sta
 		logger:       logger,
 		metrics:      NewOperationalMetricsWithRegistry(reg),
 		testCache:    make(map[int]*k6client.Test),
+		missingTests: make(map[int]bool),
+		testStats:    make(map[int]*testStats),
+		seededTests:  make(map[int]bool),
+		schedules:    make(map[int]*scheduleState),
+		loadZones:    make(map[loadZoneKey]*loadZoneTotals),
+		now:          time.Now,
 	}
 }
 
+// WithClock replaces the clock the fetch window, run durations and stuck runs are
+// computed with, replays use the time of the recording. Returns the collector.
+func (c *Collector) WithClock(now func() time.Time) *Collector {
+	c.now = now
+	return c
+}
+
+// Stack returns the name of the stack the collector fetches from, empty with a single stack
+func (c *Collector) Stack() string {
+	return c.config.StackName
+}
+
 // Describe implements prometheus.Collector
 func (c *Collector) Describe(ch chan<- *prometheus.Desc) {
 	// Send metric descriptors for active test runs only
@@ -60,6 +110,26 @@ func (c *Collector) Describe(ch chan<- *prometheus.Desc) {
 	ch <- testRunDurationSecondsDesc
 	ch <- testRunVUHConsumedDesc
 	ch <- testRunInfoDesc
+	ch <- testInfoDesc
+	ch <- testCreatedTimestampDesc
+	ch <- testUpdatedTimestampDesc
+	ch <- testLastRunStartTimestampDesc
+	ch <- testLastRunEndTimestampDesc
+	ch <- testLastRunResultDesc
+	ch <- testConsecutiveFailuresDesc
+	ch <- scheduleInfoDesc
+	ch <- scheduleNextRunTimestampDesc
+	ch <- scheduleMissedRunsTotalDesc
+	ch <- testRunLoadZoneVUsDesc
+	ch <- testRunLoadZoneVUHDesc
+	ch <- loadZoneRunsTotalDesc
+	ch <- loadZoneVUsTotalDesc
+	ch <- loadZoneVUHTotalDesc
+	ch <- userTestRunsStartedDesc
+	ch <- userVUHConsumedDesc
+	ch <- testRunStatusDurationSecondsDesc
+	ch <- testRunStuckDesc
+	ch <- testRunsStuckDesc
 	// Note: operational metrics (like scrape duration, test runs tracked) are handled separately
 }
 
@@ -68,7 +138,7 @@ func (c *Collector) Collect(ch chan<- prometheus.Metric) {
 	start := time.Now()
 
 	// Update operational metrics
-	c.metrics.TestRunsTracked.Set(float64(c.stateManager.GetStateCount()))
+	c.metrics.TestRunsTracked.Set(float64(c.stateManager.GetStateCountByStack(c.config.StackName)))
 
 	// Collect metrics
 	if err := c.collectMetrics(ch); err != nil {
@@ -81,10 +151,21 @@ func (c *Collector) Collect(ch chan<- prometheus.Metric) {
 	c.metrics.ScrapeDuration.Observe(duration)
 }
 
-func (c *Collector) collectMetrics(ch chan<- prometheus.Metric) error {
-	ctx, cancel := context.WithTimeout(context.Background(), c.config.APITimeout)
-	defer cancel()
+// Snapshot holds the test runs returned by the last successful fetch
+type Snapshot struct {
+	Runs      []k6client.TestRun
+	FetchedAt time.Time
+	Stale     bool // Served while the k6 API circuit breaker is open
+}
 
+// Refresh fetches test runs from the k6 API and updates the tracked state and snapshot
+func (c *Collector) Refresh(ctx context.Context) error {
+	c.refreshMutex.Lock()
+	defer c.refreshMutex.Unlock()
+	return c.refresh(ctx)
+}
+
+func (c *Collector) refresh(ctx context.Context) error {
 	// Update test cache if needed
 	if time.Since(c.lastTestFetch) > c.config.TestCacheTTL {
 		if err := c.updateTestCache(ctx); err != nil {
@@ -93,52 +174,153 @@ func (c *Collector) collectMetrics(ch chan<- prometheus.Metric) error {
 		}
 	}
 
+	// Update schedules if needed
+	if time.Since(c.lastScheduleFetch) > c.config.TestCacheTTL {
+		if err := c.updateSchedules(ctx); err != nil {
+			c.logger.Error("failed to update schedules", zap.Error(err))
+			c.metrics.ScrapeErrorsTotal.WithLabelValues("schedules").Inc()
+		}
+	}
+
 	// Fetch test runs from the last 24 hours
-	since := time.Now().Add(-24 * time.Hour)
+	since := c.now().Add(-24 * time.Hour)
 	testRuns, err := c.client.GetAllTestRuns(ctx, c.config.Projects, &since)
 	if err != nil {
+		c.recordFetchError(err, time.Now())
 		return fmt.Errorf("fetch test runs: %w", err)
 	}
 
 	// Update last scrape timestamp
 	c.metrics.LastScrapeTimestamp.WithLabelValues("test_runs").SetToCurrentTime()
 
+	// Pick up tests created since the last test cache refresh
+	if err := c.refreshUnknownTests(ctx, testRuns); err != nil {
+		c.logger.Error("failed to refresh unknown tests", zap.Error(err))
+		c.metrics.ScrapeErrorsTotal.WithLabelValues("test_cache").Inc()
+	}
+
+	// Update per-test last run statistics, including finished runs
+	c.updateTestStats(testRuns)
+
+	// Look up the last run of tests that did not run within the fetch window
+	if err := c.seedTestStats(ctx); err != nil {
+		c.logger.Error("failed to look up latest test runs", zap.Error(err))
+		c.metrics.ScrapeErrorsTotal.WithLabelValues("test_stats").Inc()
+	}
+
+	// Match expected schedule fire times against the observed runs
+	c.detectMissedRuns(testRuns)
+
+	for i := range testRuns {
+		run := &testRuns[i]
+		// Finished runs are accounted for once and no longer tracked
+		if k6client.IsTerminalStatus(run.Status) {
+			c.recordFinishedRun(run)
+			continue
+		}
+
+		c.stateManager.UpdateTestRun(c.newRunState(run))
+	}
+
+	// Clean up completed test runs from state manager
+	c.stateManager.CleanupCompletedRuns()
+
+	c.detectStuckRuns(c.now())
+
+	now := time.Now()
+	c.snapshotMutex.Lock()
+	c.snapshot = &Snapshot{Runs: testRuns, FetchedAt: now}
+	c.snapshotMutex.Unlock()
+	c.recordFetchSuccess(now)
+
+	return nil
+}
+
+// Snapshot returns the result of the last successful fetch, or nil if there was none
+func (c *Collector) Snapshot() *Snapshot {
+	c.snapshotMutex.RLock()
+	defer c.snapshotMutex.RUnlock()
+	return c.snapshot
+}
+
+// currentSnapshot returns the latest snapshot, refreshing it first if it is too old.
+// While the k6 API circuit breaker is open the last snapshot is returned flagged as stale.
+func (c *Collector) currentSnapshot() (*Snapshot, error) {
+	c.refreshMutex.Lock()
+	defer c.refreshMutex.Unlock()
+
+	// The background poll keeps the snapshot fresh, only fetch on scrape if it fell behind
+	if snapshot := c.Snapshot(); snapshot != nil && time.Since(snapshot.FetchedAt) < 2*c.config.ScrapeInterval {
+		return snapshot, nil
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), c.config.APITimeout)
+	defer cancel()
+
+	if err := c.refresh(ctx); err != nil {
+		snapshot := c.Snapshot()
+		if snapshot == nil || !errors.Is(err, k6client.ErrCircuitOpen) {
+			return nil, err
+		}
+		c.logger.Debug("k6 API circuit breaker is open, serving stale snapshot",
+			zap.Time("fetched_at", snapshot.FetchedAt),
+		)
+		stale := *snapshot
+		stale.Stale = true
+		return &stale, nil
+	}
+	return c.Snapshot(), nil
+}
+
+// TestName returns the name of the test run's test from cache or status details
+func (c *Collector) TestName(run *k6client.TestRun) string {
+	if testName := c.getTestName(run.TestID); testName != "" {
+		return testName
+	}
+	if name, ok := run.StatusDetails["test_name"].(string); ok {
+		return name
+	}
+	return fmt.Sprintf("test_%d", run.TestID)
+}
+
+func (c *Collector) collectMetrics(ch chan<- prometheus.Metric) error {
+	snapshot, err := c.currentSnapshot()
+	if err != nil {
+		return err
+	}
+	if snapshot.Stale {
+		c.metrics.SnapshotStale.Set(1)
+	} else {
+		c.metrics.SnapshotStale.Set(0)
+	}
+
+	c.collectTestMetrics(ch)
+
+	// Send per-user usage within the fetch window
+	c.collectUserMetrics(ch, snapshot.Runs)
+
+	c.collectScheduleMetrics(ch)
+
 	// Process test runs - only track active runs
 	statusCounts := make(map[string]map[string]int)    // status -> labels -> count
 	activeRuns := make(map[string][]*k6client.TestRun) // status -> runs
+	stuckCounts := make(map[string]int)                // reason -> count
+	now := c.now()
 
-	for _, run := range testRuns {
-		// Skip completed and aborted test runs
+	for i := range snapshot.Runs {
+		run := &snapshot.Runs[i]
 		if k6client.IsTerminalStatus(run.Status) {
 			continue
 		}
-		// Get test name from cache or status details
-		testName := c.getTestName(run.TestID)
-		if testName == "" {
-			if name, ok := run.StatusDetails["test_name"].(string); ok {
-				testName = name
-			} else {
-				testName = fmt.Sprintf("test_%d", run.TestID)
-			}
-		}
+		testName := c.TestName(run)
 
-		// Create state for this test run
-		runState := &state.TestRunState{
-			TestRunID:     run.ID,
-			TestID:        run.TestID,
-			ProjectID:     run.ProjectID,
-			TestName:      testName,
-			CurrentStatus: run.Status,
-			Created:       run.Created,
-			Ended:         run.Ended,
-			Result:        run.Result,
-			StartedBy:     run.StartedBy,
-			VUH:           run.GetVUH(),
+		// Flag runs that spent too long in their current status
+		if tracked := c.stateManager.GetTestRunState(c.config.StackName, run.ID); tracked != nil {
+			if reason := c.checkStuckRun(ch, tracked, now); reason != "" {
+				stuckCounts[reason]++
+			}
 		}
 
-		// Update state manager
-		c.stateManager.UpdateTestRun(runState)
-
 		// Create label key for deduplication
 		labelKey := fmt.Sprintf("%s|%d|%d", testName, run.TestID, run.ProjectID)
 
@@ -152,7 +334,7 @@ func (c *Collector) collectMetrics(ch chan<- prometheus.Metric) error {
 		if activeRuns[run.Status] == nil {
 			activeRuns[run.Status] = make([]*k6client.TestRun, 0)
 		}
-		activeRuns[run.Status] = append(activeRuns[run.Status], &run)
+		activeRuns[run.Status] = append(activeRuns[run.Status], run)
 
 		// Send info metric
 		ch <- prometheus.MustNewConstMetric(
@@ -169,7 +351,7 @@ func (c *Collector) collectMetrics(ch chan<- prometheus.Metric) error {
 		ch <- prometheus.MustNewConstMetric(
 			testRunDurationSecondsDesc,
 			prometheus.GaugeValue,
-			run.GetDuration(),
+			run.GetDurationAt(now),
 			testName,
 			strconv.Itoa(run.TestID),
 			strconv.Itoa(run.ProjectID),
@@ -188,8 +370,17 @@ func (c *Collector) collectMetrics(ch chan<- prometheus.Metric) error {
 				strconv.Itoa(run.ID),
 			)
 		}
+
+		// Send live load zone metrics
+		c.collectLoadZoneMetrics(ch, run, testName)
 	}
 
+	// Send stuck run counts
+	c.collectStuckCounts(ch, stuckCounts)
+
+	// Send load zone usage of finished runs
+	c.collectLoadZoneTotals(ch)
+
 	// Send status gauges - only for active statuses
 	activeStatuses := []string{
 		k6client.StatusCreated,
@@ -222,31 +413,40 @@ func (c *Collector) collectMetrics(ch chan<- prometheus.Metric) error {
 		}
 	}
 
-	// Clean up completed test runs from state manager
-	c.stateManager.CleanupCompletedRuns()
-
 	return nil
 }
 
-// updateTestCache updates the cached test information
-func (c *Collector) updateTestCache(ctx context.Context) error {
-	tests, err := c.client.ListTests(ctx, nil)
-	if err != nil {
-		return fmt.Errorf("list tests: %w", err)
+// recordFinishedRun accounts for a finished test run once and stops tracking it
+func (c *Collector) recordFinishedRun(run *k6client.TestRun) {
+	first := run.Ended != nil && c.stateManager.MarkRunFinished(c.config.StackName, run.ID, *run.Ended)
+	if first {
+		c.recordFinishedLoadZones(run)
 	}
 
-	c.testCacheMutex.Lock()
-	defer c.testCacheMutex.Unlock()
-
-	// Clear and rebuild cache
-	c.testCache = make(map[int]*k6client.Test)
-	for i := range tests {
-		c.testCache[tests[i].ID] = &tests[i]
+	// Let the state manager drop the run and report that it finished
+	if first || c.stateManager.GetTestRunState(c.config.StackName, run.ID) != nil {
+		c.stateManager.UpdateTestRun(c.newRunState(run))
 	}
-	c.lastTestFetch = time.Now()
+}
 
-	c.logger.Info("updated test cache", zap.Int("test_count", len(tests)))
-	return nil
+// newRunState creates the tracked state of a test run
+func (c *Collector) newRunState(run *k6client.TestRun) *state.TestRunState {
+	return &state.TestRunState{
+		TestRunID:     run.ID,
+		TestID:        run.TestID,
+		ProjectID:     run.ProjectID,
+		TestName:      c.TestName(run),
+		CurrentStatus: run.Status,
+		Created:       run.Created,
+		Ended:         run.Ended,
+		Result:        run.Result,
+		StartedBy:     run.StartedBy,
+		VUH:           run.GetVUH(),
+		StatusHistory: run.GetStatusTimes(),
+		Stack:         c.config.StackName,
+
+		FailedThresholds: run.GetFailedThresholds(),
+	}
 }
 
 // getTestName returns the test name from cache
@@ -291,8 +491,13 @@ func indexOf(s, substr string, start int) int {
 	return -1
 }
 
-// StartBackgroundTasks starts background tasks like state cleanup
+// StartBackgroundTasks starts background tasks like polling and state cleanup
 func (c *Collector) StartBackgroundTasks(ctx context.Context) {
+	// Poll the k6 API so readiness and the snapshot do not depend on scrapes
+	if c.config.ScrapeInterval > 0 {
+		go c.poll(ctx)
+	}
+
 	// State cleanup task
 	go func() {
 		ticker := time.NewTicker(c.config.StateCleanupInterval)
@@ -311,3 +516,24 @@ func (c *Collector) StartBackgroundTasks(ctx context.Context) {
 		}
 	}()
 }
+
+// poll refreshes the snapshot every scrape interval until the context is cancelled
+func (c *Collector) poll(ctx context.Context) {
+	ticker := time.NewTicker(c.config.ScrapeInterval)
+	defer ticker.Stop()
+
+	for {
+		refreshCtx, cancel := context.WithTimeout(ctx, c.config.APITimeout)
+		if err := c.Refresh(refreshCtx); err != nil {
+			c.logger.Error("failed to refresh test runs", zap.Error(err))
+			c.metrics.ScrapeErrorsTotal.WithLabelValues("poll").Inc()
+		}
+		cancel()
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
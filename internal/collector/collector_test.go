@@ -20,9 +20,13 @@ import (
 
 // mockK6Client implements a mock k6 API client for testing
 type mockK6Client struct {
-	tests    []k6client.Test
-	testRuns []k6client.TestRun
-	err      error
+	tests     []k6client.Test
+	testRuns  []k6client.TestRun
+	schedules []k6client.Schedule
+	err       error
+
+	listTestsCalls []*int // Project filter of every ListTests call
+	latestRunCalls []int  // Test of every GetLatestTestRun call
 }
 
 func (m *mockK6Client) ListProjects(ctx context.Context) ([]k6client.Project, error) {
@@ -33,10 +37,20 @@ func (m *mockK6Client) ListProjects(ctx context.Context) ([]k6client.Project, er
 }
 
 func (m *mockK6Client) ListTests(ctx context.Context, projectID *int) ([]k6client.Test, error) {
+	m.listTestsCalls = append(m.listTestsCalls, projectID)
 	if m.err != nil {
 		return nil, m.err
 	}
-	return m.tests, nil
+	if projectID == nil {
+		return m.tests, nil
+	}
+	var tests []k6client.Test
+	for _, test := range m.tests {
+		if test.ProjectID == *projectID {
+			tests = append(tests, test)
+		}
+	}
+	return tests, nil
 }
 
 func (m *mockK6Client) ListTestRuns(ctx context.Context, testID int, since *time.Time) ([]k6client.TestRun, error) {
@@ -52,6 +66,24 @@ func (m *mockK6Client) ListTestRuns(ctx context.Context, testID int, since *time
 	return runs, nil
 }
 
+func (m *mockK6Client) GetLatestTestRun(ctx context.Context, testID int) (*k6client.TestRun, error) {
+	m.latestRunCalls = append(m.latestRunCalls, testID)
+	if m.err != nil {
+		return nil, m.err
+	}
+	var latest *k6client.TestRun
+	for i, run := range m.testRuns {
+		if run.TestID == testID && (latest == nil || run.Created.After(latest.Created)) {
+			latest = &m.testRuns[i]
+		}
+	}
+	if latest == nil {
+		return nil, nil
+	}
+	run := *latest
+	return &run, nil
+}
+
 func (m *mockK6Client) GetTestRun(ctx context.Context, testID, runID int) (*k6client.TestRun, error) {
 	if m.err != nil {
 		return nil, m.err
@@ -86,6 +118,17 @@ func (m *mockK6Client) GetAllTestRuns(ctx context.Context, projectIDs []string,
 	return m.testRuns, nil
 }
 
+func (m *mockK6Client) ListSchedules(ctx context.Context) ([]k6client.Schedule, error) {
+	if m.err != nil {
+		return nil, m.err
+	}
+	return m.schedules, nil
+}
+
+func (m *mockK6Client) AbortTestRun(ctx context.Context, runID int) error {
+	return m.err
+}
+
 func TestCollectorDescribe(t *testing.T) {
 	logger := zaptest.NewLogger(t)
 	cfg := &config.Config{
@@ -112,6 +155,11 @@ func TestCollectorDescribe(t *testing.T) {
 		"k6_test_run_duration_seconds",
 		"k6_test_run_vuh_consumed",
 		"k6_test_run_info",
+		"k6_test_info",
+		"k6_test_last_run_start_timestamp_seconds",
+		"k6_test_last_run_end_timestamp_seconds",
+		"k6_test_last_run_result",
+		"k6_test_consecutive_failures",
 	}
 
 	descriptions := make([]string, 0)
@@ -324,6 +372,119 @@ func TestUpdateTestCache(t *testing.T) {
 	assert.Equal(t, "Test 3", collector.getTestName(3))
 }
 
+func TestUpdateTestCacheIncremental(t *testing.T) {
+	logger := zaptest.NewLogger(t)
+	cfg := &config.Config{
+		TestCacheTTL: 60 * time.Second,
+		Projects:     []string{"100", "200"},
+	}
+
+	created := time.Now().Add(-time.Hour)
+	resultPassed := k6client.ResultPassed
+	mockClient := &mockK6Client{
+		tests: []k6client.Test{
+			{ID: 1, Name: "Test 1", ProjectID: 100, Updated: created},
+			{ID: 2, Name: "Test 2", ProjectID: 100, Updated: created},
+			{ID: 3, Name: "Test 3", ProjectID: 200, Updated: created},
+			{ID: 4, Name: "Unmonitored", ProjectID: 300, Updated: created},
+		},
+		testRuns: []k6client.TestRun{
+			{ID: 20, TestID: 2, ProjectID: 100, Status: k6client.StatusCompleted, Created: created, Ended: timePtr(created.Add(time.Minute)), Result: &resultPassed},
+		},
+	}
+
+	registry := prometheus.NewRegistry()
+	collector := NewCollectorWithRegistry(mockClient, state.NewManager(logger), cfg, logger, registry)
+	registry.MustRegister(collector)
+	ctx := context.Background()
+
+	// Only the configured projects are listed
+	require.NoError(t, collector.updateTestCache(ctx))
+	require.Len(t, mockClient.listTestsCalls, 2)
+	assert.Equal(t, 100, *mockClient.listTestsCalls[0])
+	assert.Equal(t, 200, *mockClient.listTestsCalls[1])
+	assert.Equal(t, "", collector.getTestName(4))
+	collector.updateTestStats(mockClient.testRuns)
+
+	// Tests are only replaced when Updated changed
+	cached := collector.testCache[1]
+	mockClient.tests = []k6client.Test{
+		{ID: 1, Name: "Test 1", ProjectID: 100, Updated: created},
+		{ID: 3, Name: "Renamed", ProjectID: 200, Updated: created.Add(time.Minute)},
+		{ID: 5, Name: "Test 5", ProjectID: 200, Updated: created},
+	}
+	require.NoError(t, collector.updateTestCache(ctx))
+	assert.Same(t, cached, collector.testCache[1])
+	assert.Equal(t, "Renamed", collector.getTestName(3))
+	assert.Equal(t, "Test 5", collector.getTestName(5))
+
+	// Deleted tests are removed with their series
+	assert.Equal(t, "", collector.getTestName(2))
+	mockClient.testRuns = nil
+	metricMap := gatherMetrics(t, registry)
+	for _, metric := range metricMap["k6_test_last_run_start_timestamp_seconds"].GetMetric() {
+		assert.NotEqual(t, "2", labelValue(metric, "test_id"))
+	}
+	assert.Len(t, metricMap["k6_test_info"].Metric, 3)
+}
+
+func TestUpdateTestCacheKeepsFailedProjects(t *testing.T) {
+	logger := zaptest.NewLogger(t)
+	cfg := &config.Config{
+		TestCacheTTL: 60 * time.Second,
+	}
+
+	mockClient := &mockK6Client{
+		tests: []k6client.Test{
+			{ID: 1, Name: "Test 1", ProjectID: 100},
+		},
+	}
+	collector := NewCollectorWithRegistry(mockClient, state.NewManager(logger), cfg, logger, prometheus.NewRegistry())
+	ctx := context.Background()
+	require.NoError(t, collector.updateTestCache(ctx))
+
+	// A failed listing keeps the cached tests
+	mockClient.err = &k6client.APIError{StatusCode: 500, Status: "500 Internal Server Error"}
+	assert.Error(t, collector.refreshTests(ctx, []int{100}))
+	assert.Equal(t, "Test 1", collector.getTestName(1))
+}
+
+func TestRefreshUnknownTests(t *testing.T) {
+	logger := zaptest.NewLogger(t)
+	cfg := &config.Config{
+		TestCacheTTL:         time.Hour,
+		StateCleanupInterval: 5 * time.Minute,
+		APITimeout:           30 * time.Second,
+	}
+
+	now := time.Now()
+	mockClient := &mockK6Client{
+		tests: []k6client.Test{
+			{ID: 1, Name: "Test 1", ProjectID: 100},
+		},
+	}
+	collector := NewCollectorWithRegistry(mockClient, state.NewManager(logger), cfg, logger, prometheus.NewRegistry())
+	ctx := context.Background()
+	require.NoError(t, collector.Refresh(ctx))
+	require.Len(t, mockClient.listTestsCalls, 1)
+
+	// A run of a new test refreshes the tests of its project before the TTL
+	mockClient.tests = append(mockClient.tests, k6client.Test{ID: 2, Name: "New Test", ProjectID: 200})
+	mockClient.testRuns = []k6client.TestRun{
+		{ID: 10, TestID: 2, ProjectID: 200, Status: k6client.StatusRunning, Created: now},
+		{ID: 11, TestID: 3, ProjectID: 200, Status: k6client.StatusRunning, Created: now},
+	}
+	require.NoError(t, collector.Refresh(ctx))
+	require.Len(t, mockClient.listTestsCalls, 2)
+	assert.Equal(t, 200, *mockClient.listTestsCalls[1])
+	assert.Equal(t, "New Test", collector.getTestName(2))
+
+	// Tests the API did not return are not looked up on every refresh
+	require.NoError(t, collector.Refresh(ctx))
+	assert.Len(t, mockClient.listTestsCalls, 2)
+	assert.Equal(t, "Test 1", collector.getTestName(1), "other projects keep their tests")
+}
+
 func TestSplitLabelKey(t *testing.T) {
 	tests := []struct {
 		key      string
@@ -390,7 +551,7 @@ func TestBackgroundTasks(t *testing.T) {
 
 	// Now manually set the LastUpdated to old time to simulate an old state
 	// This is needed because UpdateTestRun sets LastUpdated to now
-	storedState := stateManager.GetTestRunState(1)
+	storedState := stateManager.GetTestRunState("", 1)
 	require.NotNil(t, storedState)
 	
 	// Directly call cleanup with our time threshold
@@ -462,9 +623,520 @@ func TestCollectorIntegration(t *testing.T) {
 
 	// Verify state manager has the active test run
 	assert.Equal(t, 1, stateManager.GetStateCount())
-	runState := stateManager.GetTestRunState(1)
+	runState := stateManager.GetTestRunState("", 1)
 	require.NotNil(t, runState)
 	assert.Equal(t, k6client.StatusRunning, runState.CurrentStatus)
 	// No result for running tests
 	assert.Nil(t, runState.Result)
-}
\ No newline at end of file
+}
+
+func TestCollectorTestMetrics(t *testing.T) {
+	logger := zaptest.NewLogger(t)
+	cfg := &config.Config{
+		TestCacheTTL:         60 * time.Second,
+		StateCleanupInterval: 5 * time.Minute,
+		APITimeout:           30 * time.Second,
+	}
+
+	now := time.Now().Truncate(time.Second)
+	baselineRunID := 20
+	resultPassed := k6client.ResultPassed
+	resultFailed := k6client.ResultFailed
+
+	mockClient := &mockK6Client{
+		tests: []k6client.Test{
+			{ID: 1, Name: "Nightly", ProjectID: 100, BaselineTestRunID: &baselineRunID, Created: now.Add(-48 * time.Hour), Updated: now.Add(-24 * time.Hour)},
+			{ID: 2, Name: "Never Run", ProjectID: 100},
+		},
+		testRuns: []k6client.TestRun{
+			{ID: 20, TestID: 1, ProjectID: 100, Status: k6client.StatusCompleted, Created: now.Add(-6 * time.Hour), Ended: timePtr(now.Add(-5 * time.Hour)), Result: &resultPassed},
+			{ID: 21, TestID: 1, ProjectID: 100, Status: k6client.StatusCompleted, Created: now.Add(-4 * time.Hour), Ended: timePtr(now.Add(-3 * time.Hour)), Result: &resultFailed},
+			{ID: 22, TestID: 1, ProjectID: 100, Status: k6client.StatusCompleted, Created: now.Add(-2 * time.Hour), Ended: timePtr(now.Add(-1 * time.Hour)), Result: &resultFailed},
+		},
+	}
+
+	registry := prometheus.NewRegistry()
+	collector := NewCollectorWithRegistry(mockClient, state.NewManager(logger), cfg, logger, registry)
+	registry.MustRegister(collector)
+
+	metricMap := gatherMetrics(t, registry)
+
+	infoMetric, exists := metricMap["k6_test_info"]
+	require.True(t, exists, "k6_test_info metric should exist")
+	assert.Len(t, infoMetric.Metric, 2, "Should have info for every cached test")
+
+	startMetric := findMetric(t, metricMap, "k6_test_last_run_start_timestamp_seconds", "test_id", "1")
+	assert.Equal(t, float64(now.Add(-2*time.Hour).Unix()), startMetric.GetGauge().GetValue())
+
+	endMetric := findMetric(t, metricMap, "k6_test_last_run_end_timestamp_seconds", "test_id", "1")
+	assert.Equal(t, float64(now.Add(-1*time.Hour).Unix()), endMetric.GetGauge().GetValue())
+
+	resultMetric := findMetric(t, metricMap, "k6_test_last_run_result", "test_id", "1")
+	assert.Equal(t, "failed", labelValue(resultMetric, "result"))
+
+	failuresMetric := findMetric(t, metricMap, "k6_test_consecutive_failures", "test_id", "1")
+	assert.Equal(t, float64(2), failuresMetric.GetGauge().GetValue())
+
+	// A second scrape over the same window must not double count failures
+	metricMap = gatherMetrics(t, registry)
+	failuresMetric = findMetric(t, metricMap, "k6_test_consecutive_failures", "test_id", "1")
+	assert.Equal(t, float64(2), failuresMetric.GetGauge().GetValue())
+
+	// Last run stats are kept after the runs leave the fetch window
+	mockClient.testRuns = nil
+	metricMap = gatherMetrics(t, registry)
+	startMetric = findMetric(t, metricMap, "k6_test_last_run_start_timestamp_seconds", "test_id", "1")
+	assert.Equal(t, float64(now.Add(-2*time.Hour).Unix()), startMetric.GetGauge().GetValue())
+}
+
+// gatherMetrics gathers the registry and indexes the metric families by name
+func gatherMetrics(t *testing.T, registry *prometheus.Registry) map[string]*dto.MetricFamily {
+	t.Helper()
+
+	metricFamilies, err := registry.Gather()
+	require.NoError(t, err)
+
+	metricMap := make(map[string]*dto.MetricFamily)
+	for _, mf := range metricFamilies {
+		metricMap[*mf.Name] = mf
+	}
+	return metricMap
+}
+
+// findMetric returns the metric of the named family that carries the given label value
+func findMetric(t *testing.T, metricMap map[string]*dto.MetricFamily, name, label, value string) *dto.Metric {
+	t.Helper()
+
+	mf, exists := metricMap[name]
+	require.True(t, exists, "%s metric should exist", name)
+	for _, m := range mf.Metric {
+		if labelValue(m, label) == value {
+			return m
+		}
+	}
+	require.Failf(t, "metric not found", "%s{%s=%q}", name, label, value)
+	return nil
+}
+
+// labelValue returns the value of the named label of a metric
+func labelValue(m *dto.Metric, name string) string {
+	for _, lp := range m.Label {
+		if lp.GetName() == name {
+			return lp.GetValue()
+		}
+	}
+	return ""
+}
+
+func TestCollectorScheduleMissedRuns(t *testing.T) {
+	logger := zaptest.NewLogger(t)
+	cfg := &config.Config{
+		TestCacheTTL:           60 * time.Second,
+		StateCleanupInterval:   5 * time.Minute,
+		APITimeout:             30 * time.Second,
+		ScheduleMissedRunGrace: 10 * time.Minute,
+	}
+
+	now := time.Now()
+	missedFire := now.Add(-1 * time.Hour)
+	keptFire := now.Add(-2 * time.Hour)
+	futureFire := now.Add(1 * time.Hour)
+	resultPassed := k6client.ResultPassed
+
+	mockClient := &mockK6Client{
+		tests: []k6client.Test{
+			{ID: 1, Name: "Nightly", ProjectID: 100},
+			{ID: 2, Name: "Hourly", ProjectID: 100},
+			{ID: 3, Name: "Later", ProjectID: 100},
+		},
+		testRuns: []k6client.TestRun{
+			{ID: 20, TestID: 2, ProjectID: 100, Status: k6client.StatusCompleted, Created: keptFire.Add(time.Minute), Ended: timePtr(keptFire.Add(10 * time.Minute)), Result: &resultPassed},
+		},
+		schedules: []k6client.Schedule{
+			{ID: 1, LoadTestID: 1, NextRun: &missedFire, RecurrenceRule: &k6client.RecurrenceRule{Frequency: "DAILY"}},
+			{ID: 2, LoadTestID: 2, NextRun: &keptFire, RecurrenceRule: &k6client.RecurrenceRule{Frequency: "HOURLY"}},
+			{ID: 3, LoadTestID: 3, NextRun: &futureFire},
+		},
+	}
+
+	registry := prometheus.NewRegistry()
+	collector := NewCollectorWithRegistry(mockClient, state.NewManager(logger), cfg, logger, registry)
+	registry.MustRegister(collector)
+
+	metricMap := gatherMetrics(t, registry)
+
+	infoMetric := findMetric(t, metricMap, "k6_schedule_info", "schedule_id", "1")
+	assert.Equal(t, "FREQ=DAILY", labelValue(infoMetric, "recurrence"))
+	assert.Equal(t, "Nightly", labelValue(infoMetric, "test_name"))
+
+	nextRunMetric := findMetric(t, metricMap, "k6_schedule_next_run_timestamp_seconds", "schedule_id", "3")
+	assert.Equal(t, float64(futureFire.Unix()), nextRunMetric.GetGauge().GetValue())
+
+	missed := findMetric(t, metricMap, "k6_schedule_missed_runs_total", "schedule_id", "1")
+	assert.Equal(t, float64(1), missed.GetCounter().GetValue())
+
+	kept := findMetric(t, metricMap, "k6_schedule_missed_runs_total", "schedule_id", "2")
+	assert.Equal(t, float64(0), kept.GetCounter().GetValue())
+
+	pending := findMetric(t, metricMap, "k6_schedule_missed_runs_total", "schedule_id", "3")
+	assert.Equal(t, float64(0), pending.GetCounter().GetValue())
+
+	// The same fire time must only be counted once, even if the API keeps reporting it
+	collector.lastScheduleFetch = time.Time{}
+	metricMap = gatherMetrics(t, registry)
+	missed = findMetric(t, metricMap, "k6_schedule_missed_runs_total", "schedule_id", "1")
+	assert.Equal(t, float64(1), missed.GetCounter().GetValue())
+}
+
+func TestCollectorLoadZoneMetrics(t *testing.T) {
+	logger := zaptest.NewLogger(t)
+	cfg := &config.Config{
+		TestCacheTTL:         60 * time.Second,
+		StateCleanupInterval: 5 * time.Minute,
+		APITimeout:           30 * time.Second,
+	}
+
+	now := time.Now()
+	resultPassed := k6client.ResultPassed
+	distribution := []k6client.LoadZoneDistribution{
+		{LoadZone: "amazon:us:ashburn", Percent: 75},
+		{LoadZone: "amazon:jp:tokyo", Percent: 25},
+	}
+
+	mockClient := &mockK6Client{
+		tests: []k6client.Test{
+			{ID: 1, Name: "Global Test", ProjectID: 100},
+		},
+		testRuns: []k6client.TestRun{
+			{
+				ID:           10,
+				TestID:       1,
+				ProjectID:    100,
+				Status:       k6client.StatusRunning,
+				Created:      now.Add(-5 * time.Minute),
+				Options:      map[string]interface{}{"vus": float64(40)},
+				Distribution: distribution,
+				Cost:         &k6client.Cost{VUH: 2, VUHBreakdown: map[string]float64{"amazon:us:ashburn": 1.5, "amazon:jp:tokyo": 0.5}},
+			},
+			{
+				ID:           11,
+				TestID:       1,
+				ProjectID:    100,
+				Status:       k6client.StatusCompleted,
+				Created:      now.Add(-2 * time.Hour),
+				Ended:        timePtr(now.Add(-1 * time.Hour)),
+				Result:       &resultPassed,
+				Options:      map[string]interface{}{"vus": float64(100)},
+				Distribution: distribution,
+				Cost:         &k6client.Cost{VUH: 10, VUHBreakdown: map[string]float64{"amazon:us:ashburn": 7.5, "amazon:jp:tokyo": 2.5}},
+			},
+		},
+	}
+
+	registry := prometheus.NewRegistry()
+	collector := NewCollectorWithRegistry(mockClient, state.NewManager(logger), cfg, logger, registry)
+	registry.MustRegister(collector)
+
+	metricMap := gatherMetrics(t, registry)
+
+	liveVUs := findMetric(t, metricMap, "k6_test_run_load_zone_vus", "load_zone", "amazon:us:ashburn")
+	assert.Equal(t, "10", labelValue(liveVUs, "run_id"))
+	assert.Equal(t, float64(30), liveVUs.GetGauge().GetValue())
+
+	liveVUH := findMetric(t, metricMap, "k6_test_run_load_zone_vuh", "load_zone", "amazon:jp:tokyo")
+	assert.Equal(t, 0.5, liveVUH.GetGauge().GetValue())
+
+	// Finished runs are accounted for once, no matter how often they are scraped
+	for i := 0; i < 2; i++ {
+		metricMap = gatherMetrics(t, registry)
+
+		runs := findMetric(t, metricMap, "k6_load_zone_runs_total", "load_zone", "amazon:jp:tokyo")
+		assert.Equal(t, float64(1), runs.GetCounter().GetValue())
+
+		vus := findMetric(t, metricMap, "k6_load_zone_vus_total", "load_zone", "amazon:jp:tokyo")
+		assert.Equal(t, float64(25), vus.GetCounter().GetValue())
+
+		vuh := findMetric(t, metricMap, "k6_load_zone_vuh_total", "load_zone", "amazon:us:ashburn")
+		assert.Equal(t, 7.5, vuh.GetCounter().GetValue())
+	}
+}
+
+func TestCollectorUserMetrics(t *testing.T) {
+	now := time.Now()
+
+	mockClient := &mockK6Client{
+		tests: []k6client.Test{
+			{ID: 1, Name: "API Test", ProjectID: 100},
+		},
+		testRuns: []k6client.TestRun{
+			{ID: 10, TestID: 1, ProjectID: 100, Status: k6client.StatusRunning, StartedBy: "alice@example.com", Created: now.Add(-5 * time.Minute), Cost: &k6client.Cost{VUH: 1.5}},
+			{ID: 11, TestID: 1, ProjectID: 100, Status: k6client.StatusCompleted, StartedBy: "alice@example.com", Created: now.Add(-2 * time.Hour), Ended: timePtr(now.Add(-1 * time.Hour)), Cost: &k6client.Cost{VUH: 3}},
+			{ID: 12, TestID: 1, ProjectID: 100, Status: k6client.StatusCompleted, StartedBy: "bob@example.com", Created: now.Add(-3 * time.Hour), Ended: timePtr(now.Add(-2 * time.Hour)), Cost: &k6client.Cost{VUH: 4}},
+		},
+	}
+
+	tests := []struct {
+		name      string
+		mode      string
+		aliceUser string
+	}{
+		{name: "plain", mode: config.StartedByPlain, aliceUser: "alice@example.com"},
+		{name: "hash", mode: config.StartedByHash, aliceUser: ""},
+		{name: "redact", mode: config.StartedByRedact, aliceUser: "redacted"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			logger := zaptest.NewLogger(t)
+			cfg := &config.Config{
+				TestCacheTTL:         60 * time.Second,
+				StateCleanupInterval: 5 * time.Minute,
+				APITimeout:           30 * time.Second,
+				StartedByMode:        tt.mode,
+				StarterTeams:         map[string]string{"Alice@example.com": "payments"},
+			}
+
+			registry := prometheus.NewRegistry()
+			collector := NewCollectorWithRegistry(mockClient, state.NewManager(logger), cfg, logger, registry)
+			registry.MustRegister(collector)
+
+			metricMap := gatherMetrics(t, registry)
+
+			runsMetric, exists := metricMap["k6_user_test_runs_started"]
+			require.True(t, exists)
+			// Redacted starters are still split by team
+			assert.Len(t, runsMetric.Metric, 2)
+
+			runs := findMetric(t, metricMap, "k6_user_test_runs_started", "team", "payments")
+			assert.Equal(t, float64(2), runs.GetGauge().GetValue())
+
+			vuh := findMetric(t, metricMap, "k6_user_vuh_consumed", "team", "payments")
+			assert.Equal(t, 4.5, vuh.GetGauge().GetValue())
+
+			startedBy := labelValue(runs, "started_by")
+			if tt.aliceUser != "" {
+				assert.Equal(t, tt.aliceUser, startedBy)
+			} else {
+				assert.Len(t, startedBy, 16)
+				assert.NotContains(t, startedBy, "alice")
+			}
+
+			unknown := findMetric(t, metricMap, "k6_user_test_runs_started", "team", "unknown")
+			assert.Equal(t, float64(1), unknown.GetGauge().GetValue())
+		})
+	}
+}
+
+func TestCollectorStuckRuns(t *testing.T) {
+	logger := zaptest.NewLogger(t)
+	cfg := &config.Config{
+		TestCacheTTL:         60 * time.Second,
+		StateCleanupInterval: 5 * time.Minute,
+		APITimeout:           30 * time.Second,
+		StuckRunTimeouts: map[string]string{
+			"initializing":        "5m",
+			"running":             "1h",
+			"project/200/running": "4h",
+		},
+	}
+
+	now := time.Now()
+
+	mockClient := &mockK6Client{
+		tests: []k6client.Test{
+			{ID: 1, Name: "Long Soak", ProjectID: 100},
+			{ID: 2, Name: "Soak Project", ProjectID: 200},
+			{ID: 3, Name: "Slow Start", ProjectID: 100},
+		},
+		testRuns: []k6client.TestRun{
+			{
+				// Created long ago but only running for 10 minutes, not stuck
+				ID: 10, TestID: 1, ProjectID: 100, Status: k6client.StatusRunning, Created: now.Add(-3 * time.Hour),
+				StatusHistory: []k6client.StatusHistoryEntry{
+					{Type: k6client.StatusInitializing, Entered: now.Add(-3 * time.Hour)},
+					{Type: k6client.StatusRunning, Entered: now.Add(-10 * time.Minute)},
+				},
+			},
+			{
+				// Running for 2 hours, but the project allows 4 hours
+				ID: 11, TestID: 2, ProjectID: 200, Status: k6client.StatusRunning, Created: now.Add(-2 * time.Hour),
+				StatusHistory: []k6client.StatusHistoryEntry{
+					{Type: k6client.StatusRunning, Entered: now.Add(-2 * time.Hour)},
+				},
+			},
+			{
+				// Initializing for 20 minutes
+				ID: 12, TestID: 3, ProjectID: 100, Status: k6client.StatusInitializing, Created: now.Add(-20 * time.Minute),
+				StatusHistory: []k6client.StatusHistoryEntry{
+					{Type: k6client.StatusInitializing, Entered: now.Add(-20 * time.Minute)},
+				},
+			},
+		},
+	}
+
+	registry := prometheus.NewRegistry()
+	collector := NewCollectorWithRegistry(mockClient, state.NewManager(logger), cfg, logger, registry)
+	registry.MustRegister(collector)
+
+	metricMap := gatherMetrics(t, registry)
+
+	timeInStatus := findMetric(t, metricMap, "k6_test_run_status_duration_seconds", "run_id", "10")
+	assert.InDelta(t, (10 * time.Minute).Seconds(), timeInStatus.GetGauge().GetValue(), 5)
+
+	stuckMetric, exists := metricMap["k6_test_run_stuck"]
+	require.True(t, exists, "k6_test_run_stuck metric should exist")
+	require.Len(t, stuckMetric.Metric, 1, "Only the slow initializing run should be stuck")
+	assert.Equal(t, "12", labelValue(stuckMetric.Metric[0], "run_id"))
+	assert.Equal(t, "initializing_timeout", labelValue(stuckMetric.Metric[0], "reason"))
+
+	initializing := findMetric(t, metricMap, "k6_test_runs_stuck", "reason", "initializing_timeout")
+	assert.Equal(t, float64(1), initializing.GetGauge().GetValue())
+
+	running := findMetric(t, metricMap, "k6_test_runs_stuck", "reason", "running_timeout")
+	assert.Equal(t, float64(0), running.GetGauge().GetValue())
+}
+
+func TestCollectorHealth(t *testing.T) {
+	logger := zaptest.NewLogger(t)
+	cfg := &config.Config{
+		TestCacheTTL:         60 * time.Second,
+		StateCleanupInterval: 5 * time.Minute,
+		APITimeout:           30 * time.Second,
+	}
+
+	mockClient := &mockK6Client{
+		err: &k6client.APIError{StatusCode: 401, Status: "401 Unauthorized"},
+	}
+
+	registry := prometheus.NewRegistry()
+	collector := NewCollectorWithRegistry(mockClient, state.NewManager(logger), cfg, logger, registry)
+
+	// Not ready before the first successful fetch
+	require.Error(t, collector.Refresh(context.Background()))
+	health := collector.Health()
+	ready, reason := health.Ready(5*time.Minute, time.Now())
+	assert.False(t, ready)
+	assert.Equal(t, "no successful fetch yet", reason)
+	assert.Equal(t, k6client.ErrorClassUnauthorized, health.LastErrorClass)
+	assert.Nil(t, collector.Snapshot())
+
+	// Ready after a successful fetch
+	now := time.Now()
+	mockClient.err = nil
+	mockClient.testRuns = []k6client.TestRun{
+		{ID: 1, TestID: 1, ProjectID: 100, Status: k6client.StatusRunning, Created: now.Add(-time.Minute)},
+	}
+	require.NoError(t, collector.Refresh(context.Background()))
+	health = collector.Health()
+	ready, _ = health.Ready(5*time.Minute, time.Now())
+	assert.True(t, ready)
+	assert.Equal(t, 1, health.TrackedRuns)
+	require.NotNil(t, collector.Snapshot())
+	assert.Len(t, collector.Snapshot().Runs, 1)
+
+	// Not ready once the last success is too old
+	ready, reason = health.Ready(5*time.Minute, time.Now().Add(10*time.Minute))
+	assert.False(t, ready)
+	assert.Contains(t, reason, "older than 5m0s")
+}
+
+// circuitMockClient is a mock client guarded by a circuit breaker
+type circuitMockClient struct {
+	*mockK6Client
+	state string
+}
+
+func (m *circuitMockClient) CircuitState() string {
+	return m.state
+}
+
+func TestCollectorStaleSnapshot(t *testing.T) {
+	logger := zaptest.NewLogger(t)
+	cfg := &config.Config{
+		TestCacheTTL:         60 * time.Second,
+		StateCleanupInterval: 5 * time.Minute,
+		APITimeout:           30 * time.Second,
+	}
+
+	now := time.Now()
+	mockClient := &circuitMockClient{
+		mockK6Client: &mockK6Client{
+			testRuns: []k6client.TestRun{
+				{ID: 1, TestID: 1, ProjectID: 100, Status: k6client.StatusRunning, Created: now.Add(-time.Minute)},
+			},
+		},
+		state: k6client.CircuitClosed,
+	}
+
+	registry := prometheus.NewRegistry()
+	collector := NewCollectorWithRegistry(mockClient, state.NewManager(logger), cfg, logger, registry)
+	registry.MustRegister(collector)
+
+	metricMap := gatherMetrics(t, registry)
+	assert.Equal(t, float64(0), metricMap["k6_exporter_snapshot_stale"].Metric[0].GetGauge().GetValue())
+	ready, _ := collector.Health().Ready(5*time.Minute, time.Now())
+	assert.True(t, ready)
+
+	// The last snapshot is served while the circuit breaker is open
+	mockClient.err = fmt.Errorf("list test runs: %w", k6client.ErrCircuitOpen)
+	mockClient.state = k6client.CircuitOpen
+	metricMap = gatherMetrics(t, registry)
+	assert.Len(t, metricMap["k6_test_run_info"].Metric, 1)
+	assert.Equal(t, float64(1), metricMap["k6_exporter_snapshot_stale"].Metric[0].GetGauge().GetValue())
+	assert.False(t, collector.Snapshot().Stale, "the stored snapshot is not modified")
+
+	health := collector.Health()
+	assert.Equal(t, k6client.CircuitOpen, health.CircuitBreaker)
+	assert.Equal(t, k6client.ErrorClassCircuitOpen, health.LastErrorClass)
+	ready, reason := health.Ready(5*time.Minute, time.Now())
+	assert.False(t, ready)
+	assert.Equal(t, "k6 API circuit breaker is open", reason)
+
+	// Other errors are not hidden by the snapshot
+	mockClient.err = &k6client.APIError{StatusCode: 500, Status: "500 Internal Server Error"}
+	metricMap = gatherMetrics(t, registry)
+	_, exists := metricMap["k6_test_run_info"]
+	assert.False(t, exists)
+}
+
+func TestCollectorFinishedRunEvents(t *testing.T) {
+	logger := zaptest.NewLogger(t)
+	cfg := &config.Config{
+		TestCacheTTL:         60 * time.Second,
+		StateCleanupInterval: 5 * time.Minute,
+		APITimeout:           30 * time.Second,
+	}
+
+	stateManager := state.NewManager(logger)
+	var events []state.Event
+	stateManager.Subscribe(func(event state.Event) {
+		events = append(events, event)
+	})
+
+	now := time.Now()
+	mockClient := &mockK6Client{
+		tests: []k6client.Test{{ID: 1, Name: "Smoke", ProjectID: 100}},
+		testRuns: []k6client.TestRun{
+			{ID: 10, TestID: 1, ProjectID: 100, Status: k6client.StatusRunning, Created: now.Add(time.Second)},
+		},
+	}
+
+	collector := NewCollectorWithRegistry(mockClient, stateManager, cfg, logger, prometheus.NewRegistry())
+	require.NoError(t, collector.Refresh(context.Background()))
+	assert.Equal(t, 1, stateManager.GetStateCount())
+
+	// The finished run is no longer tracked and reported once
+	passed := k6client.ResultPassed
+	mockClient.testRuns[0].Status = k6client.StatusCompleted
+	mockClient.testRuns[0].Result = &passed
+	mockClient.testRuns[0].Ended = timePtr(now.Add(time.Minute))
+	require.NoError(t, collector.Refresh(context.Background()))
+	require.NoError(t, collector.Refresh(context.Background()))
+	assert.Equal(t, 0, stateManager.GetStateCount())
+
+	require.Len(t, events, 2)
+	assert.Equal(t, state.EventRunStarted, events[0].Type)
+	assert.Equal(t, "Smoke", events[0].TestName)
+	assert.Equal(t, state.EventRunFinished, events[1].Type)
+	assert.Equal(t, k6client.ResultPassed, events[1].Result)
+}
@@ -0,0 +1,223 @@
+package collector
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap/zaptest"
+
+	"github.com/grafana-cloud-k6-prometheus-exporter/internal/config"
+	"github.com/grafana-cloud-k6-prometheus-exporter/internal/k6client"
+	"github.com/grafana-cloud-k6-prometheus-exporter/internal/k6fake"
+	"github.com/grafana-cloud-k6-prometheus-exporter/internal/state"
+)
+
+func TestCollectorAgainstFakeAPI(t *testing.T) {
+	logger := zaptest.NewLogger(t)
+	server := k6fake.NewServer("token", "stack")
+	defer server.Close()
+	server.SetPageSize(2)
+
+	now := time.Now().UTC()
+	server.AddProject(k6client.Project{ID: 100, Name: "Checkout"})
+	server.AddTest(k6client.Test{ID: 1, ProjectID: 100, Name: "Checkout flow", Updated: now.Add(-time.Hour)})
+	server.AddTest(k6client.Test{ID: 2, ProjectID: 100, Name: "Cart", Updated: now.Add(-time.Hour)})
+	server.AddTest(k6client.Test{ID: 3, ProjectID: 100, Name: "Payment", Updated: now.Add(-time.Hour)})
+	server.AddRun(k6client.TestRun{ID: 10, TestID: 1, Status: k6client.StatusRunning, Created: now.Add(-time.Minute)})
+	server.AddRun(k6client.TestRun{ID: 11, TestID: 3, Status: k6client.StatusCompleted, Created: now.Add(-2 * time.Hour), Ended: timePtr(now.Add(-time.Hour))})
+
+	cfg := &config.Config{
+		TestCacheTTL:         time.Hour,
+		StateCleanupInterval: 5 * time.Minute,
+		APITimeout:           5 * time.Second,
+	}
+	client := k6client.NewClient(server.URL, "stack", "token", logger)
+	registry := prometheus.NewRegistry()
+	collector := NewCollectorWithRegistry(client, state.NewManager(logger), cfg, logger, registry)
+	registry.MustRegister(collector)
+
+	metricMap := gatherMetrics(t, registry)
+	assert.Len(t, metricMap["k6_test_info"].Metric, 3, "all pages of tests are cached")
+	runInfo := findMetric(t, metricMap, "k6_test_run_info", "run_id", "10")
+	assert.Equal(t, "Checkout flow", labelValue(runInfo, "test_name"))
+	endMetric := findMetric(t, metricMap, "k6_test_last_run_end_timestamp_seconds", "test_id", "3")
+	assert.Equal(t, float64(now.Add(-time.Hour).Unix()), endMetric.GetGauge().GetValue())
+
+	// A test created after the test cache refresh is named by its first run
+	server.AddTest(k6client.Test{ID: 4, ProjectID: 100, Name: "Search", Updated: now})
+	server.AddRun(k6client.TestRun{ID: 12, TestID: 4, Status: k6client.StatusRunning, Created: now})
+	require.NoError(t, collector.Refresh(context.Background()))
+	assert.Equal(t, "Search", collector.getTestName(4))
+
+	// Server errors are reported by the health
+	server.Fail(k6fake.Failure{StatusCode: http.StatusInternalServerError})
+	require.Error(t, collector.Refresh(context.Background()))
+	assert.Equal(t, k6client.ErrorClassServerError, collector.Health().LastErrorClass)
+}
+
+func TestCollectorReplaysRecordedAPI(t *testing.T) {
+	logger := zaptest.NewLogger(t)
+	server := k6fake.NewServer("token", "stack")
+	defer server.Close()
+	server.SetPageSize(2)
+
+	now := time.Now().UTC()
+	server.AddProject(k6client.Project{ID: 100, Name: "Checkout"})
+	server.AddTest(k6client.Test{ID: 1, ProjectID: 100, Name: "Checkout flow", Updated: now.Add(-time.Hour)})
+	server.AddTest(k6client.Test{ID: 2, ProjectID: 100, Name: "Cart", Updated: now.Add(-time.Hour)})
+	server.AddTest(k6client.Test{ID: 3, ProjectID: 100, Name: "Payment", Updated: now.Add(-time.Hour)})
+	server.AddRun(k6client.TestRun{ID: 10, TestID: 1, Status: k6client.StatusRunning, Created: now.Add(-time.Minute)})
+	server.AddRun(k6client.TestRun{ID: 11, TestID: 3, Status: k6client.StatusCompleted, Created: now.Add(-2 * time.Hour), Ended: timePtr(now.Add(-time.Hour))})
+
+	cfg := &config.Config{
+		TestCacheTTL:         time.Hour,
+		StateCleanupInterval: 5 * time.Minute,
+		APITimeout:           5 * time.Second,
+	}
+	dir := t.TempDir()
+
+	// scrape runs a fresh exporter against the transport and returns its k6 series.
+	// Durations of active runs are measured against the clock and differ between scrapes.
+	scrape := func(transport http.RoundTripper) map[string]string {
+		client := k6client.NewClient(server.URL, "stack", "token", logger).
+			WithHTTPClient(&http.Client{Transport: transport})
+		registry := prometheus.NewRegistry()
+		collector := NewCollectorWithRegistry(client, state.NewManager(logger), cfg, logger, registry)
+		registry.MustRegister(collector)
+
+		series := make(map[string]string)
+		for name, mf := range gatherMetrics(t, registry) {
+			if !strings.HasPrefix(name, "k6_exporter_") && !strings.HasSuffix(name, "duration_seconds") {
+				series[name] = mf.String()
+			}
+		}
+		return series
+	}
+
+	recorder, err := k6client.NewRecorder(dir, http.DefaultTransport)
+	require.NoError(t, err)
+	recorded := scrape(recorder)
+	require.Contains(t, recorded, "k6_test_run_info")
+
+	// The replay reproduces the metrics without the API
+	server.Close()
+	replayer, err := k6client.NewReplayer(dir, logger)
+	require.NoError(t, err)
+	assert.Equal(t, recorded, scrape(replayer))
+}
+
+func TestCollectorReplaysOldRecording(t *testing.T) {
+	logger := zaptest.NewLogger(t)
+	server := k6fake.NewServer("token", "stack")
+	defer server.Close()
+
+	// Recorded three days ago, while run 10 was active
+	recordedAt := time.Now().UTC().Add(-72 * time.Hour).Truncate(time.Second)
+	server.AddProject(k6client.Project{ID: 100, Name: "Checkout"})
+	server.AddTest(k6client.Test{ID: 1, ProjectID: 100, Name: "Checkout flow", Updated: recordedAt.Add(-time.Hour)})
+	server.AddRun(k6client.TestRun{ID: 10, TestID: 1, Status: k6client.StatusRunning, Created: recordedAt.Add(-time.Minute)})
+
+	cfg := &config.Config{
+		TestCacheTTL:         time.Hour,
+		StateCleanupInterval: 5 * time.Minute,
+		APITimeout:           5 * time.Second,
+	}
+	dir := t.TempDir()
+
+	newCollector := func(transport http.RoundTripper, registry *prometheus.Registry) *Collector {
+		client := k6client.NewClient(server.URL, "stack", "token", logger).
+			WithHTTPClient(&http.Client{Transport: transport})
+		collector := NewCollectorWithRegistry(client, state.NewManager(logger), cfg, logger, registry)
+		registry.MustRegister(collector)
+		return collector
+	}
+
+	recorder, err := k6client.NewRecorder(dir, http.DefaultTransport)
+	require.NoError(t, err)
+	require.NoError(t, newCollector(recorder, prometheus.NewRegistry()).WithClock(func() time.Time { return recordedAt }).Refresh(context.Background()))
+	files, err := filepath.Glob(filepath.Join(dir, "*.json"))
+	require.NoError(t, err)
+	require.NotEmpty(t, files)
+	for _, file := range files {
+		data, err := os.ReadFile(file)
+		require.NoError(t, err)
+		var fixture k6client.Fixture
+		require.NoError(t, json.Unmarshal(data, &fixture))
+		fixture.Time = recordedAt
+		data, err = json.Marshal(fixture)
+		require.NoError(t, err)
+		require.NoError(t, os.WriteFile(file, data, 0o600))
+	}
+	server.Close()
+
+	// The replay sees the run within the fetch window of the recording
+	replayer, err := k6client.NewReplayer(dir, logger)
+	require.NoError(t, err)
+	registry := prometheus.NewRegistry()
+	newCollector(replayer, registry).WithClock(replayer.Now)
+	metricMap := gatherMetrics(t, registry)
+	findMetric(t, metricMap, "k6_test_run_info", "run_id", "10")
+	duration := findMetric(t, metricMap, "k6_test_run_duration_seconds", "test_id", "1")
+	assert.InDelta(t, time.Minute.Seconds(), duration.GetGauge().GetValue(), 5)
+
+	// With the current time the run has left the fetch window
+	replayer, err = k6client.NewReplayer(dir, logger)
+	require.NoError(t, err)
+	registry = prometheus.NewRegistry()
+	newCollector(replayer, registry)
+	assert.NotContains(t, gatherMetrics(t, registry), "k6_test_run_info")
+}
+
+func TestCollectorSeedsTestStatsAfterRestart(t *testing.T) {
+	logger := zaptest.NewLogger(t)
+	server := k6fake.NewServer("token", "stack")
+	defer server.Close()
+
+	now := time.Now().UTC().Truncate(time.Second)
+	server.AddProject(k6client.Project{ID: 100, Name: "Checkout"})
+	server.AddTest(k6client.Test{ID: 1, ProjectID: 100, Name: "Nightly checkout", Updated: now.Add(-time.Hour)})
+	server.AddTest(k6client.Test{ID: 2, ProjectID: 100, Name: "Never run", Updated: now.Add(-time.Hour)})
+	server.AddTest(k6client.Test{ID: 3, ProjectID: 100, Name: "Cart", Updated: now.Add(-time.Hour)})
+	server.AddRun(k6client.TestRun{ID: 10, TestID: 1, Status: k6client.StatusCompleted, Created: now.Add(-74 * time.Hour), Ended: timePtr(now.Add(-73 * time.Hour))})
+	server.AddRun(k6client.TestRun{ID: 11, TestID: 1, Status: k6client.StatusCompleted, Created: now.Add(-50 * time.Hour), Ended: timePtr(now.Add(-49 * time.Hour))})
+	server.AddRun(k6client.TestRun{ID: 12, TestID: 3, Status: k6client.StatusRunning, Created: now.Add(-time.Minute)})
+
+	cfg := &config.Config{
+		TestCacheTTL:         time.Hour,
+		StateCleanupInterval: 5 * time.Minute,
+		APITimeout:           5 * time.Second,
+	}
+	client := k6client.NewClient(server.URL, "stack", "token", logger)
+	registry := prometheus.NewRegistry()
+	collector := NewCollectorWithRegistry(client, state.NewManager(logger), cfg, logger, registry)
+	registry.MustRegister(collector)
+
+	// The last run of a test outside the fetch window is looked up after a restart
+	metricMap := gatherMetrics(t, registry)
+	startMetric := findMetric(t, metricMap, "k6_test_last_run_start_timestamp_seconds", "test_id", "1")
+	assert.Equal(t, float64(now.Add(-50*time.Hour).Unix()), startMetric.GetGauge().GetValue())
+	endMetric := findMetric(t, metricMap, "k6_test_last_run_end_timestamp_seconds", "test_id", "1")
+	assert.Equal(t, float64(now.Add(-49*time.Hour).Unix()), endMetric.GetGauge().GetValue())
+	for _, m := range metricMap["k6_test_last_run_start_timestamp_seconds"].Metric {
+		assert.NotEqual(t, "2", labelValue(m, "test_id"), "tests without runs have no last run")
+	}
+
+	// Each test without runs in the window is looked up once
+	require.NoError(t, collector.Refresh(context.Background()))
+	var lookups []string
+	for _, request := range server.Requests() {
+		if request.Query.Get("$top") == "1" {
+			lookups = append(lookups, request.Path)
+		}
+	}
+	assert.ElementsMatch(t, []string{"/cloud/v6/load_tests/1/test_runs", "/cloud/v6/load_tests/2/test_runs"}, lookups)
+}
@@ -0,0 +1,64 @@
+package collector
+
+import (
+	"time"
+
+	"github.com/grafana-cloud-k6-prometheus-exporter/internal/k6client"
+)
+
+// Health describes the k6 API health as seen by the collector
+type Health struct {
+	LastSuccess    *time.Time `json:"last_success,omitempty"`
+	LastErrorClass string     `json:"last_error_class,omitempty"`
+	LastErrorTime  *time.Time `json:"last_error_time,omitempty"`
+	TrackedRuns    int        `json:"tracked_runs"`
+	CircuitBreaker string     `json:"circuit_breaker,omitempty"`
+}
+
+// circuitStater is implemented by clients guarded by a circuit breaker
+type circuitStater interface {
+	CircuitState() string
+}
+
+// Ready reports whether a fetch succeeded within maxStaleness, with the reason if not.
+// A zero maxStaleness only requires a first successful fetch.
+func (h Health) Ready(maxStaleness time.Duration, now time.Time) (bool, string) {
+	if h.LastSuccess == nil {
+		return false, "no successful fetch yet"
+	}
+	if h.CircuitBreaker == k6client.CircuitOpen {
+		return false, "k6 API circuit breaker is open"
+	}
+	if maxStaleness > 0 && now.Sub(*h.LastSuccess) > maxStaleness {
+		return false, "last successful fetch is older than " + maxStaleness.String()
+	}
+	return true, ""
+}
+
+// Health returns the current k6 API health
+func (c *Collector) Health() Health {
+	c.healthMutex.RLock()
+	health := c.health
+	c.healthMutex.RUnlock()
+
+	health.TrackedRuns = c.stateManager.GetStateCountByStack(c.config.StackName)
+	if client, ok := c.client.(circuitStater); ok {
+		health.CircuitBreaker = client.CircuitState()
+	}
+	return health
+}
+
+// recordFetchSuccess records a successful fetch of the test runs
+func (c *Collector) recordFetchSuccess(now time.Time) {
+	c.healthMutex.Lock()
+	defer c.healthMutex.Unlock()
+	c.health.LastSuccess = &now
+}
+
+// recordFetchError records a failed fetch of the test runs
+func (c *Collector) recordFetchError(err error, now time.Time) {
+	c.healthMutex.Lock()
+	defer c.healthMutex.Unlock()
+	c.health.LastErrorClass = k6client.ErrorClass(err)
+	c.health.LastErrorTime = &now
+}
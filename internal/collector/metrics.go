@@ -49,6 +49,150 @@ var (
 		nil,
 	)
 
+	testRunLoadZoneVUsDesc = prometheus.NewDesc(
+		"k6_test_run_load_zone_vus",
+		"VUs allocated to each load zone by active test runs",
+		[]string{"test_name", "test_id", "project_id", "run_id", "load_zone"},
+		nil,
+	)
+
+	testRunLoadZoneVUHDesc = prometheus.NewDesc(
+		"k6_test_run_load_zone_vuh",
+		"Virtual User Hours consumed in each load zone by active test runs",
+		[]string{"test_name", "test_id", "project_id", "run_id", "load_zone"},
+		nil,
+	)
+
+	testRunStatusDurationSecondsDesc = prometheus.NewDesc(
+		"k6_test_run_status_duration_seconds",
+		"Time active test runs have spent in their current status in seconds",
+		[]string{"test_name", "test_id", "project_id", "run_id", "status"},
+		nil,
+	)
+
+	testRunStuckDesc = prometheus.NewDesc(
+		"k6_test_run_stuck",
+		"Active test runs that exceeded the time limit of their current status (always 1)",
+		[]string{"test_name", "test_id", "project_id", "run_id", "status", "reason"},
+		nil,
+	)
+
+	testRunsStuckDesc = prometheus.NewDesc(
+		"k6_test_runs_stuck",
+		"Number of stuck test runs by reason",
+		[]string{"reason"},
+		nil,
+	)
+
+	// Load zone metrics of finished runs
+	loadZoneRunsTotalDesc = prometheus.NewDesc(
+		"k6_load_zone_runs_total",
+		"Total number of finished test runs that used the load zone",
+		[]string{"test_name", "test_id", "project_id", "load_zone"},
+		nil,
+	)
+
+	loadZoneVUsTotalDesc = prometheus.NewDesc(
+		"k6_load_zone_vus_total",
+		"Total number of VUs allocated to the load zone by finished test runs",
+		[]string{"test_name", "test_id", "project_id", "load_zone"},
+		nil,
+	)
+
+	loadZoneVUHTotalDesc = prometheus.NewDesc(
+		"k6_load_zone_vuh_total",
+		"Total Virtual User Hours consumed in the load zone by finished test runs",
+		[]string{"test_name", "test_id", "project_id", "load_zone"},
+		nil,
+	)
+
+	// Test metrics
+	testInfoDesc = prometheus.NewDesc(
+		"k6_test_info",
+		"Information about load tests",
+		[]string{"test_name", "test_id", "project_id", "baseline_test_run_id"},
+		nil,
+	)
+
+	testCreatedTimestampDesc = prometheus.NewDesc(
+		"k6_test_created_timestamp_seconds",
+		"Unix timestamp when the test was created",
+		[]string{"test_name", "test_id", "project_id"},
+		nil,
+	)
+
+	testUpdatedTimestampDesc = prometheus.NewDesc(
+		"k6_test_updated_timestamp_seconds",
+		"Unix timestamp when the test was last updated",
+		[]string{"test_name", "test_id", "project_id"},
+		nil,
+	)
+
+	testLastRunStartTimestampDesc = prometheus.NewDesc(
+		"k6_test_last_run_start_timestamp_seconds",
+		"Unix timestamp when the most recent run of the test was created",
+		[]string{"test_name", "test_id", "project_id"},
+		nil,
+	)
+
+	testLastRunEndTimestampDesc = prometheus.NewDesc(
+		"k6_test_last_run_end_timestamp_seconds",
+		"Unix timestamp when the most recent finished run of the test ended",
+		[]string{"test_name", "test_id", "project_id"},
+		nil,
+	)
+
+	testLastRunResultDesc = prometheus.NewDesc(
+		"k6_test_last_run_result",
+		"Result of the most recent finished run of the test (always 1, see result label)",
+		[]string{"test_name", "test_id", "project_id", "result"},
+		nil,
+	)
+
+	testConsecutiveFailuresDesc = prometheus.NewDesc(
+		"k6_test_consecutive_failures",
+		"Number of consecutive failed runs of the test",
+		[]string{"test_name", "test_id", "project_id"},
+		nil,
+	)
+
+	// Schedule metrics
+	scheduleInfoDesc = prometheus.NewDesc(
+		"k6_schedule_info",
+		"Information about test schedules",
+		[]string{"schedule_id", "test_name", "test_id", "project_id", "recurrence", "deactivated"},
+		nil,
+	)
+
+	scheduleNextRunTimestampDesc = prometheus.NewDesc(
+		"k6_schedule_next_run_timestamp_seconds",
+		"Unix timestamp of the next expected fire time of the schedule",
+		[]string{"schedule_id", "test_name", "test_id", "project_id"},
+		nil,
+	)
+
+	scheduleMissedRunsTotalDesc = prometheus.NewDesc(
+		"k6_schedule_missed_runs_total",
+		"Total number of expected scheduled runs that did not start within the grace period",
+		[]string{"schedule_id", "test_name", "test_id", "project_id"},
+		nil,
+	)
+
+	// Per-user metrics
+	userTestRunsStartedDesc = prometheus.NewDesc(
+		"k6_user_test_runs_started",
+		"Number of test runs started per user within the last 24 hours",
+		[]string{"started_by", "team"},
+		nil,
+	)
+
+	userVUHConsumedDesc = prometheus.NewDesc(
+		"k6_user_vuh_consumed",
+		"Virtual User Hours consumed by test runs per user within the last 24 hours",
+		[]string{"started_by", "team"},
+		nil,
+	)
+
 	// Operational metrics
 	exporterAPIRequestsTotalDesc = prometheus.NewDesc(
 		"k6_exporter_api_requests_total",
@@ -109,6 +253,7 @@ type OperationalMetrics struct {
 	TestRunsTracked     prometheus.Gauge
 	ScrapeDuration      prometheus.Histogram
 	ScrapeErrorsTotal   *prometheus.CounterVec
+	SnapshotStale       prometheus.Gauge
 }
 
 // NewOperationalMetrics creates operational metrics that are registered globally
@@ -161,6 +306,12 @@ func NewOperationalMetricsWithRegistry(reg prometheus.Registerer) *OperationalMe
 			},
 			[]string{"error_type"},
 		),
+		SnapshotStale: prometheus.NewGauge(
+			prometheus.GaugeOpts{
+				Name: "k6_exporter_snapshot_stale",
+				Help: "Whether the last scrape served a stale snapshot because the k6 API circuit breaker is open",
+			},
+		),
 	}
 
 	// Register all metrics if registerer is provided
@@ -172,6 +323,7 @@ func NewOperationalMetricsWithRegistry(reg prometheus.Registerer) *OperationalMe
 			metrics.TestRunsTracked,
 			metrics.ScrapeDuration,
 			metrics.ScrapeErrorsTotal,
+			metrics.SnapshotStale,
 		)
 	}
 
@@ -0,0 +1,159 @@
+package collector
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	dto "github.com/prometheus/client_model/go"
+	"go.uber.org/zap"
+)
+
+// ErrProjectNotMonitored is returned when probing a project outside the PROJECTS of the stack
+var ErrProjectNotMonitored = errors.New("project is not monitored")
+
+// ErrProjectNotFound is returned when probing a project the stack does not have
+var ErrProjectNotFound = errors.New("project does not exist")
+
+var (
+	probeSuccessDesc = prometheus.NewDesc(
+		"k6_probe_success",
+		"Whether the k6 API data of the probed target could be collected",
+		nil,
+		nil,
+	)
+
+	probeDurationSecondsDesc = prometheus.NewDesc(
+		"k6_probe_duration_seconds",
+		"Duration of the probe in seconds",
+		nil,
+		nil,
+	)
+)
+
+// Probe is a collector for a single target of the /probe endpoint. It uses the
+// snapshot and caches of its collector and only sends the series of the probed
+// projects. Series without a project_id label are stack-wide and only sent when
+// the whole stack is probed.
+type Probe struct {
+	collector *Collector
+	projects  map[string]bool // nil for every monitored project
+}
+
+// NewProbe creates a probe of the given projects, or of the whole stack if there are none.
+// Without a project filter the projects must exist in the stack.
+func (c *Collector) NewProbe(ctx context.Context, projects []int) (*Probe, error) {
+	probe := &Probe{collector: c}
+	if len(projects) == 0 {
+		return probe, nil
+	}
+
+	monitored := make(map[string]bool, len(c.config.Projects))
+	for _, project := range c.config.Projects {
+		monitored[project] = true
+	}
+
+	probe.projects = make(map[string]bool, len(projects))
+	var unknown []int
+	for _, project := range projects {
+		id := strconv.Itoa(project)
+		if len(monitored) > 0 && !monitored[id] {
+			return nil, ErrProjectNotMonitored
+		}
+		if len(monitored) == 0 && !c.hasTestsInProject(project) {
+			unknown = append(unknown, project)
+		}
+		probe.projects[id] = true
+	}
+
+	if len(unknown) > 0 {
+		if err := c.checkProjectsExist(ctx, unknown); err != nil {
+			return nil, err
+		}
+	}
+	return probe, nil
+}
+
+// hasTestsInProject returns true if a cached test belongs to the project
+func (c *Collector) hasTestsInProject(projectID int) bool {
+	c.testCacheMutex.RLock()
+	defer c.testCacheMutex.RUnlock()
+	for _, test := range c.testCache {
+		if test.ProjectID == projectID {
+			return true
+		}
+	}
+	return false
+}
+
+// checkProjectsExist looks up projects without cached tests, which may be empty or not cached yet
+func (c *Collector) checkProjectsExist(ctx context.Context, projectIDs []int) error {
+	existing, err := c.client.ListProjects(ctx)
+	if err != nil {
+		return fmt.Errorf("list projects: %w", err)
+	}
+
+	exists := make(map[int]bool, len(existing))
+	for _, project := range existing {
+		exists[project.ID] = true
+	}
+	for _, projectID := range projectIDs {
+		if !exists[projectID] {
+			return ErrProjectNotFound
+		}
+	}
+	return nil
+}
+
+// Describe implements prometheus.Collector. The probe sends no descriptors,
+// which makes it an unchecked collector.
+func (p *Probe) Describe(ch chan<- *prometheus.Desc) {}
+
+// Collect implements prometheus.Collector
+func (p *Probe) Collect(ch chan<- prometheus.Metric) {
+	start := time.Now()
+
+	metrics := make(chan prometheus.Metric)
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		for metric := range metrics {
+			if p.matches(metric) {
+				ch <- metric
+			}
+		}
+	}()
+
+	err := p.collector.collectMetrics(metrics)
+	close(metrics)
+	<-done
+
+	success := 1.0
+	if err != nil {
+		success = 0
+		p.collector.logger.Warn("probe failed", zap.Error(err))
+	}
+	ch <- prometheus.MustNewConstMetric(probeSuccessDesc, prometheus.GaugeValue, success)
+	ch <- prometheus.MustNewConstMetric(probeDurationSecondsDesc, prometheus.GaugeValue, time.Since(start).Seconds())
+}
+
+// matches returns true if the metric belongs to the probed projects
+func (p *Probe) matches(metric prometheus.Metric) bool {
+	if p.projects == nil {
+		return true
+	}
+
+	var m dto.Metric
+	if err := metric.Write(&m); err != nil {
+		return false
+	}
+	for _, label := range m.GetLabel() {
+		if label.GetName() == "project_id" {
+			return p.projects[label.GetValue()]
+		}
+	}
+	return false
+}
@@ -0,0 +1,210 @@
+package collector
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"go.uber.org/zap"
+
+	"github.com/grafana-cloud-k6-prometheus-exporter/internal/k6client"
+)
+
+// scheduleState tracks a schedule together with the fire times still waiting for a run
+type scheduleState struct {
+	Schedule   k6client.Schedule
+	Pending    []time.Time // Expected fire times not yet matched against observed runs
+	Evaluated  time.Time   // Latest fire time that has been matched
+	MissedRuns int
+}
+
+// updateSchedules refreshes the cached schedules and records their next expected fire time
+func (c *Collector) updateSchedules(ctx context.Context) error {
+	schedules, err := c.client.ListSchedules(ctx)
+	if err != nil {
+		return fmt.Errorf("list schedules: %w", err)
+	}
+
+	c.schedulesMutex.Lock()
+	defer c.schedulesMutex.Unlock()
+
+	seen := make(map[int]bool, len(schedules))
+	for _, schedule := range schedules {
+		seen[schedule.ID] = true
+
+		tracked, exists := c.schedules[schedule.ID]
+		if !exists {
+			tracked = &scheduleState{}
+			c.schedules[schedule.ID] = tracked
+		}
+		tracked.Schedule = schedule
+
+		if schedule.Deactivated {
+			tracked.Pending = nil
+			continue
+		}
+
+		// Remember every distinct fire time, the API moves next_run forward as soon
+		// as the schedule fires so older fire times stay pending until evaluated.
+		// A broken schedule may keep reporting a fire time that was already evaluated.
+		if schedule.NextRun != nil && schedule.NextRun.After(tracked.Evaluated) && !containsTime(tracked.Pending, *schedule.NextRun) {
+			tracked.Pending = append(tracked.Pending, *schedule.NextRun)
+		}
+	}
+
+	// Forget schedules that were deleted
+	for id := range c.schedules {
+		if !seen[id] {
+			delete(c.schedules, id)
+		}
+	}
+	c.lastScheduleFetch = time.Now()
+
+	c.logger.Debug("updated schedules", zap.Int("schedule_count", len(schedules)))
+	return nil
+}
+
+// detectMissedRuns compares the expected fire times whose grace period has
+// elapsed with the runs that were actually created
+func (c *Collector) detectMissedRuns(runs []k6client.TestRun) {
+	grace := c.config.ScheduleMissedRunGrace
+	now := c.now()
+
+	runsByTest := make(map[int][]time.Time)
+	for _, run := range runs {
+		runsByTest[run.TestID] = append(runsByTest[run.TestID], run.Created)
+	}
+
+	c.schedulesMutex.Lock()
+	defer c.schedulesMutex.Unlock()
+
+	for _, tracked := range c.schedules {
+		// Runs of unmonitored projects are never fetched, so they can't be matched
+		if !c.monitorsTest(tracked.Schedule.LoadTestID) {
+			tracked.Pending = nil
+			continue
+		}
+
+		remaining := tracked.Pending[:0]
+		for _, expected := range tracked.Pending {
+			if now.Before(expected.Add(grace)) {
+				remaining = append(remaining, expected)
+				continue
+			}
+
+			if expected.After(tracked.Evaluated) {
+				tracked.Evaluated = expected
+			}
+			if !hasRunNear(runsByTest[tracked.Schedule.LoadTestID], expected, grace) {
+				tracked.MissedRuns++
+				c.logger.Warn("scheduled test run did not start",
+					zap.Int("schedule_id", tracked.Schedule.ID),
+					zap.Int("test_id", tracked.Schedule.LoadTestID),
+					zap.Time("expected", expected),
+					zap.Duration("grace", grace),
+				)
+			}
+		}
+		tracked.Pending = remaining
+	}
+}
+
+// collectScheduleMetrics sends the schedule metrics for monitored tests
+func (c *Collector) collectScheduleMetrics(ch chan<- prometheus.Metric) {
+	c.schedulesMutex.RLock()
+	defer c.schedulesMutex.RUnlock()
+
+	for _, tracked := range c.schedules {
+		schedule := tracked.Schedule
+
+		if !c.monitorsTest(schedule.LoadTestID) {
+			continue
+		}
+		projectID, _ := c.getTestProjectID(schedule.LoadTestID)
+
+		testName := c.getTestName(schedule.LoadTestID)
+		if testName == "" {
+			testName = fmt.Sprintf("test_%d", schedule.LoadTestID)
+		}
+		scheduleID := strconv.Itoa(schedule.ID)
+		testID := strconv.Itoa(schedule.LoadTestID)
+		project := strconv.Itoa(projectID)
+
+		ch <- prometheus.MustNewConstMetric(
+			scheduleInfoDesc,
+			prometheus.GaugeValue,
+			1,
+			scheduleID,
+			testName,
+			testID,
+			project,
+			schedule.Recurrence(),
+			strconv.FormatBool(schedule.Deactivated),
+		)
+
+		if schedule.NextRun != nil && !schedule.Deactivated {
+			ch <- prometheus.MustNewConstMetric(
+				scheduleNextRunTimestampDesc,
+				prometheus.GaugeValue,
+				float64(schedule.NextRun.Unix()),
+				scheduleID,
+				testName,
+				testID,
+				project,
+			)
+		}
+
+		ch <- prometheus.MustNewConstMetric(
+			scheduleMissedRunsTotalDesc,
+			prometheus.CounterValue,
+			float64(tracked.MissedRuns),
+			scheduleID,
+			testName,
+			testID,
+			project,
+		)
+	}
+}
+
+// getTestProjectID returns the project of a cached test
+func (c *Collector) getTestProjectID(testID int) (int, bool) {
+	c.testCacheMutex.RLock()
+	defer c.testCacheMutex.RUnlock()
+
+	if test, exists := c.testCache[testID]; exists {
+		return test.ProjectID, true
+	}
+	return 0, false
+}
+
+// monitorsTest returns true if the test belongs to a monitored project. Tests
+// that are not cached are only monitored when no project filter is configured.
+func (c *Collector) monitorsTest(testID int) bool {
+	projectID, known := c.getTestProjectID(testID)
+	if !known {
+		return len(c.config.Projects) == 0
+	}
+	return c.config.ShouldMonitorProject(strconv.Itoa(projectID))
+}
+
+// hasRunNear returns true if any of the creation times lies within grace of the expected time
+func hasRunNear(created []time.Time, expected time.Time, grace time.Duration) bool {
+	for _, t := range created {
+		if !t.Before(expected.Add(-grace)) && !t.After(expected.Add(grace)) {
+			return true
+		}
+	}
+	return false
+}
+
+// containsTime returns true if the slice contains the given time
+func containsTime(times []time.Time, t time.Time) bool {
+	for _, existing := range times {
+		if existing.Equal(t) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,107 @@
+package collector
+
+import (
+	"strconv"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"go.uber.org/zap"
+
+	"github.com/grafana-cloud-k6-prometheus-exporter/internal/state"
+)
+
+// stuckReason returns the reason reported for runs that exceed the limit of a status
+func stuckReason(status string) string {
+	return status + "_timeout"
+}
+
+// checkStuckRun sends the time in status of an active run and flags it if it
+// exceeds the configured limit. It returns the stuck reason, or "" if the run is not stuck.
+func (c *Collector) checkStuckRun(ch chan<- prometheus.Metric, runState *state.TestRunState, now time.Time) string {
+	timeInStatus := runState.TimeInStatus(now)
+	testID := strconv.Itoa(runState.TestID)
+	projectID := strconv.Itoa(runState.ProjectID)
+	runID := strconv.Itoa(runState.TestRunID)
+
+	ch <- prometheus.MustNewConstMetric(
+		testRunStatusDurationSecondsDesc,
+		prometheus.GaugeValue,
+		timeInStatus.Seconds(),
+		runState.TestName,
+		testID,
+		projectID,
+		runID,
+		runState.CurrentStatus,
+	)
+
+	limit, ok := c.config.GetStuckRunTimeout(runState.ProjectID, runState.TestID, runState.CurrentStatus)
+	if !ok || timeInStatus <= limit {
+		return ""
+	}
+
+	reason := stuckReason(runState.CurrentStatus)
+	ch <- prometheus.MustNewConstMetric(
+		testRunStuckDesc,
+		prometheus.GaugeValue,
+		1,
+		runState.TestName,
+		testID,
+		projectID,
+		runID,
+		runState.CurrentStatus,
+		reason,
+	)
+
+	c.logger.Debug("test run is stuck",
+		zap.Int("run_id", runState.TestRunID),
+		zap.String("status", runState.CurrentStatus),
+		zap.Duration("time_in_status", timeInStatus),
+		zap.Duration("limit", limit),
+	)
+	return reason
+}
+
+// collectStuckCounts sends the number of stuck runs per reason, including
+// zero counts for every status with a configured limit
+func (c *Collector) collectStuckCounts(ch chan<- prometheus.Metric, counts map[string]int) {
+	for _, status := range c.config.StuckRunStatuses() {
+		reason := stuckReason(status)
+		if _, exists := counts[reason]; !exists {
+			counts[reason] = 0
+		}
+	}
+
+	for reason, count := range counts {
+		ch <- prometheus.MustNewConstMetric(
+			testRunsStuckDesc,
+			prometheus.GaugeValue,
+			float64(count),
+			reason,
+		)
+	}
+}
+
+// detectStuckRuns reports every tracked run that exceeds the limit of its current status
+// to the state manager, which emits a run_stuck event once per run and status
+func (c *Collector) detectStuckRuns(now time.Time) {
+	for _, runState := range c.stateManager.GetAllStates() {
+		// Runs of other stacks are checked by their own collector
+		if runState.Stack != c.config.StackName {
+			continue
+		}
+
+		limit, ok := c.config.GetStuckRunTimeout(runState.ProjectID, runState.TestID, runState.CurrentStatus)
+		if !ok || runState.TimeInStatus(now) <= limit {
+			continue
+		}
+
+		reason := stuckReason(runState.CurrentStatus)
+		if c.stateManager.MarkRunStuck(runState.Stack, runState.TestRunID, reason) {
+			c.logger.Info("test run is stuck",
+				zap.Int("run_id", runState.TestRunID),
+				zap.String("status", runState.CurrentStatus),
+				zap.Duration("limit", limit),
+			)
+		}
+	}
+}
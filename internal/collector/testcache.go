@@ -0,0 +1,192 @@
+package collector
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"strconv"
+	"time"
+
+	"go.uber.org/zap"
+
+	"github.com/grafana-cloud-k6-prometheus-exporter/internal/k6client"
+)
+
+// updateTestCache refreshes the cached tests of the monitored projects, or of all
+// projects without a project filter
+func (c *Collector) updateTestCache(ctx context.Context) error {
+	var projectIDs []int
+	if len(c.config.Projects) > 0 {
+		projectIDs = make([]int, 0, len(c.config.Projects))
+		for _, project := range c.config.Projects {
+			projectID, err := strconv.Atoi(project)
+			if err != nil {
+				c.logger.Warn("invalid project ID, skipping", zap.String("project_id", project))
+				continue
+			}
+			projectIDs = append(projectIDs, projectID)
+		}
+	}
+
+	if err := c.refreshTests(ctx, projectIDs); err != nil {
+		return err
+	}
+
+	c.testCacheMutex.Lock()
+	c.lastTestFetch = time.Now()
+	// Tests missed by an on-demand refresh get another chance
+	c.missingTests = make(map[int]bool)
+	c.testCacheMutex.Unlock()
+	return nil
+}
+
+// refreshUnknownTests refreshes the tests of the projects with runs of tests that are
+// not cached, so new tests are named before the next full refresh. Tests the API does
+// not return are not looked up again until then.
+func (c *Collector) refreshUnknownTests(ctx context.Context, runs []k6client.TestRun) error {
+	projects := make(map[int]bool)
+	var unknown []int
+
+	c.testCacheMutex.RLock()
+	for _, run := range runs {
+		if _, exists := c.testCache[run.TestID]; exists || c.missingTests[run.TestID] {
+			continue
+		}
+		if !c.config.ShouldMonitorProject(strconv.Itoa(run.ProjectID)) {
+			continue
+		}
+		projects[run.ProjectID] = true
+		unknown = append(unknown, run.TestID)
+	}
+	c.testCacheMutex.RUnlock()
+
+	if len(unknown) == 0 {
+		return nil
+	}
+
+	projectIDs := make([]int, 0, len(projects))
+	for projectID := range projects {
+		projectIDs = append(projectIDs, projectID)
+	}
+	sort.Ints(projectIDs)
+
+	c.logger.Debug("refreshing tests of runs with unknown tests",
+		zap.Ints("test_ids", unknown),
+		zap.Ints("project_ids", projectIDs),
+	)
+	err := c.refreshTests(ctx, projectIDs)
+
+	c.testCacheMutex.Lock()
+	for _, testID := range unknown {
+		if _, exists := c.testCache[testID]; !exists {
+			c.missingTests[testID] = true
+		}
+	}
+	c.testCacheMutex.Unlock()
+
+	return err
+}
+
+// refreshTests lists the tests of the projects, or of all projects if projectIDs is
+// nil, and applies them to the cache. Projects that fail to list keep their cached tests.
+func (c *Collector) refreshTests(ctx context.Context, projectIDs []int) error {
+	if projectIDs == nil {
+		tests, err := c.client.ListTests(ctx, nil)
+		if err != nil {
+			return fmt.Errorf("list tests: %w", err)
+		}
+		c.applyTests(tests, func(int) bool { return true })
+		return nil
+	}
+
+	var tests []k6client.Test
+	var errs []error
+	listed := make(map[int]bool, len(projectIDs))
+	for _, projectID := range projectIDs {
+		projectID := projectID
+		projectTests, err := c.client.ListTests(ctx, &projectID)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("list tests of project %d: %w", projectID, err))
+			continue
+		}
+		listed[projectID] = true
+		tests = append(tests, projectTests...)
+	}
+
+	c.applyTests(tests, func(projectID int) bool { return listed[projectID] })
+	return errors.Join(errs...)
+}
+
+// applyTests adds new tests to the cache and replaces tests whose Updated time
+// changed. Cached tests of listed projects that are missing from tests were
+// deleted, they are removed along with their series.
+func (c *Collector) applyTests(tests []k6client.Test, listed func(projectID int) bool) {
+	seen := make(map[int]bool, len(tests))
+	var added, updated int
+	var deleted []int
+
+	c.testCacheMutex.Lock()
+	for i := range tests {
+		test := tests[i]
+		seen[test.ID] = true
+
+		cached, exists := c.testCache[test.ID]
+		switch {
+		case !exists:
+			added++
+		case test.Updated.IsZero() || !test.Updated.Equal(cached.Updated):
+			// Renames and other changes bump Updated, tests without it are always replaced
+			updated++
+		default:
+			continue
+		}
+		c.testCache[test.ID] = &test
+		delete(c.missingTests, test.ID)
+	}
+
+	for testID, test := range c.testCache {
+		if !seen[testID] && listed(test.ProjectID) {
+			delete(c.testCache, testID)
+			deleted = append(deleted, testID)
+		}
+	}
+	count := len(c.testCache)
+	c.testCacheMutex.Unlock()
+
+	c.dropTests(deleted)
+
+	c.logger.Info("updated test cache",
+		zap.Int("test_count", count),
+		zap.Int("added", added),
+		zap.Int("updated", updated),
+		zap.Int("deleted", len(deleted)),
+	)
+}
+
+// dropTests removes the run statistics and load zone totals of deleted tests
+func (c *Collector) dropTests(testIDs []int) {
+	if len(testIDs) == 0 {
+		return
+	}
+
+	deleted := make(map[int]bool, len(testIDs))
+	for _, testID := range testIDs {
+		deleted[testID] = true
+	}
+
+	c.testStatsMutex.Lock()
+	for testID := range deleted {
+		delete(c.testStats, testID)
+		delete(c.seededTests, testID)
+	}
+	c.testStatsMutex.Unlock()
+
+	c.loadZonesMutex.Lock()
+	for key := range c.loadZones {
+		if deleted[key.TestID] {
+			delete(c.loadZones, key)
+		}
+	}
+	c.loadZonesMutex.Unlock()
+}
@@ -0,0 +1,267 @@
+package collector
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"strconv"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+
+	"github.com/grafana-cloud-k6-prometheus-exporter/internal/k6client"
+)
+
+// testStats tracks the most recent run activity for a single test
+type testStats struct {
+	TestID              int
+	ProjectID           int
+	LastRunStart        time.Time
+	LastRunEnd          time.Time
+	LastResult          string
+	ConsecutiveFailures int
+}
+
+// updateTestStats folds the given test runs into the per-test statistics.
+// Stats survive across scrapes so that a test keeps its last run timestamps
+// after its runs have left the fetch window.
+func (c *Collector) updateTestStats(runs []k6client.TestRun) {
+	// Process finished runs in the order they ended so failure streaks are counted correctly
+	sorted := make([]k6client.TestRun, len(runs))
+	copy(sorted, runs)
+	now := c.now()
+	sort.SliceStable(sorted, func(i, j int) bool {
+		return endedOrNow(sorted[i], now).Before(endedOrNow(sorted[j], now))
+	})
+
+	c.testStatsMutex.Lock()
+	defer c.testStatsMutex.Unlock()
+
+	for _, run := range sorted {
+		stats, exists := c.testStats[run.TestID]
+		if !exists {
+			stats = &testStats{TestID: run.TestID}
+			c.testStats[run.TestID] = stats
+		}
+		stats.ProjectID = run.ProjectID
+
+		if run.Created.After(stats.LastRunStart) {
+			stats.LastRunStart = run.Created
+		}
+
+		// Only count each finished run once, runs that ended before the last
+		// recorded end have already been accounted for
+		if !k6client.IsTerminalStatus(run.Status) || run.Ended == nil || !run.Ended.After(stats.LastRunEnd) {
+			continue
+		}
+
+		stats.LastRunEnd = *run.Ended
+		stats.LastResult = run.GetResult()
+
+		switch stats.LastResult {
+		case k6client.ResultFailed:
+			stats.ConsecutiveFailures++
+		case k6client.ResultPassed:
+			stats.ConsecutiveFailures = 0
+		}
+	}
+}
+
+// seedTestStats looks up the latest run of the cached tests without run statistics,
+// so tests that last ran before the fetch window report their last run after a
+// restart. Each test is looked up once, later runs are seen in the fetch window.
+func (c *Collector) seedTestStats(ctx context.Context) error {
+	var tests []k6client.Test
+	c.testCacheMutex.RLock()
+	c.testStatsMutex.RLock()
+	for _, test := range c.testCache {
+		if _, exists := c.testStats[test.ID]; exists || c.seededTests[test.ID] {
+			continue
+		}
+		if !c.config.ShouldMonitorProject(strconv.Itoa(test.ProjectID)) {
+			continue
+		}
+		tests = append(tests, *test)
+	}
+	c.testStatsMutex.RUnlock()
+	c.testCacheMutex.RUnlock()
+
+	sort.Slice(tests, func(i, j int) bool {
+		return tests[i].ID < tests[j].ID
+	})
+
+	var errs []error
+	for _, test := range tests {
+		run, err := c.client.GetLatestTestRun(ctx, test.ID)
+		if err != nil {
+			errs = append(errs, err)
+			if errors.Is(err, k6client.ErrCircuitOpen) {
+				break
+			}
+			continue
+		}
+
+		c.testStatsMutex.Lock()
+		c.seededTests[test.ID] = true
+		c.testStatsMutex.Unlock()
+
+		if run == nil {
+			continue
+		}
+		if run.ProjectID == 0 {
+			run.ProjectID = test.ProjectID
+		}
+		c.updateTestStats([]k6client.TestRun{*run})
+	}
+	return errors.Join(errs...)
+}
+
+// collectTestMetrics sends the test info and last run metrics
+func (c *Collector) collectTestMetrics(ch chan<- prometheus.Metric) {
+	c.testCacheMutex.RLock()
+	for _, test := range c.testCache {
+		projectID := strconv.Itoa(test.ProjectID)
+		if !c.config.ShouldMonitorProject(projectID) {
+			continue
+		}
+
+		testID := strconv.Itoa(test.ID)
+		baselineRunID := ""
+		if test.BaselineTestRunID != nil {
+			baselineRunID = strconv.Itoa(*test.BaselineTestRunID)
+		}
+
+		ch <- prometheus.MustNewConstMetric(
+			testInfoDesc,
+			prometheus.GaugeValue,
+			1,
+			test.Name,
+			testID,
+			projectID,
+			baselineRunID,
+		)
+
+		if !test.Created.IsZero() {
+			ch <- prometheus.MustNewConstMetric(
+				testCreatedTimestampDesc,
+				prometheus.GaugeValue,
+				float64(test.Created.Unix()),
+				test.Name,
+				testID,
+				projectID,
+			)
+		}
+
+		if !test.Updated.IsZero() {
+			ch <- prometheus.MustNewConstMetric(
+				testUpdatedTimestampDesc,
+				prometheus.GaugeValue,
+				float64(test.Updated.Unix()),
+				test.Name,
+				testID,
+				projectID,
+			)
+		}
+	}
+	c.testCacheMutex.RUnlock()
+
+	c.testStatsMutex.RLock()
+	defer c.testStatsMutex.RUnlock()
+
+	for _, stats := range c.testStats {
+		testName := c.getTestName(stats.TestID)
+		if testName == "" {
+			testName = fmt.Sprintf("test_%d", stats.TestID)
+		}
+		testID := strconv.Itoa(stats.TestID)
+		projectID := strconv.Itoa(stats.ProjectID)
+
+		ch <- prometheus.MustNewConstMetric(
+			testLastRunStartTimestampDesc,
+			prometheus.GaugeValue,
+			float64(stats.LastRunStart.Unix()),
+			testName,
+			testID,
+			projectID,
+		)
+
+		if stats.LastRunEnd.IsZero() {
+			continue
+		}
+
+		ch <- prometheus.MustNewConstMetric(
+			testLastRunEndTimestampDesc,
+			prometheus.GaugeValue,
+			float64(stats.LastRunEnd.Unix()),
+			testName,
+			testID,
+			projectID,
+		)
+
+		ch <- prometheus.MustNewConstMetric(
+			testLastRunResultDesc,
+			prometheus.GaugeValue,
+			1,
+			testName,
+			testID,
+			projectID,
+			stats.LastResult,
+		)
+
+		ch <- prometheus.MustNewConstMetric(
+			testConsecutiveFailuresDesc,
+			prometheus.GaugeValue,
+			float64(stats.ConsecutiveFailures),
+			testName,
+			testID,
+			projectID,
+		)
+	}
+}
+
+// endedOrNow returns the end time of a run, or now if it is still active
+func endedOrNow(run k6client.TestRun, now time.Time) time.Time {
+	if run.Ended == nil {
+		return now
+	}
+	return *run.Ended
+}
+
+// TestSummary describes a cached test and its last run activity
+type TestSummary struct {
+	Test                k6client.Test
+	LastRunStart        time.Time
+	LastRunEnd          time.Time
+	LastResult          string
+	ConsecutiveFailures int
+}
+
+// Tests returns the cached tests of the monitored projects with their last run activity, sorted by ID
+func (c *Collector) Tests() []TestSummary {
+	c.testCacheMutex.RLock()
+	c.testStatsMutex.RLock()
+	defer c.testCacheMutex.RUnlock()
+	defer c.testStatsMutex.RUnlock()
+
+	summaries := make([]TestSummary, 0, len(c.testCache))
+	for _, test := range c.testCache {
+		if !c.config.ShouldMonitorProject(strconv.Itoa(test.ProjectID)) {
+			continue
+		}
+
+		summary := TestSummary{Test: *test}
+		if stats, exists := c.testStats[test.ID]; exists {
+			summary.LastRunStart = stats.LastRunStart
+			summary.LastRunEnd = stats.LastRunEnd
+			summary.LastResult = stats.LastResult
+			summary.ConsecutiveFailures = stats.ConsecutiveFailures
+		}
+		summaries = append(summaries, summary)
+	}
+
+	sort.Slice(summaries, func(i, j int) bool {
+		return summaries[i].Test.ID < summaries[j].Test.ID
+	})
+	return summaries
+}
@@ -0,0 +1,50 @@
+package collector
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+
+	"github.com/grafana-cloud-k6-prometheus-exporter/internal/k6client"
+)
+
+// userKey identifies the runs of a starter
+type userKey struct {
+	StartedBy string
+	Team      string
+}
+
+// userTotals holds the usage of a starter within the fetch window
+type userTotals struct {
+	Runs float64
+	VUH  float64
+}
+
+// collectUserMetrics sends the runs started and VUH consumed per starter within the fetch window
+func (c *Collector) collectUserMetrics(ch chan<- prometheus.Metric, runs []k6client.TestRun) {
+	totals := make(map[userKey]*userTotals)
+	for _, run := range runs {
+		key := userKey{
+			StartedBy: c.starterLabel(run.StartedBy),
+			Team:      c.config.GetStarterTeam(run.StartedBy),
+		}
+		t, exists := totals[key]
+		if !exists {
+			t = &userTotals{}
+			totals[key] = t
+		}
+		t.Runs++
+		t.VUH += run.GetVUH()
+	}
+
+	for key, t := range totals {
+		ch <- prometheus.MustNewConstMetric(userTestRunsStartedDesc, prometheus.GaugeValue, t.Runs, key.StartedBy, key.Team)
+		ch <- prometheus.MustNewConstMetric(userVUHConsumedDesc, prometheus.GaugeValue, t.VUH, key.StartedBy, key.Team)
+	}
+}
+
+// starterLabel returns the started_by label value according to the configured privacy mode
+func (c *Collector) starterLabel(startedBy string) string {
+	if startedBy == "" {
+		return "unknown"
+	}
+	return c.config.StarterName(startedBy)
+}
@@ -0,0 +1,103 @@
+package collector
+
+import (
+	"fmt"
+	"strconv"
+
+	"github.com/prometheus/client_golang/prometheus"
+
+	"github.com/grafana-cloud-k6-prometheus-exporter/internal/k6client"
+)
+
+// loadZoneKey identifies the accumulated load zone usage of a test
+type loadZoneKey struct {
+	TestID    int
+	ProjectID int
+	LoadZone  string
+}
+
+// loadZoneTotals holds the load zone usage accumulated from finished runs
+type loadZoneTotals struct {
+	Runs float64
+	VUs  float64
+	VUH  float64
+}
+
+// collectLoadZoneMetrics sends live per-zone metrics for an active run
+func (c *Collector) collectLoadZoneMetrics(ch chan<- prometheus.Metric, run *k6client.TestRun, testName string) {
+	for zone, vus := range run.GetLoadZoneVUs() {
+		ch <- prometheus.MustNewConstMetric(
+			testRunLoadZoneVUsDesc,
+			prometheus.GaugeValue,
+			vus,
+			testName,
+			strconv.Itoa(run.TestID),
+			strconv.Itoa(run.ProjectID),
+			strconv.Itoa(run.ID),
+			zone,
+		)
+	}
+
+	if run.Cost == nil {
+		return
+	}
+	for zone, vuh := range run.Cost.VUHBreakdown {
+		ch <- prometheus.MustNewConstMetric(
+			testRunLoadZoneVUHDesc,
+			prometheus.GaugeValue,
+			vuh,
+			testName,
+			strconv.Itoa(run.TestID),
+			strconv.Itoa(run.ProjectID),
+			strconv.Itoa(run.ID),
+			zone,
+		)
+	}
+}
+
+// recordFinishedLoadZones adds the load zone usage of a finished run to the totals
+func (c *Collector) recordFinishedLoadZones(run *k6client.TestRun) {
+	c.loadZonesMutex.Lock()
+	defer c.loadZonesMutex.Unlock()
+
+	totals := func(zone string) *loadZoneTotals {
+		key := loadZoneKey{TestID: run.TestID, ProjectID: run.ProjectID, LoadZone: zone}
+		t, exists := c.loadZones[key]
+		if !exists {
+			t = &loadZoneTotals{}
+			c.loadZones[key] = t
+		}
+		return t
+	}
+
+	for zone, vus := range run.GetLoadZoneVUs() {
+		t := totals(zone)
+		t.Runs++
+		t.VUs += vus
+	}
+
+	if run.Cost == nil {
+		return
+	}
+	for zone, vuh := range run.Cost.VUHBreakdown {
+		totals(zone).VUH += vuh
+	}
+}
+
+// collectLoadZoneTotals sends the load zone usage accumulated from finished runs
+func (c *Collector) collectLoadZoneTotals(ch chan<- prometheus.Metric) {
+	c.loadZonesMutex.RLock()
+	defer c.loadZonesMutex.RUnlock()
+
+	for key, totals := range c.loadZones {
+		testName := c.getTestName(key.TestID)
+		if testName == "" {
+			testName = fmt.Sprintf("test_%d", key.TestID)
+		}
+		labels := []string{testName, strconv.Itoa(key.TestID), strconv.Itoa(key.ProjectID), key.LoadZone}
+
+		ch <- prometheus.MustNewConstMetric(loadZoneRunsTotalDesc, prometheus.CounterValue, totals.Runs, labels...)
+		ch <- prometheus.MustNewConstMetric(loadZoneVUsTotalDesc, prometheus.CounterValue, totals.VUs, labels...)
+		ch <- prometheus.MustNewConstMetric(loadZoneVUHTotalDesc, prometheus.CounterValue, totals.VUH, labels...)
+	}
+}
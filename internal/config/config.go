@@ -1,7 +1,12 @@
 package config
 
 import (
+	"crypto/sha256"
+	"encoding/hex"
 	"fmt"
+	"net/url"
+	"sort"
+	"strconv"
 	"strings"
 	"time"
 
@@ -10,19 +15,104 @@ import (
 
 // Config holds the application configuration
 type Config struct {
-	// K6 API configuration
-	K6APIToken     string   `envconfig:"K6_API_TOKEN" required:"true"`
+	// K6 API configuration of a single stack, required unless STACKS is set
+	K6APIToken     string   `envconfig:"K6_API_TOKEN"`
+	K6APITokenFile string   `envconfig:"K6_API_TOKEN_FILE"` // File with the token, reread when it changes
 	K6APIURL       string   `envconfig:"K6_API_URL" default:"https://api.k6.io"`
-	GrafanaStackID string   `envconfig:"GRAFANA_STACK_ID" required:"true"`
-	Projects       []string `envconfig:"PROJECTS" required:"true"` // Comma-separated list of project IDs to monitor
+	GrafanaStackID string   `envconfig:"GRAFANA_STACK_ID"`
+	Projects       []string `envconfig:"PROJECTS"` // Comma-separated list of project IDs to monitor
+
+	// Multiple stacks, each configured with STACK_<NAME>_* variables
+	Stacks       []string      `envconfig:"STACKS"` // Comma-separated list of stack names
+	StackConfigs []StackConfig `ignored:"true"`     // Loaded from the variables of each stack
+	StackName    string        `ignored:"true"`     // Stack the configuration applies to, see ForStack
 
 	// Server configuration
-	Port int `envconfig:"PORT" default:"9090"`
+	Port          int    `envconfig:"PORT" default:"9090"`
+	WebConfigFile string `envconfig:"WEB_CONFIG_FILE"` // TLS and basic auth configuration, in the exporter-toolkit format
 
 	// Operational configuration
 	TestCacheTTL         time.Duration `envconfig:"TEST_CACHE_TTL" default:"60s"`
 	StateCleanupInterval time.Duration `envconfig:"STATE_CLEANUP_INTERVAL" default:"5m"`
 	ScrapeInterval       time.Duration `envconfig:"SCRAPE_INTERVAL" default:"15s"`
+	ReadyMaxStaleness    time.Duration `envconfig:"READY_MAX_STALENESS" default:"5m"` // /ready fails once the last successful fetch is older
+
+	// Event stream configuration
+	EventsBufferSize        int           `envconfig:"EVENTS_BUFFER_SIZE" default:"1000"` // Events kept for Last-Event-ID resume
+	EventsHeartbeatInterval time.Duration `envconfig:"EVENTS_HEARTBEAT_INTERVAL" default:"15s"`
+
+	// Notifications configuration file with the webhook receivers
+	NotificationsConfigFile string `envconfig:"NOTIFICATIONS_CONFIG_FILE"`
+
+	// Schedule configuration
+	ScheduleMissedRunGrace time.Duration `envconfig:"SCHEDULE_MISSED_RUN_GRACE" default:"10m"` // How long after its expected fire time a scheduled run may start
+
+	// Per-user configuration
+	StartedByMode     string            `envconfig:"STARTED_BY_MODE" default:"plain"` // How run starters are exported: plain, hash or redact
+	StartedByHashSalt string            `envconfig:"STARTED_BY_HASH_SALT"`
+	StarterTeams      map[string]string `envconfig:"STARTER_TEAMS"` // Comma-separated list of email:team pairs
+
+	// Stuck run detection, keyed by <status>, project/<id>/<status> or test/<id>/<status>
+	// merged over the defaults initializing:5m,running:1h. A timeout of 0 disables a limit.
+	StuckRunTimeouts map[string]string `envconfig:"STUCK_RUN_TIMEOUTS"`
+
+	// Guard configuration, aborts runaway test runs when enabled
+	GuardEnabled           bool          `envconfig:"GUARD_ENABLED" default:"false"`
+	GuardAPIToken          string        `envconfig:"GUARD_API_TOKEN"` // Token with permission to abort test runs
+	GuardDryRun            bool          `envconfig:"GUARD_DRY_RUN" default:"true"`
+	GuardMaxDuration       time.Duration `envconfig:"GUARD_MAX_DURATION"`
+	GuardMaxVUH            float64       `envconfig:"GUARD_MAX_VUH"`
+	GuardAllowlistProjects []string      `envconfig:"GUARD_ALLOWLIST_PROJECTS"` // Projects whose runs are never aborted
+	GuardCheckInterval     time.Duration `envconfig:"GUARD_CHECK_INTERVAL" default:"1m"`
+
+	// Grafana annotations of test run windows, enabled when a URL is set
+	GrafanaAnnotationsURL           string   `envconfig:"GRAFANA_ANNOTATIONS_URL"`
+	GrafanaAnnotationsToken         string   `envconfig:"GRAFANA_ANNOTATIONS_TOKEN"`          // Service account token allowed to write annotations
+	GrafanaAnnotationsDashboardUIDs []string `envconfig:"GRAFANA_ANNOTATIONS_DASHBOARD_UIDS"` // Organization-wide annotations if empty
+
+	// Remote-write push mode, enabled when a URL is set
+	RemoteWriteURL          string            `envconfig:"REMOTE_WRITE_URL"`
+	RemoteWriteInterval     time.Duration     `envconfig:"REMOTE_WRITE_INTERVAL" default:"30s"`
+	RemoteWriteUsername     string            `envconfig:"REMOTE_WRITE_USERNAME"`
+	RemoteWritePassword     string            `envconfig:"REMOTE_WRITE_PASSWORD"`
+	RemoteWriteHeaders      map[string]string `envconfig:"REMOTE_WRITE_HEADERS"` // Comma-separated list of name:value pairs
+	RemoteWriteTimeout      time.Duration     `envconfig:"REMOTE_WRITE_TIMEOUT" default:"30s"`
+	RemoteWriteMaxRetries   int               `envconfig:"REMOTE_WRITE_MAX_RETRIES" default:"3"`
+	RemoteWriteRetryBackoff time.Duration     `envconfig:"REMOTE_WRITE_RETRY_BACKOFF" default:"1s"`
+	RemoteWriteQueueSize    int               `envconfig:"REMOTE_WRITE_QUEUE_SIZE" default:"10"` // Pending writes kept in memory, the oldest is dropped when full
+
+	// OTLP/HTTP metrics export, enabled when an endpoint is set
+	OTLPEndpoint string            `envconfig:"OTLP_ENDPOINT"` // Metrics URL, /v1/metrics is appended if it has no path
+	OTLPProtocol string            `envconfig:"OTLP_PROTOCOL" default:"http/protobuf"`
+	OTLPHeaders  map[string]string `envconfig:"OTLP_HEADERS"` // Comma-separated list of name:value pairs
+	OTLPInterval time.Duration     `envconfig:"OTLP_INTERVAL" default:"30s"`
+	OTLPTimeout  time.Duration     `envconfig:"OTLP_TIMEOUT" default:"10s"`
+
+	// HTTP transport to the k6 API, requests time out after API_TIMEOUT
+	K6APIProxyURL       string        `envconfig:"K6_API_PROXY_URL"` // HTTPS_PROXY and NO_PROXY are used if empty
+	K6APINoProxy        []string      `envconfig:"K6_API_NO_PROXY"`  // Hosts and domains reached without a proxy, in addition to NO_PROXY
+	K6APICAFile         string        `envconfig:"K6_API_CA_FILE"`   // PEM certificates trusted in addition to the system roots
+	K6APIClientCertFile string        `envconfig:"K6_API_CLIENT_CERT_FILE"`
+	K6APIClientKeyFile  string        `envconfig:"K6_API_CLIENT_KEY_FILE"`
+	K6APIMaxIdleConns   int           `envconfig:"K6_API_MAX_IDLE_CONNS" default:"10"`
+	K6APIKeepAlive      time.Duration `envconfig:"K6_API_KEEPALIVE" default:"30s"` // TCP keep-alive period, 0 disables keep-alive probes
+
+	// Client-side rate limit of the k6 API requests per stack, 0 only honors the rate limit headers
+	K6APIRateLimit      float64 `envconfig:"K6_API_RATE_LIMIT"` // Requests per second
+	K6APIRateLimitBurst int     `envconfig:"K6_API_RATE_LIMIT_BURST" default:"20"`
+
+	// Circuit breaker around the k6 API per stack, disabled if both thresholds are 0
+	K6APIBreakerFailures    int           `envconfig:"K6_API_BREAKER_FAILURES"`            // Consecutive failures that open the circuit
+	K6APIBreakerErrorRatio  float64       `envconfig:"K6_API_BREAKER_ERROR_RATIO"`         // Ratio of failed requests within the window that opens the circuit
+	K6APIBreakerWindow      int           `envconfig:"K6_API_BREAKER_WINDOW" default:"20"` // Recent requests the error ratio is computed over
+	K6APIBreakerOpenTimeout time.Duration `envconfig:"K6_API_BREAKER_OPEN_TIMEOUT" default:"30s"`
+
+	// Entries of the k6 API response cache and of the finished run cache per stack, 0 disables caching
+	K6APICacheSize int `envconfig:"K6_API_CACHE_SIZE"`
+
+	// Record and replay of the k6 API traffic, with a subdirectory per stack when STACKS is set
+	K6APIRecordDir string `envconfig:"K6_API_RECORD_DIR"` // Responses are written to this directory with the tokens redacted
+	K6APIReplayDir string `envconfig:"K6_API_REPLAY_DIR"` // Responses are served from this directory instead of the k6 API, no token is needed
 
 	// Advanced configuration
 	MaxConcurrentRequests int           `envconfig:"MAX_CONCURRENT_REQUESTS" default:"10"`
@@ -31,6 +121,16 @@ type Config struct {
 	RetryDelay            time.Duration `envconfig:"RETRY_DELAY" default:"1s"`
 }
 
+// Stuck run limits that apply unless STUCK_RUN_TIMEOUTS overrides them
+var defaultStuckRunTimeouts = map[string]string{"initializing": "5m", "running": "1h"}
+
+// Supported STARTED_BY_MODE values
+const (
+	StartedByPlain  = "plain"
+	StartedByHash   = "hash"
+	StartedByRedact = "redact"
+)
+
 // Load loads configuration from environment variables
 func Load() (*Config, error) {
 	var cfg Config
@@ -39,6 +139,25 @@ func Load() (*Config, error) {
 		return nil, fmt.Errorf("failed to load config: %w", err)
 	}
 
+	// Overrides keep the default limits of the other statuses
+	timeouts := make(map[string]string, len(defaultStuckRunTimeouts)+len(cfg.StuckRunTimeouts))
+	for key, value := range defaultStuckRunTimeouts {
+		timeouts[key] = value
+	}
+	for key, value := range cfg.StuckRunTimeouts {
+		timeouts[key] = value
+	}
+	cfg.StuckRunTimeouts = timeouts
+
+	if len(cfg.Stacks) > 0 {
+		if cfg.StackConfigs, err = loadStacks(cfg.Stacks); err != nil {
+			return nil, fmt.Errorf("failed to load config: %w", err)
+		}
+	} else if err := envconfig.Process("", &singleStack{}); err != nil {
+		// The single stack variables are only required without STACKS
+		return nil, fmt.Errorf("failed to load config: %w", err)
+	}
+
 	// Validate configuration
 	if err := cfg.Validate(); err != nil {
 		return nil, fmt.Errorf("invalid configuration: %w", err)
@@ -49,16 +168,26 @@ func Load() (*Config, error) {
 
 // Validate validates the configuration
 func (c *Config) Validate() error {
-	if c.K6APIToken == "" {
-		return fmt.Errorf("K6_API_TOKEN is required")
-	}
+	if len(c.StackConfigs) > 0 {
+		if err := c.validateStacks(); err != nil {
+			return err
+		}
+	} else {
+		if c.K6APIToken == "" && c.K6APITokenFile == "" && c.K6APIReplayDir == "" {
+			return fmt.Errorf("K6_API_TOKEN is required unless K6_API_TOKEN_FILE or K6_API_REPLAY_DIR is set")
+		}
 
-	if c.GrafanaStackID == "" {
-		return fmt.Errorf("GRAFANA_STACK_ID is required")
-	}
+		if c.K6APIToken != "" && c.K6APITokenFile != "" {
+			return fmt.Errorf("K6_API_TOKEN and K6_API_TOKEN_FILE cannot both be set")
+		}
+
+		if c.GrafanaStackID == "" {
+			return fmt.Errorf("GRAFANA_STACK_ID is required")
+		}
 
-	if !strings.HasPrefix(c.K6APIURL, "http://") && !strings.HasPrefix(c.K6APIURL, "https://") {
-		return fmt.Errorf("K6_API_URL must start with http:// or https://")
+		if !strings.HasPrefix(c.K6APIURL, "http://") && !strings.HasPrefix(c.K6APIURL, "https://") {
+			return fmt.Errorf("K6_API_URL must start with http:// or https://")
+		}
 	}
 
 	if c.Port < 1 || c.Port > 65535 {
@@ -73,6 +202,139 @@ func (c *Config) Validate() error {
 		return fmt.Errorf("STATE_CLEANUP_INTERVAL must be at least 1 minute")
 	}
 
+	if c.ReadyMaxStaleness < 0 {
+		return fmt.Errorf("READY_MAX_STALENESS must not be negative")
+	}
+
+	if c.ReadyMaxStaleness > 0 && c.ScrapeInterval > 0 && c.ReadyMaxStaleness < c.ScrapeInterval {
+		return fmt.Errorf("READY_MAX_STALENESS must be at least SCRAPE_INTERVAL")
+	}
+
+	if c.EventsBufferSize < 0 {
+		return fmt.Errorf("EVENTS_BUFFER_SIZE must not be negative")
+	}
+
+	if c.ScheduleMissedRunGrace < 0 || c.ScheduleMissedRunGrace > 12*time.Hour {
+		return fmt.Errorf("SCHEDULE_MISSED_RUN_GRACE must be between 0 and 12 hours")
+	}
+
+	switch c.StartedByMode {
+	case "", StartedByPlain, StartedByHash, StartedByRedact:
+	default:
+		return fmt.Errorf("STARTED_BY_MODE must be one of plain, hash or redact")
+	}
+
+	for key, value := range c.StuckRunTimeouts {
+		if _, err := parseStuckRunTimeoutKey(key); err != nil {
+			return fmt.Errorf("STUCK_RUN_TIMEOUTS: %w", err)
+		}
+		if d, err := time.ParseDuration(value); err != nil || d < 0 {
+			return fmt.Errorf("STUCK_RUN_TIMEOUTS: invalid timeout %q for %q", value, key)
+		}
+	}
+
+	if c.GuardEnabled {
+		if c.GuardAPIToken == "" && len(c.StackConfigs) == 0 {
+			return fmt.Errorf("GUARD_API_TOKEN is required when GUARD_ENABLED is set")
+		}
+		if c.GuardMaxDuration <= 0 && c.GuardMaxVUH <= 0 {
+			return fmt.Errorf("GUARD_MAX_DURATION or GUARD_MAX_VUH is required when GUARD_ENABLED is set")
+		}
+		if c.GuardCheckInterval < time.Second {
+			return fmt.Errorf("GUARD_CHECK_INTERVAL must be at least 1 second")
+		}
+	}
+
+	if c.GrafanaAnnotationsURL != "" {
+		if !strings.HasPrefix(c.GrafanaAnnotationsURL, "http://") && !strings.HasPrefix(c.GrafanaAnnotationsURL, "https://") {
+			return fmt.Errorf("GRAFANA_ANNOTATIONS_URL must start with http:// or https://")
+		}
+		if c.GrafanaAnnotationsToken == "" {
+			return fmt.Errorf("GRAFANA_ANNOTATIONS_TOKEN is required when GRAFANA_ANNOTATIONS_URL is set")
+		}
+	}
+
+	if c.RemoteWriteURL != "" {
+		if !strings.HasPrefix(c.RemoteWriteURL, "http://") && !strings.HasPrefix(c.RemoteWriteURL, "https://") {
+			return fmt.Errorf("REMOTE_WRITE_URL must start with http:// or https://")
+		}
+		if c.RemoteWriteInterval < time.Second {
+			return fmt.Errorf("REMOTE_WRITE_INTERVAL must be at least 1 second")
+		}
+		if c.RemoteWriteMaxRetries < 0 {
+			return fmt.Errorf("REMOTE_WRITE_MAX_RETRIES must not be negative")
+		}
+		if c.RemoteWriteQueueSize < 1 {
+			return fmt.Errorf("REMOTE_WRITE_QUEUE_SIZE must be at least 1")
+		}
+	}
+
+	if c.OTLPEndpoint != "" {
+		if !strings.HasPrefix(c.OTLPEndpoint, "http://") && !strings.HasPrefix(c.OTLPEndpoint, "https://") {
+			return fmt.Errorf("OTLP_ENDPOINT must start with http:// or https://")
+		}
+		if c.OTLPProtocol != "http/protobuf" && c.OTLPProtocol != "http/json" {
+			return fmt.Errorf("OTLP_PROTOCOL must be http/protobuf or http/json")
+		}
+		if c.OTLPInterval < time.Second {
+			return fmt.Errorf("OTLP_INTERVAL must be at least 1 second")
+		}
+	}
+
+	if c.K6APIProxyURL != "" {
+		proxyURL, err := url.Parse(c.K6APIProxyURL)
+		if err != nil || proxyURL.Host == "" {
+			return fmt.Errorf("K6_API_PROXY_URL must be a URL with a host")
+		}
+		if proxyURL.Scheme != "http" && proxyURL.Scheme != "https" && proxyURL.Scheme != "socks5" {
+			return fmt.Errorf("K6_API_PROXY_URL must start with http://, https:// or socks5://")
+		}
+	}
+
+	if (c.K6APIClientCertFile == "") != (c.K6APIClientKeyFile == "") {
+		return fmt.Errorf("K6_API_CLIENT_CERT_FILE and K6_API_CLIENT_KEY_FILE must be set together")
+	}
+
+	if c.K6APIMaxIdleConns < 0 {
+		return fmt.Errorf("K6_API_MAX_IDLE_CONNS must not be negative")
+	}
+
+	if c.K6APIKeepAlive < 0 {
+		return fmt.Errorf("K6_API_KEEPALIVE must not be negative")
+	}
+
+	if c.K6APIRateLimit < 0 {
+		return fmt.Errorf("K6_API_RATE_LIMIT must not be negative")
+	}
+
+	if c.K6APIRateLimit > 0 && c.K6APIRateLimitBurst < 1 {
+		return fmt.Errorf("K6_API_RATE_LIMIT_BURST must be at least 1")
+	}
+
+	if c.K6APIBreakerFailures < 0 {
+		return fmt.Errorf("K6_API_BREAKER_FAILURES must not be negative")
+	}
+
+	if c.K6APIBreakerErrorRatio < 0 || c.K6APIBreakerErrorRatio > 1 {
+		return fmt.Errorf("K6_API_BREAKER_ERROR_RATIO must be between 0 and 1")
+	}
+
+	if c.K6APIBreakerErrorRatio > 0 && c.K6APIBreakerWindow < 1 {
+		return fmt.Errorf("K6_API_BREAKER_WINDOW must be at least 1")
+	}
+
+	if c.BreakerEnabled() && c.K6APIBreakerOpenTimeout <= 0 {
+		return fmt.Errorf("K6_API_BREAKER_OPEN_TIMEOUT must be positive")
+	}
+
+	if c.K6APICacheSize < 0 {
+		return fmt.Errorf("K6_API_CACHE_SIZE must not be negative")
+	}
+
+	if c.K6APIRecordDir != "" && c.K6APIReplayDir != "" {
+		return fmt.Errorf("K6_API_RECORD_DIR and K6_API_REPLAY_DIR cannot both be set")
+	}
+
 	if c.MaxConcurrentRequests < 1 {
 		return fmt.Errorf("MAX_CONCURRENT_REQUESTS must be at least 1")
 	}
@@ -98,3 +360,100 @@ func (c *Config) ShouldMonitorProject(projectID string) bool {
 	}
 	return false
 }
+
+// GetStarterTeam returns the team of a run starter, or "unknown" if it is not mapped
+func (c *Config) GetStarterTeam(startedBy string) string {
+	for starter, team := range c.StarterTeams {
+		if strings.EqualFold(starter, startedBy) {
+			return team
+		}
+	}
+	return "unknown"
+}
+
+// StarterName returns the run starter as exposed under STARTED_BY_MODE, hashed with the
+// salt or redacted. An unknown starter stays empty.
+func (c *Config) StarterName(startedBy string) string {
+	if startedBy == "" {
+		return ""
+	}
+
+	switch c.StartedByMode {
+	case StartedByRedact:
+		return "redacted"
+	case StartedByHash:
+		sum := sha256.Sum256([]byte(c.StartedByHashSalt + strings.ToLower(startedBy)))
+		return hex.EncodeToString(sum[:])[:16]
+	default:
+		return startedBy
+	}
+}
+
+// GetStuckRunTimeout returns the longest time a test run may spend in the given status.
+// Test specific limits take precedence over project limits, which take precedence over
+// status limits. The second return value is false if no limit applies.
+func (c *Config) GetStuckRunTimeout(projectID, testID int, status string) (time.Duration, bool) {
+	keys := []string{
+		fmt.Sprintf("test/%d/%s", testID, status),
+		fmt.Sprintf("project/%d/%s", projectID, status),
+		status,
+	}
+
+	for _, key := range keys {
+		if value, ok := c.StuckRunTimeouts[key]; ok {
+			d, err := time.ParseDuration(value)
+			if err != nil || d <= 0 {
+				return 0, false
+			}
+			return d, true
+		}
+	}
+	return 0, false
+}
+
+// StuckRunStatuses returns the statuses that have a stuck run limit configured
+func (c *Config) StuckRunStatuses() []string {
+	seen := make(map[string]bool)
+	var statuses []string
+	for key := range c.StuckRunTimeouts {
+		status, err := parseStuckRunTimeoutKey(key)
+		if err != nil || seen[status] {
+			continue
+		}
+		seen[status] = true
+		statuses = append(statuses, status)
+	}
+	sort.Strings(statuses)
+	return statuses
+}
+
+// parseStuckRunTimeoutKey validates a STUCK_RUN_TIMEOUTS key and returns its status
+func parseStuckRunTimeoutKey(key string) (string, error) {
+	parts := strings.Split(key, "/")
+	switch {
+	case len(parts) == 1 && parts[0] != "":
+		return parts[0], nil
+	case len(parts) == 3 && (parts[0] == "project" || parts[0] == "test") && parts[2] != "":
+		if _, err := strconv.Atoi(parts[1]); err != nil {
+			return "", fmt.Errorf("invalid %s ID in %q", parts[0], key)
+		}
+		return parts[2], nil
+	default:
+		return "", fmt.Errorf("invalid key %q, expected <status>, project/<id>/<status> or test/<id>/<status>", key)
+	}
+}
+
+// IsGuardAllowlisted returns true if runs of the project must never be aborted by the guard
+func (c *Config) IsGuardAllowlisted(projectID string) bool {
+	for _, p := range c.GuardAllowlistProjects {
+		if p == projectID {
+			return true
+		}
+	}
+	return false
+}
+
+// BreakerEnabled returns true if the k6 API circuit breaker has a threshold
+func (c *Config) BreakerEnabled() bool {
+	return c.K6APIBreakerFailures > 0 || c.K6APIBreakerErrorRatio > 0
+}
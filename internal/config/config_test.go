@@ -38,6 +38,14 @@ func TestLoadConfig(t *testing.T) {
 				assert.Equal(t, 9090, cfg.Port)
 				assert.Equal(t, 60*time.Second, cfg.TestCacheTTL)
 				assert.Equal(t, []string{"100", "200"}, cfg.Projects)
+				assert.Equal(t, map[string]string{"initializing": "5m", "running": "1h"}, cfg.StuckRunTimeouts)
+				assert.False(t, cfg.GuardEnabled)
+				assert.Equal(t, 5*time.Minute, cfg.ReadyMaxStaleness)
+				assert.Equal(t, 1000, cfg.EventsBufferSize)
+				assert.Equal(t, 15*time.Second, cfg.EventsHeartbeatInterval)
+				assert.Zero(t, cfg.K6APIRateLimit)
+				assert.False(t, cfg.BreakerEnabled())
+				assert.Zero(t, cfg.K6APICacheSize)
 			},
 		},
 		{
@@ -50,6 +58,194 @@ func TestLoadConfig(t *testing.T) {
 			wantErr: true,
 			errMsg:  "K6_API_TOKEN is required",
 		},
+		{
+			name: "api_token_file",
+			envVars: map[string]string{
+				"K6_API_TOKEN_FILE": "/run/secrets/k6-token",
+				"GRAFANA_STACK_ID":  "test-stack-id",
+				"PROJECTS":          "100",
+			},
+			wantErr: false,
+			verify: func(t *testing.T, cfg *Config) {
+				assert.Empty(t, cfg.K6APIToken)
+				assert.Equal(t, "/run/secrets/k6-token", cfg.GetStacks()[0].K6APITokenFile)
+			},
+		},
+		{
+			name: "api_token_and_token_file",
+			envVars: map[string]string{
+				"K6_API_TOKEN":      "test-token",
+				"K6_API_TOKEN_FILE": "/run/secrets/k6-token",
+				"GRAFANA_STACK_ID":  "test-stack-id",
+				"PROJECTS":          "100",
+			},
+			wantErr: true,
+			errMsg:  "K6_API_TOKEN and K6_API_TOKEN_FILE cannot both be set",
+		},
+		{
+			name: "api_transport",
+			envVars: map[string]string{
+				"K6_API_TOKEN":            "test-token",
+				"GRAFANA_STACK_ID":        "test-stack-id",
+				"PROJECTS":                "100",
+				"K6_API_PROXY_URL":        "http://proxy.internal:3128",
+				"K6_API_NO_PROXY":         "internal.example,.corp",
+				"K6_API_CA_FILE":          "/etc/ssl/ca.pem",
+				"K6_API_CLIENT_CERT_FILE": "/etc/k6/client.pem",
+				"K6_API_CLIENT_KEY_FILE":  "/etc/k6/client-key.pem",
+				"K6_API_MAX_IDLE_CONNS":   "20",
+				"K6_API_KEEPALIVE":        "0",
+			},
+			wantErr: false,
+			verify: func(t *testing.T, cfg *Config) {
+				assert.Equal(t, "http://proxy.internal:3128", cfg.K6APIProxyURL)
+				assert.Equal(t, []string{"internal.example", ".corp"}, cfg.K6APINoProxy)
+				assert.Equal(t, "/etc/ssl/ca.pem", cfg.K6APICAFile)
+				assert.Equal(t, 20, cfg.K6APIMaxIdleConns)
+				assert.Equal(t, time.Duration(0), cfg.K6APIKeepAlive)
+			},
+		},
+		{
+			name: "api_rate_limit",
+			envVars: map[string]string{
+				"K6_API_TOKEN":            "test-token",
+				"GRAFANA_STACK_ID":        "test-stack-id",
+				"PROJECTS":                "100",
+				"K6_API_RATE_LIMIT":       "2.5",
+				"K6_API_RATE_LIMIT_BURST": "5",
+			},
+			wantErr: false,
+			verify: func(t *testing.T, cfg *Config) {
+				assert.Equal(t, 2.5, cfg.K6APIRateLimit)
+				assert.Equal(t, 5, cfg.K6APIRateLimitBurst)
+			},
+		},
+		{
+			name: "invalid_api_rate_limit_burst",
+			envVars: map[string]string{
+				"K6_API_TOKEN":            "test-token",
+				"GRAFANA_STACK_ID":        "test-stack-id",
+				"PROJECTS":                "100",
+				"K6_API_RATE_LIMIT":       "5",
+				"K6_API_RATE_LIMIT_BURST": "0",
+			},
+			wantErr: true,
+			errMsg:  "K6_API_RATE_LIMIT_BURST must be at least 1",
+		},
+		{
+			name: "api_circuit_breaker",
+			envVars: map[string]string{
+				"K6_API_TOKEN":                "test-token",
+				"GRAFANA_STACK_ID":            "test-stack-id",
+				"PROJECTS":                    "100",
+				"K6_API_BREAKER_FAILURES":     "3",
+				"K6_API_BREAKER_ERROR_RATIO":  "0.25",
+				"K6_API_BREAKER_WINDOW":       "40",
+				"K6_API_BREAKER_OPEN_TIMEOUT": "1m",
+			},
+			wantErr: false,
+			verify: func(t *testing.T, cfg *Config) {
+				assert.Equal(t, 3, cfg.K6APIBreakerFailures)
+				assert.Equal(t, 0.25, cfg.K6APIBreakerErrorRatio)
+				assert.Equal(t, 40, cfg.K6APIBreakerWindow)
+				assert.Equal(t, time.Minute, cfg.K6APIBreakerOpenTimeout)
+				assert.True(t, cfg.BreakerEnabled())
+			},
+		},
+		{
+			name: "invalid_api_breaker_error_ratio",
+			envVars: map[string]string{
+				"K6_API_TOKEN":               "test-token",
+				"GRAFANA_STACK_ID":           "test-stack-id",
+				"PROJECTS":                   "100",
+				"K6_API_BREAKER_ERROR_RATIO": "1.5",
+			},
+			wantErr: true,
+			errMsg:  "K6_API_BREAKER_ERROR_RATIO must be between 0 and 1",
+		},
+		{
+			name: "invalid_api_breaker_open_timeout",
+			envVars: map[string]string{
+				"K6_API_TOKEN":                "test-token",
+				"GRAFANA_STACK_ID":            "test-stack-id",
+				"PROJECTS":                    "100",
+				"K6_API_BREAKER_FAILURES":     "5",
+				"K6_API_BREAKER_OPEN_TIMEOUT": "0s",
+			},
+			wantErr: true,
+			errMsg:  "K6_API_BREAKER_OPEN_TIMEOUT must be positive",
+		},
+		{
+			name: "api_cache_size",
+			envVars: map[string]string{
+				"K6_API_TOKEN":      "test-token",
+				"GRAFANA_STACK_ID":  "test-stack-id",
+				"PROJECTS":          "100",
+				"K6_API_CACHE_SIZE": "500",
+			},
+			wantErr: false,
+			verify: func(t *testing.T, cfg *Config) {
+				assert.Equal(t, 500, cfg.K6APICacheSize)
+			},
+		},
+		{
+			name: "invalid_api_cache_size",
+			envVars: map[string]string{
+				"K6_API_TOKEN":      "test-token",
+				"GRAFANA_STACK_ID":  "test-stack-id",
+				"PROJECTS":          "100",
+				"K6_API_CACHE_SIZE": "-1",
+			},
+			wantErr: true,
+			errMsg:  "K6_API_CACHE_SIZE must not be negative",
+		},
+		{
+			name: "api_replay_without_token",
+			envVars: map[string]string{
+				"GRAFANA_STACK_ID":  "test-stack-id",
+				"PROJECTS":          "100",
+				"K6_API_REPLAY_DIR": "/tmp/fixtures",
+			},
+			wantErr: false,
+			verify: func(t *testing.T, cfg *Config) {
+				assert.Equal(t, "/tmp/fixtures", cfg.K6APIReplayDir)
+				assert.Empty(t, cfg.K6APIToken)
+			},
+		},
+		{
+			name: "api_record_and_replay",
+			envVars: map[string]string{
+				"K6_API_TOKEN":      "test-token",
+				"GRAFANA_STACK_ID":  "test-stack-id",
+				"PROJECTS":          "100",
+				"K6_API_RECORD_DIR": "/tmp/fixtures",
+				"K6_API_REPLAY_DIR": "/tmp/fixtures",
+			},
+			wantErr: true,
+			errMsg:  "K6_API_RECORD_DIR and K6_API_REPLAY_DIR cannot both be set",
+		},
+		{
+			name: "invalid_proxy_url",
+			envVars: map[string]string{
+				"K6_API_TOKEN":     "test-token",
+				"GRAFANA_STACK_ID": "test-stack-id",
+				"PROJECTS":         "100",
+				"K6_API_PROXY_URL": "ftp://proxy.internal",
+			},
+			wantErr: true,
+			errMsg:  "K6_API_PROXY_URL must start with http://, https:// or socks5://",
+		},
+		{
+			name: "client_cert_without_key",
+			envVars: map[string]string{
+				"K6_API_TOKEN":            "test-token",
+				"GRAFANA_STACK_ID":        "test-stack-id",
+				"PROJECTS":                "100",
+				"K6_API_CLIENT_CERT_FILE": "/etc/k6/client.pem",
+			},
+			wantErr: true,
+			errMsg:  "K6_API_CLIENT_CERT_FILE and K6_API_CLIENT_KEY_FILE must be set together",
+		},
 		{
 			name: "missing_stack_id",
 			envVars: map[string]string{
@@ -141,6 +337,264 @@ func TestLoadConfig(t *testing.T) {
 			wantErr: true,
 			errMsg:  "STATE_CLEANUP_INTERVAL must be at least 1 minute",
 		},
+		{
+			name: "starter_teams",
+			envVars: map[string]string{
+				"K6_API_TOKEN":     "test-token",
+				"GRAFANA_STACK_ID": "test-stack-id",
+				"PROJECTS":         "100",
+				"STARTED_BY_MODE":  "hash",
+				"STARTER_TEAMS":    "alice@example.com:payments,bob@example.com:search",
+			},
+			wantErr: false,
+			verify: func(t *testing.T, cfg *Config) {
+				assert.Equal(t, StartedByHash, cfg.StartedByMode)
+				assert.Equal(t, "payments", cfg.GetStarterTeam("alice@example.com"))
+				assert.Equal(t, "search", cfg.GetStarterTeam("BOB@example.com"))
+				assert.Equal(t, "unknown", cfg.GetStarterTeam("carol@example.com"))
+			},
+		},
+		{
+			name: "invalid_started_by_mode",
+			envVars: map[string]string{
+				"K6_API_TOKEN":     "test-token",
+				"GRAFANA_STACK_ID": "test-stack-id",
+				"PROJECTS":         "100",
+				"STARTED_BY_MODE":  "encrypt",
+			},
+			wantErr: true,
+			errMsg:  "STARTED_BY_MODE must be one of plain, hash or redact",
+		},
+		{
+			name: "stuck_run_timeouts_merged_over_defaults",
+			envVars: map[string]string{
+				"K6_API_TOKEN":       "test-token",
+				"GRAFANA_STACK_ID":   "test-stack-id",
+				"PROJECTS":           "100",
+				"STUCK_RUN_TIMEOUTS": "project/1/running:2h,initializing:0",
+			},
+			wantErr: false,
+			verify: func(t *testing.T, cfg *Config) {
+				assert.Equal(t, map[string]string{"initializing": "0", "running": "1h", "project/1/running": "2h"}, cfg.StuckRunTimeouts)
+				timeout, ok := cfg.GetStuckRunTimeout(1, 5, "running")
+				assert.True(t, ok)
+				assert.Equal(t, 2*time.Hour, timeout)
+				timeout, ok = cfg.GetStuckRunTimeout(2, 5, "running")
+				assert.True(t, ok)
+				assert.Equal(t, time.Hour, timeout, "the default applies to other projects")
+				_, ok = cfg.GetStuckRunTimeout(1, 5, "initializing")
+				assert.False(t, ok, "a timeout of 0 disables the limit")
+			},
+		},
+		{
+			name: "invalid_stuck_run_timeout_key",
+			envVars: map[string]string{
+				"K6_API_TOKEN":       "test-token",
+				"GRAFANA_STACK_ID":   "test-stack-id",
+				"PROJECTS":           "100",
+				"STUCK_RUN_TIMEOUTS": "team/a/running:1h",
+			},
+			wantErr: true,
+			errMsg:  "STUCK_RUN_TIMEOUTS: invalid key",
+		},
+		{
+			name: "invalid_stuck_run_timeout_value",
+			envVars: map[string]string{
+				"K6_API_TOKEN":       "test-token",
+				"GRAFANA_STACK_ID":   "test-stack-id",
+				"PROJECTS":           "100",
+				"STUCK_RUN_TIMEOUTS": "running:forever",
+			},
+			wantErr: true,
+			errMsg:  "STUCK_RUN_TIMEOUTS: invalid timeout",
+		},
+		{
+			name: "guard_requires_token",
+			envVars: map[string]string{
+				"K6_API_TOKEN":       "test-token",
+				"GRAFANA_STACK_ID":   "test-stack-id",
+				"PROJECTS":           "100",
+				"GUARD_ENABLED":      "true",
+				"GUARD_MAX_DURATION": "3h",
+			},
+			wantErr: true,
+			errMsg:  "GUARD_API_TOKEN is required when GUARD_ENABLED is set",
+		},
+		{
+			name: "guard_requires_limit",
+			envVars: map[string]string{
+				"K6_API_TOKEN":     "test-token",
+				"GRAFANA_STACK_ID": "test-stack-id",
+				"PROJECTS":         "100",
+				"GUARD_ENABLED":    "true",
+				"GUARD_API_TOKEN":  "guard-token",
+			},
+			wantErr: true,
+			errMsg:  "GUARD_MAX_DURATION or GUARD_MAX_VUH is required",
+		},
+		{
+			name: "guard_enabled",
+			envVars: map[string]string{
+				"K6_API_TOKEN":             "test-token",
+				"GRAFANA_STACK_ID":         "test-stack-id",
+				"PROJECTS":                 "100",
+				"GUARD_ENABLED":            "true",
+				"GUARD_API_TOKEN":          "guard-token",
+				"GUARD_MAX_VUH":            "250",
+				"GUARD_ALLOWLIST_PROJECTS": "100,200",
+			},
+			wantErr: false,
+			verify: func(t *testing.T, cfg *Config) {
+				assert.True(t, cfg.GuardEnabled)
+				assert.True(t, cfg.GuardDryRun, "dry run should be the default")
+				assert.Equal(t, 250.0, cfg.GuardMaxVUH)
+				assert.Equal(t, time.Minute, cfg.GuardCheckInterval)
+				assert.True(t, cfg.IsGuardAllowlisted("200"))
+				assert.False(t, cfg.IsGuardAllowlisted("300"))
+			},
+		},
+		{
+			name: "annotations_require_token",
+			envVars: map[string]string{
+				"K6_API_TOKEN":            "test-token",
+				"GRAFANA_STACK_ID":        "test-stack-id",
+				"PROJECTS":                "100",
+				"GRAFANA_ANNOTATIONS_URL": "https://grafana.example.com",
+			},
+			wantErr: true,
+			errMsg:  "GRAFANA_ANNOTATIONS_TOKEN is required when GRAFANA_ANNOTATIONS_URL is set",
+		},
+		{
+			name: "annotations_enabled",
+			envVars: map[string]string{
+				"K6_API_TOKEN":                       "test-token",
+				"GRAFANA_STACK_ID":                   "test-stack-id",
+				"PROJECTS":                           "100",
+				"GRAFANA_ANNOTATIONS_URL":            "https://grafana.example.com",
+				"GRAFANA_ANNOTATIONS_TOKEN":          "glsa_token",
+				"GRAFANA_ANNOTATIONS_DASHBOARD_UIDS": "checkout,search",
+			},
+			wantErr: false,
+			verify: func(t *testing.T, cfg *Config) {
+				assert.Equal(t, []string{"checkout", "search"}, cfg.GrafanaAnnotationsDashboardUIDs)
+			},
+		},
+		{
+			name: "remote_write_enabled",
+			envVars: map[string]string{
+				"K6_API_TOKEN":         "test-token",
+				"GRAFANA_STACK_ID":     "test-stack-id",
+				"PROJECTS":             "100",
+				"REMOTE_WRITE_URL":     "https://prometheus.example.com/api/prom/push",
+				"REMOTE_WRITE_HEADERS": "X-Scope-OrgID:team-a",
+			},
+			wantErr: false,
+			verify: func(t *testing.T, cfg *Config) {
+				assert.Equal(t, 30*time.Second, cfg.RemoteWriteInterval)
+				assert.Equal(t, 3, cfg.RemoteWriteMaxRetries)
+				assert.Equal(t, 10, cfg.RemoteWriteQueueSize)
+				assert.Equal(t, map[string]string{"X-Scope-OrgID": "team-a"}, cfg.RemoteWriteHeaders)
+			},
+		},
+		{
+			name: "remote_write_invalid_queue_size",
+			envVars: map[string]string{
+				"K6_API_TOKEN":            "test-token",
+				"GRAFANA_STACK_ID":        "test-stack-id",
+				"PROJECTS":                "100",
+				"REMOTE_WRITE_URL":        "https://prometheus.example.com/api/prom/push",
+				"REMOTE_WRITE_QUEUE_SIZE": "0",
+			},
+			wantErr: true,
+			errMsg:  "REMOTE_WRITE_QUEUE_SIZE must be at least 1",
+		},
+		{
+			name: "otlp_invalid_protocol",
+			envVars: map[string]string{
+				"K6_API_TOKEN":     "test-token",
+				"GRAFANA_STACK_ID": "test-stack-id",
+				"PROJECTS":         "100",
+				"OTLP_ENDPOINT":    "http://otel-collector:4318",
+				"OTLP_PROTOCOL":    "grpc",
+			},
+			wantErr: true,
+			errMsg:  "OTLP_PROTOCOL must be http/protobuf or http/json",
+		},
+		{
+			name: "multiple_stacks",
+			envVars: map[string]string{
+				"STACKS":                      "prod,bu-a",
+				"STACK_PROD_K6_API_TOKEN":     "prod-token",
+				"STACK_PROD_GRAFANA_STACK_ID": "1",
+				"STACK_PROD_PROJECTS":         "100,200",
+				"STACK_BU_A_K6_API_TOKEN":     "bu-a-token",
+				"STACK_BU_A_GRAFANA_STACK_ID": "2",
+				"STACK_BU_A_K6_API_URL":       "https://api.eu.k6.io",
+			},
+			wantErr: false,
+			verify: func(t *testing.T, cfg *Config) {
+				stacks := cfg.GetStacks()
+				require.Len(t, stacks, 2)
+				assert.Equal(t, StackConfig{Name: "prod", K6APIToken: "prod-token", K6APIURL: "https://api.k6.io", GrafanaStackID: "1", Projects: []string{"100", "200"}}, stacks[0])
+				assert.Equal(t, "https://api.eu.k6.io", stacks[1].K6APIURL)
+
+				stackCfg := cfg.ForStack(stacks[1])
+				assert.Equal(t, "bu-a", stackCfg.StackName)
+				assert.Equal(t, "bu-a-token", stackCfg.K6APIToken)
+				assert.Equal(t, "2", stackCfg.GrafanaStackID)
+				assert.Empty(t, stackCfg.Projects)
+			},
+		},
+		{
+			name: "stack_requires_token",
+			envVars: map[string]string{
+				"STACKS":                      "prod",
+				"STACK_PROD_GRAFANA_STACK_ID": "1",
+			},
+			wantErr: true,
+			errMsg:  "STACK_PROD_K6_API_TOKEN is required",
+		},
+		{
+			name: "stack_requires_guard_token",
+			envVars: map[string]string{
+				"STACKS":                      "prod",
+				"STACK_PROD_K6_API_TOKEN":     "prod-token",
+				"STACK_PROD_GRAFANA_STACK_ID": "1",
+				"GUARD_ENABLED":               "true",
+				"GUARD_MAX_VUH":               "100",
+			},
+			wantErr: true,
+			errMsg:  "STACK_PROD_GUARD_API_TOKEN is required when GUARD_ENABLED is set",
+		},
+		{
+			name: "invalid_stack_name",
+			envVars: map[string]string{
+				"STACKS": "Prod Stack",
+			},
+			wantErr: true,
+			errMsg:  "invalid stack name",
+		},
+		{
+			name: "missing_projects_without_stacks",
+			envVars: map[string]string{
+				"K6_API_TOKEN":     "test-token",
+				"GRAFANA_STACK_ID": "test-stack-id",
+			},
+			wantErr: true,
+			errMsg:  "required key PROJECTS missing value",
+		},
+		{
+			name: "ready_max_staleness_below_scrape_interval",
+			envVars: map[string]string{
+				"K6_API_TOKEN":        "test-token",
+				"GRAFANA_STACK_ID":    "test-stack-id",
+				"PROJECTS":            "100",
+				"SCRAPE_INTERVAL":     "1m",
+				"READY_MAX_STALENESS": "30s",
+			},
+			wantErr: true,
+			errMsg:  "READY_MAX_STALENESS must be at least SCRAPE_INTERVAL",
+		},
 		{
 			name: "invalid_max_concurrent_requests",
 			envVars: map[string]string{
@@ -161,6 +615,21 @@ func TestLoadConfig(t *testing.T) {
 				"K6_API_TOKEN", "GRAFANA_STACK_ID", "K6_API_URL", "PORT", "TEST_CACHE_TTL",
 				"STATE_CLEANUP_INTERVAL", "PROJECTS", "MAX_CONCURRENT_REQUESTS",
 				"API_TIMEOUT", "RETRY_ATTEMPTS", "RETRY_DELAY", "SCRAPE_INTERVAL",
+				"STARTED_BY_MODE", "STARTED_BY_HASH_SALT", "STARTER_TEAMS", "STUCK_RUN_TIMEOUTS",
+				"GUARD_ENABLED", "GUARD_API_TOKEN", "GUARD_DRY_RUN", "GUARD_MAX_DURATION", "GUARD_MAX_VUH",
+				"GUARD_ALLOWLIST_PROJECTS", "GUARD_CHECK_INTERVAL", "READY_MAX_STALENESS", "WEB_CONFIG_FILE",
+				"EVENTS_BUFFER_SIZE", "EVENTS_HEARTBEAT_INTERVAL", "NOTIFICATIONS_CONFIG_FILE",
+				"GRAFANA_ANNOTATIONS_URL", "GRAFANA_ANNOTATIONS_TOKEN", "GRAFANA_ANNOTATIONS_DASHBOARD_UIDS",
+				"REMOTE_WRITE_URL", "REMOTE_WRITE_INTERVAL", "REMOTE_WRITE_USERNAME", "REMOTE_WRITE_PASSWORD",
+				"REMOTE_WRITE_HEADERS", "REMOTE_WRITE_TIMEOUT", "REMOTE_WRITE_MAX_RETRIES", "REMOTE_WRITE_RETRY_BACKOFF",
+				"REMOTE_WRITE_QUEUE_SIZE", "OTLP_ENDPOINT", "OTLP_PROTOCOL", "OTLP_HEADERS", "OTLP_INTERVAL", "OTLP_TIMEOUT",
+				"K6_API_TOKEN_FILE", "STACK_PROD_K6_API_TOKEN_FILE",
+				"K6_API_PROXY_URL", "K6_API_NO_PROXY", "K6_API_CA_FILE", "K6_API_CLIENT_CERT_FILE", "K6_API_CLIENT_KEY_FILE",
+				"K6_API_MAX_IDLE_CONNS", "K6_API_KEEPALIVE", "K6_API_RATE_LIMIT", "K6_API_RATE_LIMIT_BURST",
+				"K6_API_BREAKER_FAILURES", "K6_API_BREAKER_ERROR_RATIO", "K6_API_BREAKER_WINDOW", "K6_API_BREAKER_OPEN_TIMEOUT",
+				"K6_API_CACHE_SIZE", "K6_API_RECORD_DIR", "K6_API_REPLAY_DIR",
+				"STACKS", "STACK_PROD_K6_API_TOKEN", "STACK_PROD_GRAFANA_STACK_ID", "STACK_PROD_PROJECTS",
+				"STACK_PROD_GUARD_API_TOKEN", "STACK_BU_A_K6_API_TOKEN", "STACK_BU_A_GRAFANA_STACK_ID", "STACK_BU_A_K6_API_URL",
 			}
 			for _, v := range envVars {
 				os.Unsetenv(v)
@@ -265,6 +734,43 @@ func TestShouldMonitorProject(t *testing.T) {
 	}
 }
 
+func TestGetStuckRunTimeout(t *testing.T) {
+	cfg := &Config{
+		StuckRunTimeouts: map[string]string{
+			"initializing":         "5m",
+			"running":              "1h",
+			"project/100/running":  "2h",
+			"test/42/running":      "6h",
+			"test/42/initializing": "15m",
+		},
+	}
+
+	tests := []struct {
+		name      string
+		projectID int
+		testID    int
+		status    string
+		want      time.Duration
+		wantFound bool
+	}{
+		{name: "status_default", projectID: 200, testID: 1, status: "running", want: time.Hour, wantFound: true},
+		{name: "project_override", projectID: 100, testID: 1, status: "running", want: 2 * time.Hour, wantFound: true},
+		{name: "test_override", projectID: 100, testID: 42, status: "running", want: 6 * time.Hour, wantFound: true},
+		{name: "project_falls_back_to_status", projectID: 100, testID: 1, status: "initializing", want: 5 * time.Minute, wantFound: true},
+		{name: "no_limit", projectID: 100, testID: 1, status: "processing_metrics", wantFound: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, found := cfg.GetStuckRunTimeout(tt.projectID, tt.testID, tt.status)
+			assert.Equal(t, tt.wantFound, found)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+
+	assert.Equal(t, []string{"initializing", "running"}, cfg.StuckRunStatuses())
+}
+
 func TestValidate(t *testing.T) {
 	tests := []struct {
 		name    string
@@ -0,0 +1,109 @@
+package config
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/kelseyhightower/envconfig"
+)
+
+// StackConfig holds the k6 API configuration of one Grafana stack
+type StackConfig struct {
+	Name           string   `ignored:"true"`
+	K6APIToken     string   `envconfig:"K6_API_TOKEN"`
+	K6APITokenFile string   `envconfig:"K6_API_TOKEN_FILE"` // File with the token, reread when it changes
+	K6APIURL       string   `envconfig:"K6_API_URL" default:"https://api.k6.io"`
+	GrafanaStackID string   `envconfig:"GRAFANA_STACK_ID"`
+	Projects       []string `envconfig:"PROJECTS"`        // Comma-separated list of project IDs to monitor
+	GuardAPIToken  string   `envconfig:"GUARD_API_TOKEN"` // Token with permission to abort test runs of the stack
+}
+
+// singleStack holds the variables required when STACKS is not set
+type singleStack struct {
+	GrafanaStackID string   `envconfig:"GRAFANA_STACK_ID" required:"true"`
+	Projects       []string `envconfig:"PROJECTS" required:"true"`
+}
+
+// Valid stack names, used in the stack label and the STACK_<NAME>_* variables
+var stackNamePattern = regexp.MustCompile(`^[a-z0-9][a-z0-9_-]*$`)
+
+// loadStacks loads the configuration of each stack from its STACK_<NAME>_* variables
+func loadStacks(names []string) ([]StackConfig, error) {
+	stacks := make([]StackConfig, 0, len(names))
+	for _, name := range names {
+		stack := StackConfig{Name: name}
+		if err := envconfig.Process(StackEnvPrefix(name), &stack); err != nil {
+			return nil, fmt.Errorf("stack %q: %w", name, err)
+		}
+		stacks = append(stacks, stack)
+	}
+	return stacks, nil
+}
+
+// StackEnvPrefix returns the prefix of the variables of a stack, STACK_BU_A for bu-a
+func StackEnvPrefix(name string) string {
+	return "STACK_" + strings.ToUpper(strings.ReplaceAll(name, "-", "_"))
+}
+
+// validateStacks validates the configuration of every stack
+func (c *Config) validateStacks() error {
+	prefixes := make(map[string]bool, len(c.StackConfigs))
+	for _, stack := range c.StackConfigs {
+		if !stackNamePattern.MatchString(stack.Name) {
+			return fmt.Errorf("STACKS: invalid stack name %q, use lowercase letters, digits, - and _", stack.Name)
+		}
+
+		prefix := StackEnvPrefix(stack.Name)
+		if prefixes[prefix] {
+			return fmt.Errorf("STACKS: duplicate stack %q", stack.Name)
+		}
+		prefixes[prefix] = true
+
+		if stack.K6APIToken == "" && stack.K6APITokenFile == "" && c.K6APIReplayDir == "" {
+			return fmt.Errorf("%s_K6_API_TOKEN is required unless %s_K6_API_TOKEN_FILE or K6_API_REPLAY_DIR is set", prefix, prefix)
+		}
+		if stack.K6APIToken != "" && stack.K6APITokenFile != "" {
+			return fmt.Errorf("%s_K6_API_TOKEN and %s_K6_API_TOKEN_FILE cannot both be set", prefix, prefix)
+		}
+		if stack.GrafanaStackID == "" {
+			return fmt.Errorf("%s_GRAFANA_STACK_ID is required", prefix)
+		}
+		if !strings.HasPrefix(stack.K6APIURL, "http://") && !strings.HasPrefix(stack.K6APIURL, "https://") {
+			return fmt.Errorf("%s_K6_API_URL must start with http:// or https://", prefix)
+		}
+		if c.GuardEnabled && stack.GuardAPIToken == "" {
+			return fmt.Errorf("%s_GUARD_API_TOKEN is required when GUARD_ENABLED is set", prefix)
+		}
+	}
+	return nil
+}
+
+// GetStacks returns the configured stacks. Without STACKS this is a single unnamed stack
+// from the K6_API_TOKEN(_FILE), K6_API_URL, GRAFANA_STACK_ID and PROJECTS variables.
+func (c *Config) GetStacks() []StackConfig {
+	if len(c.StackConfigs) > 0 {
+		return c.StackConfigs
+	}
+	return []StackConfig{{
+		K6APIToken:     c.K6APIToken,
+		K6APITokenFile: c.K6APITokenFile,
+		K6APIURL:       c.K6APIURL,
+		GrafanaStackID: c.GrafanaStackID,
+		Projects:       c.Projects,
+		GuardAPIToken:  c.GuardAPIToken,
+	}}
+}
+
+// ForStack returns a copy of the configuration with the k6 API settings of the stack
+func (c *Config) ForStack(stack StackConfig) *Config {
+	stackCfg := *c
+	stackCfg.StackName = stack.Name
+	stackCfg.K6APIToken = stack.K6APIToken
+	stackCfg.K6APITokenFile = stack.K6APITokenFile
+	stackCfg.K6APIURL = stack.K6APIURL
+	stackCfg.GrafanaStackID = stack.GrafanaStackID
+	stackCfg.Projects = stack.Projects
+	stackCfg.GuardAPIToken = stack.GuardAPIToken
+	return &stackCfg
+}
@@ -0,0 +1,216 @@
+package guard
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"go.uber.org/zap"
+
+	"github.com/grafana-cloud-k6-prometheus-exporter/internal/config"
+	"github.com/grafana-cloud-k6-prometheus-exporter/internal/k6client"
+	"github.com/grafana-cloud-k6-prometheus-exporter/internal/state"
+)
+
+// Reasons a test run gets aborted
+const (
+	ReasonMaxDuration = "max_duration"
+	ReasonMaxVUH      = "max_vuh"
+)
+
+// Guard aborts tracked test runs that exceed the configured duration or VUH limits
+type Guard struct {
+	client       k6client.ClientInterface
+	stateManager *state.Manager
+	config       *config.Config
+	logger       *zap.Logger
+	metrics      *Metrics
+
+	// Runs that have already been aborted (or would have been in dry-run mode)
+	mu      sync.Mutex
+	handled map[int]time.Time
+}
+
+// Metrics holds the guard metrics
+type Metrics struct {
+	RunsAbortedTotal *prometheus.CounterVec
+	AbortErrorsTotal prometheus.Counter
+}
+
+// NewMetrics creates the guard metrics and registers them if a registerer is provided
+func NewMetrics(reg prometheus.Registerer) *Metrics {
+	metrics := &Metrics{
+		RunsAbortedTotal: prometheus.NewCounterVec(
+			prometheus.CounterOpts{
+				Name: "k6_exporter_runs_aborted_total",
+				Help: "Total number of test runs aborted by the guard",
+			},
+			[]string{"reason", "dry_run"},
+		),
+		AbortErrorsTotal: prometheus.NewCounter(
+			prometheus.CounterOpts{
+				Name: "k6_exporter_guard_abort_errors_total",
+				Help: "Total number of failed attempts to abort a test run",
+			},
+		),
+	}
+
+	if reg != nil {
+		reg.MustRegister(
+			metrics.RunsAbortedTotal,
+			metrics.AbortErrorsTotal,
+		)
+	}
+
+	return metrics
+}
+
+// NewGuard creates a new guard. The client must be authenticated with a token
+// that is allowed to abort test runs.
+func NewGuard(client k6client.ClientInterface, stateManager *state.Manager, cfg *config.Config, logger *zap.Logger, reg prometheus.Registerer) *Guard {
+	return &Guard{
+		client:       client,
+		stateManager: stateManager,
+		config:       cfg,
+		logger:       logger.Named("guard"),
+		metrics:      NewMetrics(reg),
+		handled:      make(map[int]time.Time),
+	}
+}
+
+// Start periodically checks the tracked test runs until the context is cancelled
+func (g *Guard) Start(ctx context.Context) {
+	g.logger.Info("guard enabled",
+		zap.Bool("dry_run", g.config.GuardDryRun),
+		zap.Duration("max_duration", g.config.GuardMaxDuration),
+		zap.Float64("max_vuh", g.config.GuardMaxVUH),
+		zap.Strings("allowlist_projects", g.config.GuardAllowlistProjects),
+	)
+
+	go func() {
+		ticker := time.NewTicker(g.config.GuardCheckInterval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				g.Check(ctx)
+			}
+		}
+	}()
+}
+
+// Check aborts every tracked test run that exceeds a limit and returns the number of runs acted on
+func (g *Guard) Check(ctx context.Context) int {
+	now := time.Now()
+	acted := 0
+
+	for _, runState := range g.stateManager.GetAllStates() {
+		// Runs of other stacks are guarded with their own token
+		if runState.Stack != g.config.StackName {
+			continue
+		}
+
+		reason, limit := g.violation(runState, now)
+		if reason == "" {
+			continue
+		}
+		if !g.markHandled(runState.TestRunID, now) {
+			continue
+		}
+
+		if g.abort(ctx, runState, reason, limit, now) {
+			acted++
+		}
+	}
+
+	g.forgetHandled(now.Add(-24 * time.Hour))
+	return acted
+}
+
+// violation returns the reason and the exceeded limit if the run must be aborted
+func (g *Guard) violation(runState *state.TestRunState, now time.Time) (string, string) {
+	// Nothing left to abort once the run is processing its metrics
+	if runState.CurrentStatus == k6client.StatusProcessingMetrics || k6client.IsTerminalStatus(runState.CurrentStatus) {
+		return "", ""
+	}
+	if g.config.IsGuardAllowlisted(strconv.Itoa(runState.ProjectID)) {
+		return "", ""
+	}
+
+	if g.config.GuardMaxDuration > 0 && now.Sub(runState.Created) > g.config.GuardMaxDuration {
+		return ReasonMaxDuration, g.config.GuardMaxDuration.String()
+	}
+	if g.config.GuardMaxVUH > 0 && runState.VUH > g.config.GuardMaxVUH {
+		return ReasonMaxVUH, strconv.FormatFloat(g.config.GuardMaxVUH, 'f', -1, 64)
+	}
+	return "", ""
+}
+
+// abort aborts the test run, or only logs the decision in dry-run mode
+func (g *Guard) abort(ctx context.Context, runState *state.TestRunState, reason, limit string, now time.Time) bool {
+	dryRun := g.config.GuardDryRun
+	fields := []zap.Field{
+		zap.String("audit", "abort_test_run"),
+		zap.Int("run_id", runState.TestRunID),
+		zap.Int("test_id", runState.TestID),
+		zap.String("test_name", runState.TestName),
+		zap.Int("project_id", runState.ProjectID),
+		zap.String("started_by", runState.StartedBy),
+		zap.String("status", runState.CurrentStatus),
+		zap.Duration("duration", now.Sub(runState.Created)),
+		zap.Float64("vuh", runState.VUH),
+		zap.String("reason", reason),
+		zap.String("limit", limit),
+		zap.Bool("dry_run", dryRun),
+	}
+
+	if !dryRun {
+		if err := g.client.AbortTestRun(ctx, runState.TestRunID); err != nil {
+			g.logger.Error("failed to abort test run", append(fields, zap.Error(err))...)
+			g.metrics.AbortErrorsTotal.Inc()
+			g.unmarkHandled(runState.TestRunID)
+			return false
+		}
+	}
+
+	g.logger.Warn(fmt.Sprintf("test run exceeded %s limit", reason), fields...)
+	g.metrics.RunsAbortedTotal.WithLabelValues(reason, strconv.FormatBool(dryRun)).Inc()
+	return true
+}
+
+// markHandled records that the run has been acted on and returns false if it already was
+func (g *Guard) markHandled(runID int, now time.Time) bool {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	if _, exists := g.handled[runID]; exists {
+		return false
+	}
+	g.handled[runID] = now
+	return true
+}
+
+// unmarkHandled allows the run to be retried on the next check
+func (g *Guard) unmarkHandled(runID int) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	delete(g.handled, runID)
+}
+
+// forgetHandled removes runs handled before the cutoff
+func (g *Guard) forgetHandled(cutoff time.Time) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	for runID, handledAt := range g.handled {
+		if handledAt.Before(cutoff) {
+			delete(g.handled, runID)
+		}
+	}
+}
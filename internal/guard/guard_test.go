@@ -0,0 +1,117 @@
+package guard
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/zap/zaptest"
+
+	"github.com/grafana-cloud-k6-prometheus-exporter/internal/config"
+	"github.com/grafana-cloud-k6-prometheus-exporter/internal/k6client"
+	"github.com/grafana-cloud-k6-prometheus-exporter/internal/state"
+)
+
+func newTestState(t *testing.T, now time.Time) *state.Manager {
+	manager := state.NewManager(zaptest.NewLogger(t))
+
+	runs := []*state.TestRunState{
+		// Running for 3 hours
+		{TestRunID: 1, TestID: 10, ProjectID: 100, TestName: "Soak", CurrentStatus: k6client.StatusRunning, Created: now.Add(-3 * time.Hour), VUH: 5},
+		// Burned too many VUH
+		{TestRunID: 2, TestID: 20, ProjectID: 100, TestName: "Spike", CurrentStatus: k6client.StatusRunning, Created: now.Add(-10 * time.Minute), VUH: 150},
+		// Within limits
+		{TestRunID: 3, TestID: 30, ProjectID: 100, TestName: "Smoke", CurrentStatus: k6client.StatusRunning, Created: now.Add(-5 * time.Minute), VUH: 1},
+		// Allowlisted project
+		{TestRunID: 4, TestID: 40, ProjectID: 200, TestName: "Endurance", CurrentStatus: k6client.StatusRunning, Created: now.Add(-10 * time.Hour), VUH: 500},
+		// Already processing metrics
+		{TestRunID: 5, TestID: 50, ProjectID: 100, TestName: "Done", CurrentStatus: k6client.StatusProcessingMetrics, Created: now.Add(-5 * time.Hour), VUH: 200},
+	}
+	for _, run := range runs {
+		manager.UpdateTestRun(run)
+	}
+	return manager
+}
+
+func newTestConfig(dryRun bool) *config.Config {
+	return &config.Config{
+		GuardEnabled:           true,
+		GuardAPIToken:          "guard-token",
+		GuardDryRun:            dryRun,
+		GuardMaxDuration:       2 * time.Hour,
+		GuardMaxVUH:            100,
+		GuardAllowlistProjects: []string{"200"},
+		GuardCheckInterval:     time.Minute,
+	}
+}
+
+func TestGuardCheck(t *testing.T) {
+	logger := zaptest.NewLogger(t)
+	client := k6client.NewMockClient()
+	registry := prometheus.NewRegistry()
+
+	guard := NewGuard(client, newTestState(t, time.Now()), newTestConfig(false), logger, registry)
+
+	acted := guard.Check(context.Background())
+	assert.Equal(t, 2, acted)
+	assert.ElementsMatch(t, []int{1, 2}, client.AbortedRuns)
+	assert.Equal(t, float64(1), testutil.ToFloat64(guard.metrics.RunsAbortedTotal.WithLabelValues(ReasonMaxDuration, "false")))
+	assert.Equal(t, float64(1), testutil.ToFloat64(guard.metrics.RunsAbortedTotal.WithLabelValues(ReasonMaxVUH, "false")))
+
+	// Runs are only aborted once
+	acted = guard.Check(context.Background())
+	assert.Equal(t, 0, acted)
+	assert.Equal(t, 2, client.AbortTestRunCalled)
+}
+
+func TestGuardDryRun(t *testing.T) {
+	logger := zaptest.NewLogger(t)
+	client := k6client.NewMockClient()
+	registry := prometheus.NewRegistry()
+
+	guard := NewGuard(client, newTestState(t, time.Now()), newTestConfig(true), logger, registry)
+
+	acted := guard.Check(context.Background())
+	assert.Equal(t, 2, acted)
+	assert.Equal(t, 0, client.AbortTestRunCalled, "dry run must not call the API")
+	assert.Equal(t, float64(1), testutil.ToFloat64(guard.metrics.RunsAbortedTotal.WithLabelValues(ReasonMaxDuration, "true")))
+}
+
+func TestGuardAbortError(t *testing.T) {
+	logger := zaptest.NewLogger(t)
+	client := k6client.NewMockClient()
+	client.AbortTestRunError = fmt.Errorf("API error")
+	registry := prometheus.NewRegistry()
+
+	guard := NewGuard(client, newTestState(t, time.Now()), newTestConfig(false), logger, registry)
+
+	acted := guard.Check(context.Background())
+	assert.Equal(t, 0, acted)
+	assert.Equal(t, float64(2), testutil.ToFloat64(guard.metrics.AbortErrorsTotal))
+
+	// Failed aborts are retried on the next check
+	client.AbortTestRunError = nil
+	acted = guard.Check(context.Background())
+	assert.Equal(t, 2, acted)
+}
+
+func TestGuardChecksOwnStack(t *testing.T) {
+	logger := zaptest.NewLogger(t)
+	now := time.Now()
+	manager := state.NewManager(logger)
+	manager.UpdateTestRun(&state.TestRunState{TestRunID: 1, ProjectID: 100, CurrentStatus: k6client.StatusRunning, Created: now.Add(-3 * time.Hour), Stack: "prod"})
+	manager.UpdateTestRun(&state.TestRunState{TestRunID: 2, ProjectID: 100, CurrentStatus: k6client.StatusRunning, Created: now.Add(-3 * time.Hour), Stack: "staging"})
+
+	cfg := newTestConfig(false)
+	cfg.StackName = "prod"
+	client := k6client.NewMockClient()
+	guard := NewGuard(client, manager, cfg, logger, prometheus.NewRegistry())
+
+	acted := guard.Check(context.Background())
+	assert.Equal(t, 1, acted)
+	assert.Equal(t, []int{1}, client.AbortedRuns)
+}
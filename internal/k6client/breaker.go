@@ -0,0 +1,208 @@
+package k6client
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"go.uber.org/zap"
+)
+
+// ErrCircuitOpen is returned without contacting the API while the circuit breaker is open
+var ErrCircuitOpen = errors.New("k6 API circuit breaker is open")
+
+// Circuit breaker states
+const (
+	CircuitClosed   = "closed"
+	CircuitHalfOpen = "half_open"
+	CircuitOpen     = "open"
+)
+
+// BreakerConfig configures a circuit breaker
+type BreakerConfig struct {
+	ConsecutiveFailures int           // Failures in a row that open the circuit
+	ErrorRatio          float64       // Ratio of failures within the window that opens the circuit, 0 disables it
+	Window              int           // Number of recent requests the error ratio is computed over
+	OpenTimeout         time.Duration // How long the circuit stays open before a probe request is let through
+}
+
+// CircuitBreaker stops requests to the k6 API during outages. It opens after
+// consecutive failures or a high error ratio, rejects requests while open and
+// lets a single probe request through once the open timeout passed (half-open).
+// A successful probe closes it, a failed one opens it again. Network errors,
+// timeouts and 5xx responses count as failures, any other response as success.
+type CircuitBreaker struct {
+	config BreakerConfig
+	logger *zap.Logger
+	now    func() time.Time
+
+	mu          sync.Mutex
+	state       string
+	consecutive int
+	outcomes    []bool // Ring buffer of recent outcomes, true for failures
+	next        int
+	openedAt    time.Time
+	probing     bool
+}
+
+// NewCircuitBreaker creates a closed circuit breaker and registers its state metric if a registerer is provided
+func NewCircuitBreaker(cfg BreakerConfig, logger *zap.Logger, reg prometheus.Registerer) *CircuitBreaker {
+	breaker := &CircuitBreaker{
+		config:   cfg,
+		logger:   logger,
+		now:      time.Now,
+		state:    CircuitClosed,
+		outcomes: make([]bool, 0, cfg.Window),
+	}
+
+	if reg != nil {
+		reg.MustRegister(prometheus.NewGaugeFunc(
+			prometheus.GaugeOpts{
+				Name: "k6_exporter_api_circuit_breaker_state",
+				Help: "State of the k6 API circuit breaker: 0 closed, 1 half-open, 2 open",
+			},
+			func() float64 {
+				switch breaker.State() {
+				case CircuitHalfOpen:
+					return 1
+				case CircuitOpen:
+					return 2
+				}
+				return 0
+			},
+		))
+	}
+
+	return breaker
+}
+
+// WithCircuitBreaker guards the requests of the client with the breaker and returns the client
+func (c *Client) WithCircuitBreaker(breaker *CircuitBreaker) *Client {
+	c.breaker = breaker
+	return c
+}
+
+// CircuitState returns the state of the client's circuit breaker, or "" without one
+func (c *Client) CircuitState() string {
+	if c.breaker == nil {
+		return ""
+	}
+	return c.breaker.State()
+}
+
+// State returns the current state. An open circuit whose timeout passed reports half-open.
+func (b *CircuitBreaker) State() string {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if b.state == CircuitOpen && b.now().Sub(b.openedAt) >= b.config.OpenTimeout {
+		return CircuitHalfOpen
+	}
+	return b.state
+}
+
+// Allow returns ErrCircuitOpen if the request must not be sent. Every allowed
+// request must be followed by a call to Record.
+func (b *CircuitBreaker) Allow() error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if b.state == CircuitOpen {
+		if b.now().Sub(b.openedAt) < b.config.OpenTimeout {
+			return ErrCircuitOpen
+		}
+		b.state = CircuitHalfOpen
+		b.logger.Info("k6 API circuit breaker is half-open, probing the API")
+	}
+
+	if b.state == CircuitHalfOpen {
+		if b.probing {
+			return ErrCircuitOpen
+		}
+		b.probing = true
+	}
+	return nil
+}
+
+// Record records the outcome of an allowed request. Requests canceled by the
+// caller are not counted, they say nothing about the API.
+func (b *CircuitBreaker) Record(resp *http.Response, err error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if errors.Is(err, context.Canceled) {
+		b.probing = false
+		return
+	}
+	failed := err != nil || resp.StatusCode >= 500
+
+	if b.state == CircuitHalfOpen {
+		b.probing = false
+		if failed {
+			b.open("probe request failed")
+			return
+		}
+		b.state = CircuitClosed
+		b.consecutive = 0
+		b.outcomes = b.outcomes[:0]
+		b.next = 0
+		b.logger.Info("k6 API circuit breaker is closed")
+		return
+	}
+
+	b.record(failed)
+	if b.state != CircuitClosed {
+		return
+	}
+
+	if b.config.ConsecutiveFailures > 0 && b.consecutive >= b.config.ConsecutiveFailures {
+		b.open("consecutive failures")
+		return
+	}
+	if b.config.ErrorRatio > 0 && len(b.outcomes) == b.config.Window && b.errorRatio() >= b.config.ErrorRatio {
+		b.open("error ratio exceeded")
+	}
+}
+
+// record adds an outcome to the counters. The caller must hold the lock.
+func (b *CircuitBreaker) record(failed bool) {
+	if failed {
+		b.consecutive++
+	} else {
+		b.consecutive = 0
+	}
+
+	if b.config.Window <= 0 {
+		return
+	}
+	if len(b.outcomes) < b.config.Window {
+		b.outcomes = append(b.outcomes, failed)
+		return
+	}
+	b.outcomes[b.next] = failed
+	b.next = (b.next + 1) % b.config.Window
+}
+
+// errorRatio returns the ratio of failures in the window. The caller must hold the lock.
+func (b *CircuitBreaker) errorRatio() float64 {
+	failures := 0
+	for _, failed := range b.outcomes {
+		if failed {
+			failures++
+		}
+	}
+	return float64(failures) / float64(len(b.outcomes))
+}
+
+// open opens the circuit. The caller must hold the lock.
+func (b *CircuitBreaker) open(reason string) {
+	b.state = CircuitOpen
+	b.openedAt = b.now()
+	b.logger.Warn("k6 API circuit breaker is open",
+		zap.String("reason", reason),
+		zap.Duration("open_timeout", b.config.OpenTimeout),
+	)
+}
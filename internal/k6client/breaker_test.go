@@ -0,0 +1,151 @@
+package k6client
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap/zaptest"
+)
+
+var (
+	okResponse     = &http.Response{StatusCode: http.StatusOK}
+	failedResponse = &http.Response{StatusCode: http.StatusBadGateway}
+)
+
+func TestCircuitBreakerConsecutiveFailures(t *testing.T) {
+	now := time.Now()
+	breaker := NewCircuitBreaker(BreakerConfig{ConsecutiveFailures: 3, OpenTimeout: time.Minute}, zaptest.NewLogger(t), nil)
+	breaker.now = func() time.Time { return now }
+
+	// Client errors reset the count, canceled requests are not counted
+	breaker.Record(failedResponse, nil)
+	breaker.Record(&http.Response{StatusCode: http.StatusNotFound}, nil)
+	breaker.Record(failedResponse, nil)
+	breaker.Record(nil, context.Canceled)
+	breaker.Record(failedResponse, nil)
+	assert.Equal(t, CircuitClosed, breaker.State())
+
+	breaker.Record(nil, context.DeadlineExceeded)
+	assert.Equal(t, CircuitOpen, breaker.State())
+	assert.ErrorIs(t, breaker.Allow(), ErrCircuitOpen)
+
+	// After the open timeout a single probe is let through
+	now = now.Add(time.Minute)
+	assert.Equal(t, CircuitHalfOpen, breaker.State())
+	require.NoError(t, breaker.Allow())
+	assert.ErrorIs(t, breaker.Allow(), ErrCircuitOpen)
+
+	// A failed probe opens the circuit again
+	breaker.Record(nil, errors.New("connection refused"))
+	assert.Equal(t, CircuitOpen, breaker.State())
+
+	now = now.Add(time.Minute)
+	require.NoError(t, breaker.Allow())
+	breaker.Record(okResponse, nil)
+	assert.Equal(t, CircuitClosed, breaker.State())
+	assert.NoError(t, breaker.Allow())
+}
+
+func TestCircuitBreakerErrorRatio(t *testing.T) {
+	breaker := NewCircuitBreaker(BreakerConfig{ErrorRatio: 0.5, Window: 4, OpenTimeout: time.Minute}, zaptest.NewLogger(t), nil)
+
+	// The ratio is only evaluated over a full window
+	breaker.Record(failedResponse, nil)
+	breaker.Record(okResponse, nil)
+	breaker.Record(failedResponse, nil)
+	assert.Equal(t, CircuitClosed, breaker.State())
+
+	breaker.Record(okResponse, nil)
+	assert.Equal(t, CircuitOpen, breaker.State())
+}
+
+func TestCircuitBreakerMetric(t *testing.T) {
+	now := time.Now()
+	registry := prometheus.NewRegistry()
+	breaker := NewCircuitBreaker(BreakerConfig{ConsecutiveFailures: 1, OpenTimeout: time.Minute}, zaptest.NewLogger(t), registry)
+	breaker.now = func() time.Time { return now }
+
+	gather := func() float64 {
+		families, err := registry.Gather()
+		require.NoError(t, err)
+		require.Len(t, families, 1)
+		return families[0].GetMetric()[0].GetGauge().GetValue()
+	}
+
+	assert.Equal(t, float64(0), gather())
+	breaker.Record(failedResponse, nil)
+	assert.Equal(t, float64(2), gather())
+	now = now.Add(time.Minute)
+	assert.Equal(t, float64(1), gather())
+	assert.Equal(t, 1, testutil.CollectAndCount(registry, "k6_exporter_api_circuit_breaker_state"))
+}
+
+func TestClientCircuitBreaker(t *testing.T) {
+	var requests atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests.Add(1)
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer server.Close()
+
+	breaker := NewCircuitBreaker(BreakerConfig{ConsecutiveFailures: 2, OpenTimeout: time.Minute}, zaptest.NewLogger(t), nil)
+	client := NewClient(server.URL, "stack", "token", zaptest.NewLogger(t)).WithCircuitBreaker(breaker)
+	assert.Equal(t, CircuitClosed, client.CircuitState())
+
+	for i := 0; i < 2; i++ {
+		_, err := client.ListProjects(context.Background())
+		assert.Equal(t, ErrorClassServerError, ErrorClass(err))
+	}
+	assert.Equal(t, CircuitOpen, client.CircuitState())
+
+	// Requests fail without reaching the API while the circuit is open
+	_, err := client.ListProjects(context.Background())
+	assert.ErrorIs(t, err, ErrCircuitOpen)
+	assert.Equal(t, int32(2), requests.Load())
+}
+
+func TestClientCircuitBreakerInvalidRequest(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"value":[]}`))
+	}))
+	defer server.Close()
+
+	now := time.Now()
+	breaker := NewCircuitBreaker(BreakerConfig{ConsecutiveFailures: 1, OpenTimeout: time.Minute}, zaptest.NewLogger(t), nil)
+	breaker.now = func() time.Time { return now }
+	breaker.Record(failedResponse, nil)
+	now = now.Add(time.Minute)
+	require.Equal(t, CircuitHalfOpen, breaker.State())
+
+	// A request that cannot be built does not use up the probe of the half-open circuit
+	client := NewClient(server.URL, "stack", "token", zaptest.NewLogger(t)).WithCircuitBreaker(breaker)
+	_, err := client.send(context.Background(), http.MethodGet, "http://api.k6.example/\x7f", nil)
+	require.ErrorContains(t, err, "create request")
+
+	_, err = client.ListProjects(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, CircuitClosed, breaker.State())
+}
+
+func TestGetAllTestRunsCircuitOpen(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusBadGateway)
+	}))
+	defer server.Close()
+
+	breaker := NewCircuitBreaker(BreakerConfig{ConsecutiveFailures: 1, OpenTimeout: time.Minute}, zaptest.NewLogger(t), nil)
+	client := NewClient(server.URL, "stack", "token", zaptest.NewLogger(t)).WithCircuitBreaker(breaker)
+
+	// Failing projects are skipped, but not while the circuit is open
+	_, err := client.GetAllTestRuns(context.Background(), []string{"100", "200"}, nil)
+	assert.ErrorIs(t, err, ErrCircuitOpen)
+}
@@ -0,0 +1,240 @@
+package k6client
+
+import (
+	"bytes"
+	"container/list"
+	"fmt"
+	"io"
+	"maps"
+	"net/http"
+	"sync"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// Cache names used as the cache label of the cache metrics
+const (
+	cacheConditional  = "conditional"
+	cacheFinishedRuns = "finished_runs"
+)
+
+// CacheMetrics holds the response cache metrics
+type CacheMetrics struct {
+	HitsTotal   *prometheus.CounterVec
+	MissesTotal *prometheus.CounterVec
+}
+
+// NewCacheMetrics creates the response cache metrics and registers them if a registerer is provided
+func NewCacheMetrics(reg prometheus.Registerer) *CacheMetrics {
+	metrics := &CacheMetrics{
+		HitsTotal: prometheus.NewCounterVec(
+			prometheus.CounterOpts{
+				Name: "k6_exporter_api_cache_hits_total",
+				Help: "Total number of k6 API responses served from the cache, revalidated or not",
+			},
+			[]string{"cache"},
+		),
+		MissesTotal: prometheus.NewCounterVec(
+			prometheus.CounterOpts{
+				Name: "k6_exporter_api_cache_misses_total",
+				Help: "Total number of k6 API responses that had to be downloaded",
+			},
+			[]string{"cache"},
+		),
+	}
+
+	// Initialize the series so they are exported before the first request
+	for _, cache := range []string{cacheConditional, cacheFinishedRuns} {
+		metrics.HitsTotal.WithLabelValues(cache)
+		metrics.MissesTotal.WithLabelValues(cache)
+	}
+
+	if reg != nil {
+		reg.MustRegister(metrics.HitsTotal, metrics.MissesTotal)
+	}
+
+	return metrics
+}
+
+// cachedResponse is a response body with the validators it was served with
+type cachedResponse struct {
+	etag         string
+	lastModified string
+	body         []byte
+}
+
+// runKey identifies a test run resource
+type runKey struct {
+	testID int
+	runID  int
+}
+
+// ResponseCache caches k6 API responses of a client. GET responses with an ETag or
+// Last-Modified header are revalidated with If-None-Match and If-Modified-Since, a
+// 304 response is answered from the cache. Every page of a paginated list is cached
+// under its own URL, so the next link always comes from the body its validator covers.
+// Finished test runs never change and are served without a request.
+type ResponseCache struct {
+	responses    *lru[string, *cachedResponse]
+	finishedRuns *lru[runKey, TestRun]
+	metrics      *CacheMetrics
+}
+
+// NewResponseCache creates a response cache holding up to size responses and size finished runs
+func NewResponseCache(size int, reg prometheus.Registerer) *ResponseCache {
+	return &ResponseCache{
+		responses:    newLRU[string, *cachedResponse](size),
+		finishedRuns: newLRU[runKey, TestRun](size),
+		metrics:      NewCacheMetrics(reg),
+	}
+}
+
+// WithCache caches the responses of the client and returns the client
+func (c *Client) WithCache(cache *ResponseCache) *Client {
+	c.cache = cache
+	return c
+}
+
+// conditionalHeaders returns the validators of the cached response for the URL
+func (rc *ResponseCache) conditionalHeaders(rawURL string) http.Header {
+	cached, ok := rc.responses.get(rawURL)
+	if !ok {
+		return nil
+	}
+	header := http.Header{}
+	if cached.etag != "" {
+		header.Set("If-None-Match", cached.etag)
+	}
+	if cached.lastModified != "" {
+		header.Set("If-Modified-Since", cached.lastModified)
+	}
+	return header
+}
+
+// handle answers a 304 response from the cache and stores cacheable 200 responses.
+// It returns the response to use in place of resp.
+func (rc *ResponseCache) handle(rawURL string, resp *http.Response) (*http.Response, error) {
+	switch resp.StatusCode {
+	case http.StatusNotModified:
+		cached, ok := rc.responses.get(rawURL)
+		if !ok {
+			// Evicted since the request was sent, the caller retries without validators
+			return resp, nil
+		}
+		io.Copy(io.Discard, resp.Body)
+		resp.Body.Close()
+
+		rc.metrics.HitsTotal.WithLabelValues(cacheConditional).Inc()
+		resp.StatusCode = http.StatusOK
+		resp.Status = http.StatusText(http.StatusOK)
+		resp.Body = io.NopCloser(bytes.NewReader(cached.body))
+		return resp, nil
+
+	case http.StatusOK:
+		rc.metrics.MissesTotal.WithLabelValues(cacheConditional).Inc()
+		etag, lastModified := resp.Header.Get("ETag"), resp.Header.Get("Last-Modified")
+		if etag == "" && lastModified == "" {
+			return resp, nil
+		}
+
+		body, err := io.ReadAll(resp.Body)
+		resp.Body.Close()
+		if err != nil {
+			return nil, fmt.Errorf("read response: %w", err)
+		}
+		rc.responses.add(rawURL, &cachedResponse{etag: etag, lastModified: lastModified, body: body})
+		resp.Body = io.NopCloser(bytes.NewReader(body))
+		return resp, nil
+	}
+
+	return resp, nil
+}
+
+// finishedRun returns a copy of a cached finished test run
+func (rc *ResponseCache) finishedRun(testID, runID int) (*TestRun, bool) {
+	run, ok := rc.finishedRuns.get(runKey{testID: testID, runID: runID})
+	if !ok {
+		rc.metrics.MissesTotal.WithLabelValues(cacheFinishedRuns).Inc()
+		return nil, false
+	}
+	rc.metrics.HitsTotal.WithLabelValues(cacheFinishedRuns).Inc()
+	run.StatusDetails = maps.Clone(run.StatusDetails)
+	return &run, true
+}
+
+// storeRun caches a copy of the test run if it is finished
+func (rc *ResponseCache) storeRun(run TestRun) {
+	if !IsTerminalStatus(run.Status) {
+		return
+	}
+	// Callers annotate the status details of the runs they get
+	run.StatusDetails = maps.Clone(run.StatusDetails)
+	rc.finishedRuns.add(runKey{testID: run.TestID, runID: run.ID}, run)
+}
+
+// lru is a fixed size map that evicts the least recently used entry
+type lru[K comparable, V any] struct {
+	size int
+
+	mu      sync.Mutex
+	order   *list.List // Front is the most recently used
+	entries map[K]*list.Element
+}
+
+// lruEntry is an element of the lru order list
+type lruEntry[K comparable, V any] struct {
+	key   K
+	value V
+}
+
+func newLRU[K comparable, V any](size int) *lru[K, V] {
+	return &lru[K, V]{
+		size:    size,
+		order:   list.New(),
+		entries: make(map[K]*list.Element),
+	}
+}
+
+// get returns the value of the key and marks it as recently used
+func (l *lru[K, V]) get(key K) (V, bool) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	element, ok := l.entries[key]
+	if !ok {
+		var zero V
+		return zero, false
+	}
+	l.order.MoveToFront(element)
+	return element.Value.(*lruEntry[K, V]).value, true
+}
+
+// add stores the value, evicting the least recently used entry if the cache is full
+func (l *lru[K, V]) add(key K, value V) {
+	if l.size <= 0 {
+		return
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if element, ok := l.entries[key]; ok {
+		element.Value.(*lruEntry[K, V]).value = value
+		l.order.MoveToFront(element)
+		return
+	}
+
+	l.entries[key] = l.order.PushFront(&lruEntry[K, V]{key: key, value: value})
+	if l.order.Len() > l.size {
+		oldest := l.order.Back()
+		l.order.Remove(oldest)
+		delete(l.entries, oldest.Value.(*lruEntry[K, V]).key)
+	}
+}
+
+// len returns the number of entries
+func (l *lru[K, V]) len() int {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return l.order.Len()
+}
@@ -0,0 +1,135 @@
+package k6client
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap/zaptest"
+)
+
+func TestClientConditionalRequests(t *testing.T) {
+	var downloads atomic.Int32
+	version := "v1"
+	var server *httptest.Server
+	server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		page := r.URL.Query().Get("page")
+		etag := fmt.Sprintf(`"%s-%s"`, version, page)
+		if r.Header.Get("If-None-Match") == etag {
+			w.WriteHeader(http.StatusNotModified)
+			return
+		}
+
+		downloads.Add(1)
+		w.Header().Set("ETag", etag)
+		if page == "" {
+			fmt.Fprintf(w, `{"value":[{"id":1,"name":"%s"}],"next":"%s/cloud/v6/load_tests?page=2"}`, version, server.URL)
+			return
+		}
+		fmt.Fprintf(w, `{"value":[{"id":2,"name":"%s"}]}`, version)
+	}))
+	defer server.Close()
+
+	registry := prometheus.NewRegistry()
+	cache := NewResponseCache(10, registry)
+	client := NewClient(server.URL, "stack", "token", zaptest.NewLogger(t)).WithCache(cache)
+
+	tests, err := client.ListTests(context.Background(), nil)
+	require.NoError(t, err)
+	require.Len(t, tests, 2)
+	assert.Equal(t, int32(2), downloads.Load())
+	assert.Equal(t, 2, cache.responses.len(), "every page is cached under its own URL")
+
+	// Unchanged pages are revalidated and served from the cache
+	tests, err = client.ListTests(context.Background(), nil)
+	require.NoError(t, err)
+	require.Len(t, tests, 2)
+	assert.Equal(t, "v1", tests[1].Name)
+	assert.Equal(t, int32(2), downloads.Load())
+	assert.Equal(t, float64(2), testutil.ToFloat64(cache.metrics.HitsTotal.WithLabelValues(cacheConditional)))
+	assert.Equal(t, float64(2), testutil.ToFloat64(cache.metrics.MissesTotal.WithLabelValues(cacheConditional)))
+
+	// Changed pages are downloaded again
+	version = "v2"
+	tests, err = client.ListTests(context.Background(), nil)
+	require.NoError(t, err)
+	assert.Equal(t, "v2", tests[0].Name)
+	assert.Equal(t, "v2", tests[1].Name)
+	assert.Equal(t, int32(4), downloads.Load())
+}
+
+func TestClientCachesFinishedRuns(t *testing.T) {
+	var requests atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests.Add(1)
+		switch r.URL.Path {
+		case "/cloud/v6/load_tests/1/test_runs/10":
+			w.Write([]byte(`{"id":10,"test_id":1,"status":"completed"}`))
+		case "/cloud/v6/load_tests/1/test_runs/11":
+			w.Write([]byte(`{"id":11,"test_id":1,"status":"running"}`))
+		case "/cloud/v6/load_tests/1/test_runs":
+			w.Write([]byte(`{"value":[{"id":12,"test_id":1,"status":"aborted","status_details":{"type":"aborted_user"}}]}`))
+		}
+	}))
+	defer server.Close()
+
+	cache := NewResponseCache(10, nil)
+	client := NewClient(server.URL, "stack", "token", zaptest.NewLogger(t)).WithCache(cache)
+	ctx := context.Background()
+
+	for i := 0; i < 2; i++ {
+		run, err := client.GetTestRun(ctx, 1, 10)
+		require.NoError(t, err)
+		assert.Equal(t, StatusCompleted, run.Status)
+	}
+	assert.Equal(t, int32(1), requests.Load())
+
+	// Active runs change and are always fetched
+	for i := 0; i < 2; i++ {
+		_, err := client.GetTestRun(ctx, 1, 11)
+		require.NoError(t, err)
+	}
+	assert.Equal(t, int32(3), requests.Load())
+
+	// Finished runs of a list are cached too, callers may annotate their copy
+	runs, err := client.ListTestRuns(ctx, 1, nil)
+	require.NoError(t, err)
+	runs[0].StatusDetails["test_name"] = "annotated"
+	run, err := client.GetTestRun(ctx, 1, 12)
+	require.NoError(t, err)
+	assert.Equal(t, map[string]interface{}{"type": "aborted_user"}, run.StatusDetails)
+	assert.Equal(t, int32(4), requests.Load())
+
+	assert.Equal(t, float64(2), testutil.ToFloat64(cache.metrics.HitsTotal.WithLabelValues(cacheFinishedRuns)))
+	assert.Equal(t, float64(3), testutil.ToFloat64(cache.metrics.MissesTotal.WithLabelValues(cacheFinishedRuns)))
+}
+
+func TestLRUEviction(t *testing.T) {
+	cache := newLRU[string, int](2)
+	cache.add("a", 1)
+	cache.add("b", 2)
+
+	// Reading a marks it as recently used, so b is evicted
+	_, ok := cache.get("a")
+	require.True(t, ok)
+	cache.add("c", 3)
+
+	_, ok = cache.get("b")
+	assert.False(t, ok)
+	value, ok := cache.get("a")
+	assert.True(t, ok)
+	assert.Equal(t, 1, value)
+	assert.Equal(t, 2, cache.len())
+
+	// A size of zero disables the cache
+	disabled := newLRU[string, int](0)
+	disabled.add("a", 1)
+	assert.Equal(t, 0, disabled.len())
+}
@@ -3,6 +3,7 @@ package k6client
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"net/http"
@@ -16,8 +17,12 @@ import (
 type Client struct {
 	baseURL    string
 	apiToken   string
+	tokenFile  *TokenFile // Takes precedence over apiToken if set
 	stackID    string
 	httpClient *http.Client
+	limiter    *RateLimiter    // Optional, shared by all requests
+	breaker    *CircuitBreaker // Optional, shared by all requests
+	cache      *ResponseCache  // Optional
 	logger     *zap.Logger
 }
 
@@ -34,6 +39,21 @@ func NewClient(baseURL, stackID, apiToken string, logger *zap.Logger) *Client {
 	}
 }
 
+// NewClientWithTokenFile creates a new k6 API client that reads its token from a file
+func NewClientWithTokenFile(baseURL, stackID string, tokenFile *TokenFile, logger *zap.Logger) *Client {
+	client := NewClient(baseURL, stackID, "", logger)
+	client.tokenFile = tokenFile
+	return client
+}
+
+// token returns the current API token
+func (c *Client) token() string {
+	if c.tokenFile != nil {
+		return c.tokenFile.Token()
+	}
+	return c.apiToken
+}
+
 // doRequest performs an HTTP request with authentication
 func (c *Client) doRequest(ctx context.Context, method, path string, params url.Values) (*http.Response, error) {
 	u, err := url.Parse(c.baseURL + path)
@@ -45,35 +65,102 @@ func (c *Client) doRequest(ctx context.Context, method, path string, params url.
 		u.RawQuery = params.Encode()
 	}
 
-	req, err := http.NewRequestWithContext(ctx, method, u.String(), nil)
-	if err != nil {
-		return nil, fmt.Errorf("create request: %w", err)
-	}
-
-	req.Header.Set("Authorization", "Bearer "+c.apiToken)
-	req.Header.Set("Content-Type", "application/json")
-	req.Header.Set("Accept", "application/json")
-	req.Header.Set("X-Stack-Id", c.stackID)
-
 	c.logger.Debug("making API request",
 		zap.String("method", method),
 		zap.String("url", u.String()),
 	)
 
-	resp, err := c.httpClient.Do(req)
+	// Revalidate cached responses instead of downloading them again
+	var conditional http.Header
+	cacheable := c.cache != nil && method == http.MethodGet
+	if cacheable {
+		conditional = c.cache.conditionalHeaders(u.String())
+	}
+
+	resp, err := c.send(ctx, method, u.String(), conditional)
 	if err != nil {
-		return nil, fmt.Errorf("perform request: %w", err)
+		return nil, err
+	}
+
+	// The token may have been rotated without the file changing its modification time yet,
+	// retry once if rereading the file yields a different token
+	if resp.StatusCode == http.StatusUnauthorized && c.tokenFile != nil && c.tokenFile.Reload() {
+		io.Copy(io.Discard, resp.Body)
+		resp.Body.Close()
+
+		c.logger.Info("retrying API request with the reloaded token", zap.String("url", u.String()))
+		if resp, err = c.send(ctx, method, u.String(), conditional); err != nil {
+			return nil, err
+		}
+	}
+
+	if cacheable {
+		if resp, err = c.cache.handle(u.String(), resp); err != nil {
+			return nil, err
+		}
+
+		// The cached response was evicted meanwhile, download it again
+		if resp.StatusCode == http.StatusNotModified {
+			io.Copy(io.Discard, resp.Body)
+			resp.Body.Close()
+			if resp, err = c.send(ctx, method, u.String(), nil); err != nil {
+				return nil, err
+			}
+			if resp, err = c.cache.handle(u.String(), resp); err != nil {
+				return nil, err
+			}
+		}
 	}
 
 	if resp.StatusCode >= 400 {
 		body, _ := io.ReadAll(resp.Body)
 		resp.Body.Close()
-		return nil, fmt.Errorf("API error: %s (status %d): %s", resp.Status, resp.StatusCode, string(body))
+		return nil, &APIError{StatusCode: resp.StatusCode, Status: resp.Status, Body: string(body)}
 	}
 
 	return resp, nil
 }
 
+// send performs a single authenticated request with the extra headers, waiting for the
+// rate limiter first. While the circuit breaker is open it fails with ErrCircuitOpen.
+func (c *Client) send(ctx context.Context, method, rawURL string, header http.Header) (*http.Response, error) {
+	if c.limiter != nil {
+		if err := c.limiter.Wait(ctx); err != nil {
+			return nil, fmt.Errorf("wait for rate limiter: %w", err)
+		}
+	}
+	req, err := http.NewRequestWithContext(ctx, method, rawURL, nil)
+	if err != nil {
+		return nil, fmt.Errorf("create request: %w", err)
+	}
+
+	req.Header.Set("Authorization", "Bearer "+c.token())
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Accept", "application/json")
+	req.Header.Set("X-Stack-Id", c.stackID)
+	for name, values := range header {
+		req.Header[name] = values
+	}
+
+	// Admitted requests must be recorded, or a half-open breaker waits for its probe forever
+	if c.breaker != nil {
+		if err := c.breaker.Allow(); err != nil {
+			return nil, err
+		}
+	}
+	resp, err := c.httpClient.Do(req)
+	if c.breaker != nil {
+		c.breaker.Record(resp, err)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("perform request: %w", err)
+	}
+	if c.limiter != nil {
+		c.limiter.Observe(resp)
+	}
+	return resp, nil
+}
+
 // ListProjects lists all projects
 func (c *Client) ListProjects(ctx context.Context) ([]Project, error) {
 	var allProjects []Project
@@ -195,6 +282,7 @@ func (c *Client) ListTestRuns(ctx context.Context, testID int, since *time.Time)
 
 		if firstPage {
 			params := url.Values{}
+			params.Set("$orderby", "created desc")
 			resp, err = c.doRequest(ctx, http.MethodGet, nextURL, params)
 			firstPage = false
 		} else {
@@ -212,6 +300,12 @@ func (c *Client) ListTestRuns(ctx context.Context, testID int, since *time.Time)
 			return nil, fmt.Errorf("decode response: %w", err)
 		}
 
+		if c.cache != nil {
+			for _, run := range result.Value {
+				c.cache.storeRun(run)
+			}
+		}
+
 		// Filter by since time if provided
 		for _, run := range result.Value {
 			if since == nil || run.Created.After(*since) {
@@ -247,8 +341,42 @@ func (c *Client) ListTestRuns(ctx context.Context, testID int, since *time.Time)
 	return allRuns, nil
 }
 
-// GetTestRun gets a specific test run
+// GetLatestTestRun gets the most recently created run of a test, or nil if the test has no runs
+func (c *Client) GetLatestTestRun(ctx context.Context, testID int) (*TestRun, error) {
+	path := fmt.Sprintf("/cloud/v6/load_tests/%d/test_runs", testID)
+	params := url.Values{}
+	params.Set("$orderby", "created desc")
+	params.Set("$top", "1")
+
+	resp, err := c.doRequest(ctx, http.MethodGet, path, params)
+	if err != nil {
+		return nil, fmt.Errorf("get latest test run for test %d: %w", testID, err)
+	}
+	defer resp.Body.Close()
+
+	var result TestRunListResponse
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		return nil, fmt.Errorf("decode response: %w", err)
+	}
+	if len(result.Value) == 0 {
+		return nil, nil
+	}
+
+	run := result.Value[0]
+	if c.cache != nil {
+		c.cache.storeRun(run)
+	}
+	return &run, nil
+}
+
+// GetTestRun gets a specific test run. Finished runs are served from the cache if configured.
 func (c *Client) GetTestRun(ctx context.Context, testID, runID int) (*TestRun, error) {
+	if c.cache != nil {
+		if run, ok := c.cache.finishedRun(testID, runID); ok {
+			return run, nil
+		}
+	}
+
 	path := fmt.Sprintf("/cloud/v6/load_tests/%d/test_runs/%d", testID, runID)
 
 	resp, err := c.doRequest(ctx, http.MethodGet, path, nil)
@@ -261,15 +389,41 @@ func (c *Client) GetTestRun(ctx context.Context, testID, runID int) (*TestRun, e
 	if err := json.NewDecoder(resp.Body).Decode(&testRun); err != nil {
 		return nil, fmt.Errorf("decode response: %w", err)
 	}
+	if c.cache != nil {
+		c.cache.storeRun(testRun)
+	}
 
 	return &testRun, nil
 }
 
-// GetAllTestRuns fetches all test runs for all tests in the specified projects
+// AbortTestRun aborts a running test run
+func (c *Client) AbortTestRun(ctx context.Context, runID int) error {
+	path := fmt.Sprintf("/cloud/v6/test_runs/%d/abort", runID)
+
+	resp, err := c.doRequest(ctx, http.MethodPost, path, nil)
+	if err != nil {
+		return fmt.Errorf("abort test run %d: %w", runID, err)
+	}
+	resp.Body.Close()
+
+	return nil
+}
+
+// failsFetch returns true for errors that fail every other request of a fetch too, so
+// GetAllTestRuns returns them instead of skipping the project or test
+func failsFetch(err error) bool {
+	return errors.Is(err, ErrCircuitOpen) || ErrorClass(err) == ErrorClassUnauthorized
+}
+
+// GetAllTestRuns fetches all test runs for all tests in the specified projects.
+// Failures of single projects and tests are skipped, unless all of them fail or
+// the circuit is open or the token is rejected.
 func (c *Client) GetAllTestRuns(ctx context.Context, projectIDs []string, since *time.Time) ([]TestRun, error) {
 	// First, get all tests
 	var tests []Test
 	var err error
+	var lastErr error
+	listed := 0
 
 	if len(projectIDs) > 0 {
 		// Fetch tests for each specified project
@@ -281,14 +435,25 @@ func (c *Client) GetAllTestRuns(ctx context.Context, projectIDs []string, since
 			}
 			projectTests, err := c.ListTests(ctx, &pid)
 			if err != nil {
+				err = fmt.Errorf("list tests for project %d: %w", pid, err)
+				if failsFetch(err) {
+					return nil, err
+				}
 				c.logger.Error("failed to list tests for project",
 					zap.Int("project_id", pid),
 					zap.Error(err),
 				)
+				lastErr = err
 				continue
 			}
+			listed++
 			tests = append(tests, projectTests...)
 		}
+
+		// A fetch that could not list any project did not succeed
+		if listed == 0 && lastErr != nil {
+			return nil, lastErr
+		}
 	} else {
 		// Fetch all tests
 		tests, err = c.ListTests(ctx, nil)
@@ -299,14 +464,21 @@ func (c *Client) GetAllTestRuns(ctx context.Context, projectIDs []string, since
 
 	// Now fetch test runs for each test
 	var allRuns []TestRun
+	runErrors := 0
 	for _, test := range tests {
 		runs, err := c.ListTestRuns(ctx, test.ID, since)
 		if err != nil {
+			// Skipping every test would report a fetch without runs as successful
+			if failsFetch(err) {
+				return nil, err
+			}
 			c.logger.Error("failed to list test runs",
 				zap.Int("test_id", test.ID),
 				zap.String("test_name", test.Name),
 				zap.Error(err),
 			)
+			runErrors++
+			lastErr = err
 			continue
 		}
 
@@ -322,6 +494,10 @@ func (c *Client) GetAllTestRuns(ctx context.Context, projectIDs []string, since
 		allRuns = append(allRuns, runs...)
 	}
 
+	if len(tests) > 0 && runErrors == len(tests) {
+		return nil, lastErr
+	}
+
 	c.logger.Info("fetched all test runs",
 		zap.Int("test_count", len(tests)),
 		zap.Int("run_count", len(allRuns)),
@@ -330,3 +506,39 @@ func (c *Client) GetAllTestRuns(ctx context.Context, projectIDs []string, since
 
 	return allRuns, nil
 }
+
+// ListSchedules lists all test schedules
+func (c *Client) ListSchedules(ctx context.Context) ([]Schedule, error) {
+	var allSchedules []Schedule
+	nextURL := "/cloud/v6/schedules"
+
+	for nextURL != "" {
+		resp, err := c.doRequest(ctx, http.MethodGet, nextURL, nil)
+		if err != nil {
+			return nil, fmt.Errorf("list schedules: %w", err)
+		}
+		defer resp.Body.Close()
+
+		var result ScheduleListResponse
+		if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+			return nil, fmt.Errorf("decode response: %w", err)
+		}
+
+		allSchedules = append(allSchedules, result.Value...)
+
+		// Check if there's a next page
+		if result.Next != nil && *result.Next != "" {
+			// Extract path from next URL
+			u, err := url.Parse(*result.Next)
+			if err != nil {
+				return nil, fmt.Errorf("parse next URL: %w", err)
+			}
+			nextURL = u.Path + "?" + u.RawQuery
+		} else {
+			nextURL = ""
+		}
+	}
+
+	c.logger.Debug("listed schedules", zap.Int("count", len(allSchedules)))
+	return allSchedules, nil
+}
@@ -370,6 +370,30 @@ func TestGetTestRun(t *testing.T) {
 	}
 }
 
+func TestGetLatestTestRun(t *testing.T) {
+	runs := []TestRun{}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/cloud/v6/load_tests/1/test_runs", r.URL.Path)
+		assert.Equal(t, "created desc", r.URL.Query().Get("$orderby"))
+		assert.Equal(t, "1", r.URL.Query().Get("$top"))
+		json.NewEncoder(w).Encode(TestRunListResponse{Value: runs})
+	}))
+	defer server.Close()
+
+	client := NewClient(server.URL, "stack", "token", zaptest.NewLogger(t))
+
+	// Tests without runs have no latest run
+	run, err := client.GetLatestTestRun(context.Background(), 1)
+	require.NoError(t, err)
+	assert.Nil(t, run)
+
+	runs = []TestRun{{ID: 100, TestID: 1, Status: StatusCompleted, Created: time.Now().Add(-72 * time.Hour)}}
+	run, err = client.GetLatestTestRun(context.Background(), 1)
+	require.NoError(t, err)
+	require.NotNil(t, run)
+	assert.Equal(t, 100, run.ID)
+}
+
 func TestGetAllTestRuns(t *testing.T) {
 	now := time.Now()
 
@@ -427,6 +451,61 @@ func TestGetAllTestRuns(t *testing.T) {
 			wantRuns: 3,
 			wantErr:  false,
 		},
+		{
+			name:       "failing_project_skipped",
+			projectIDs: []string{"1", "2"},
+			serverResponse: func(callCount int) http.HandlerFunc {
+				return func(w http.ResponseWriter, r *http.Request) {
+					switch r.URL.Path {
+					case "/cloud/v6/projects/1/load_tests":
+						w.WriteHeader(http.StatusInternalServerError)
+					case "/cloud/v6/projects/2/load_tests":
+						json.NewEncoder(w).Encode(TestListResponse{Value: []Test{{ID: 2, Name: "Test 2", ProjectID: 2}}})
+					case "/cloud/v6/load_tests/2/test_runs":
+						json.NewEncoder(w).Encode(TestRunListResponse{Value: []TestRun{{ID: 3, TestID: 2, Status: "created", Created: now}}})
+					}
+				}
+			},
+			wantRuns: 1,
+			wantErr:  false,
+		},
+		{
+			name:       "all_projects_failing",
+			projectIDs: []string{"1", "2"},
+			serverResponse: func(callCount int) http.HandlerFunc {
+				return func(w http.ResponseWriter, r *http.Request) {
+					w.WriteHeader(http.StatusInternalServerError)
+				}
+			},
+			wantErr: true,
+		},
+		{
+			name:       "unauthorized",
+			projectIDs: []string{"1", "2"},
+			serverResponse: func(callCount int) http.HandlerFunc {
+				return func(w http.ResponseWriter, r *http.Request) {
+					if r.URL.Path == "/cloud/v6/projects/2/load_tests" {
+						t.Error("no further requests after the token is rejected")
+					}
+					w.WriteHeader(http.StatusUnauthorized)
+				}
+			},
+			wantErr: true,
+		},
+		{
+			name:       "all_tests_failing",
+			projectIDs: []string{"1"},
+			serverResponse: func(callCount int) http.HandlerFunc {
+				return func(w http.ResponseWriter, r *http.Request) {
+					if r.URL.Path == "/cloud/v6/projects/1/load_tests" {
+						json.NewEncoder(w).Encode(TestListResponse{Value: []Test{{ID: 1, Name: "Test 1", ProjectID: 1}, {ID: 2, Name: "Test 2", ProjectID: 1}}})
+						return
+					}
+					w.WriteHeader(http.StatusBadGateway)
+				}
+			},
+			wantErr: true,
+		},
 		{
 			name:       "invalid_project_id",
 			projectIDs: []string{"invalid"},
@@ -466,6 +545,85 @@ func TestGetAllTestRuns(t *testing.T) {
 	}
 }
 
+func TestListSchedules(t *testing.T) {
+	nextRun := time.Now().Add(2 * time.Hour).Truncate(time.Second)
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/cloud/v6/schedules", r.URL.Path)
+
+		if r.URL.Query().Get("page") == "2" {
+			resp := ScheduleListResponse{
+				Count: 1,
+				Value: []Schedule{
+					{ID: 2, LoadTestID: 20, Deactivated: true},
+				},
+			}
+			json.NewEncoder(w).Encode(resp)
+			return
+		}
+
+		next := fmt.Sprintf("http://%s/cloud/v6/schedules?page=2", r.Host)
+		resp := ScheduleListResponse{
+			Count: 2,
+			Next:  &next,
+			Value: []Schedule{
+				{
+					ID:             1,
+					LoadTestID:     10,
+					NextRun:        &nextRun,
+					RecurrenceRule: &RecurrenceRule{Frequency: "DAILY", Interval: 1},
+				},
+			},
+		}
+		json.NewEncoder(w).Encode(resp)
+	}))
+	defer server.Close()
+
+	logger := zaptest.NewLogger(t)
+	client := NewClient(server.URL, "test-stack-id", "test-token", logger)
+
+	schedules, err := client.ListSchedules(context.Background())
+	require.NoError(t, err)
+	require.Len(t, schedules, 2)
+	assert.Equal(t, 10, schedules[0].LoadTestID)
+	require.NotNil(t, schedules[0].NextRun)
+	assert.True(t, nextRun.Equal(*schedules[0].NextRun))
+	assert.True(t, schedules[1].Deactivated)
+}
+
+func TestAbortTestRun(t *testing.T) {
+	tests := []struct {
+		name       string
+		statusCode int
+		wantErr    bool
+	}{
+		{name: "aborted", statusCode: http.StatusNoContent, wantErr: false},
+		{name: "forbidden", statusCode: http.StatusForbidden, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				assert.Equal(t, http.MethodPost, r.Method)
+				assert.Equal(t, "/cloud/v6/test_runs/42/abort", r.URL.Path)
+				assert.Equal(t, "Bearer guard-token", r.Header.Get("Authorization"))
+				w.WriteHeader(tt.statusCode)
+			}))
+			defer server.Close()
+
+			logger := zaptest.NewLogger(t)
+			client := NewClient(server.URL, "test-stack-id", "guard-token", logger)
+
+			err := client.AbortTestRun(context.Background(), 42)
+			if tt.wantErr {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
+
 // Helper function
 func intPtr(i int) *int {
 	return &i
@@ -0,0 +1,88 @@
+package k6client
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+)
+
+// APIError is returned when the k6 API responds with an error status
+type APIError struct {
+	StatusCode int
+	Status     string
+	Body       string
+}
+
+// Error implements the error interface
+func (e *APIError) Error() string {
+	return fmt.Sprintf("API error: %s (status %d): %s", e.Status, e.StatusCode, e.Body)
+}
+
+// Error classes returned by ErrorClass
+const (
+	ErrorClassUnauthorized = "unauthorized"
+	ErrorClassNotFound     = "not_found"
+	ErrorClassRateLimited  = "rate_limited"
+	ErrorClassClientError  = "client_error"
+	ErrorClassServerError  = "server_error"
+	ErrorClassTimeout      = "timeout"
+	ErrorClassCanceled     = "canceled"
+	ErrorClassNetwork      = "network"
+	ErrorClassDecode       = "decode"
+	ErrorClassCircuitOpen  = "circuit_open"
+	ErrorClassUnknown      = "unknown"
+)
+
+// ErrorClass returns a coarse classification of an error returned by the client,
+// suitable for metric labels and health reports. It returns "" for a nil error.
+func ErrorClass(err error) string {
+	if err == nil {
+		return ""
+	}
+
+	if errors.Is(err, ErrCircuitOpen) {
+		return ErrorClassCircuitOpen
+	}
+
+	var apiErr *APIError
+	if errors.As(err, &apiErr) {
+		switch {
+		case apiErr.StatusCode == http.StatusUnauthorized || apiErr.StatusCode == http.StatusForbidden:
+			return ErrorClassUnauthorized
+		case apiErr.StatusCode == http.StatusNotFound:
+			return ErrorClassNotFound
+		case apiErr.StatusCode == http.StatusTooManyRequests:
+			return ErrorClassRateLimited
+		case apiErr.StatusCode >= 500:
+			return ErrorClassServerError
+		default:
+			return ErrorClassClientError
+		}
+	}
+
+	if errors.Is(err, context.DeadlineExceeded) {
+		return ErrorClassTimeout
+	}
+	if errors.Is(err, context.Canceled) {
+		return ErrorClassCanceled
+	}
+
+	var netErr net.Error
+	if errors.As(err, &netErr) {
+		if netErr.Timeout() {
+			return ErrorClassTimeout
+		}
+		return ErrorClassNetwork
+	}
+
+	var syntaxErr *json.SyntaxError
+	var typeErr *json.UnmarshalTypeError
+	if errors.As(err, &syntaxErr) || errors.As(err, &typeErr) {
+		return ErrorClassDecode
+	}
+
+	return ErrorClassUnknown
+}
@@ -0,0 +1,65 @@
+package k6client
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap/zaptest"
+)
+
+func TestErrorClass(t *testing.T) {
+	var syntaxErr *json.SyntaxError
+	decodeErr := json.Unmarshal([]byte("{"), &struct{}{})
+	require.ErrorAs(t, decodeErr, &syntaxErr)
+
+	tests := []struct {
+		name string
+		err  error
+		want string
+	}{
+		{name: "nil", err: nil, want: ""},
+		{name: "unauthorized", err: &APIError{StatusCode: http.StatusUnauthorized}, want: ErrorClassUnauthorized},
+		{name: "forbidden", err: &APIError{StatusCode: http.StatusForbidden}, want: ErrorClassUnauthorized},
+		{name: "not_found", err: &APIError{StatusCode: http.StatusNotFound}, want: ErrorClassNotFound},
+		{name: "rate_limited", err: &APIError{StatusCode: http.StatusTooManyRequests}, want: ErrorClassRateLimited},
+		{name: "bad_request", err: &APIError{StatusCode: http.StatusBadRequest}, want: ErrorClassClientError},
+		{name: "server_error", err: fmt.Errorf("list tests: %w", &APIError{StatusCode: http.StatusBadGateway}), want: ErrorClassServerError},
+		{name: "deadline", err: fmt.Errorf("perform request: %w", context.DeadlineExceeded), want: ErrorClassTimeout},
+		{name: "canceled", err: context.Canceled, want: ErrorClassCanceled},
+		{name: "network", err: &net.OpError{Op: "dial", Err: fmt.Errorf("connection refused")}, want: ErrorClassNetwork},
+		{name: "decode", err: fmt.Errorf("decode response: %w", decodeErr), want: ErrorClassDecode},
+		{name: "circuit_open", err: fmt.Errorf("list tests: %w", ErrCircuitOpen), want: ErrorClassCircuitOpen},
+		{name: "unknown", err: fmt.Errorf("something else"), want: ErrorClassUnknown},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, ErrorClass(tt.err))
+		})
+	}
+}
+
+func TestClientReturnsAPIError(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusUnauthorized)
+		w.Write([]byte(`{"error":"invalid token"}`))
+	}))
+	defer server.Close()
+
+	client := NewClient(server.URL, "test-stack-id", "bad-token", zaptest.NewLogger(t))
+
+	_, err := client.ListProjects(context.Background())
+	require.Error(t, err)
+
+	var apiErr *APIError
+	require.ErrorAs(t, err, &apiErr)
+	assert.Equal(t, http.StatusUnauthorized, apiErr.StatusCode)
+	assert.Equal(t, ErrorClassUnauthorized, ErrorClass(err))
+}
@@ -10,6 +10,9 @@ type ClientInterface interface {
 	ListProjects(ctx context.Context) ([]Project, error)
 	ListTests(ctx context.Context, projectID *int) ([]Test, error)
 	ListTestRuns(ctx context.Context, testID int, since *time.Time) ([]TestRun, error)
+	GetLatestTestRun(ctx context.Context, testID int) (*TestRun, error)
 	GetTestRun(ctx context.Context, testID, runID int) (*TestRun, error)
 	GetAllTestRuns(ctx context.Context, projectIDs []string, since *time.Time) ([]TestRun, error)
+	ListSchedules(ctx context.Context) ([]Schedule, error)
+	AbortTestRun(ctx context.Context, runID int) error
 }
\ No newline at end of file
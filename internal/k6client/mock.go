@@ -12,20 +12,28 @@ type MockClient struct {
 	Projects []Project
 	Tests    []Test
 	TestRuns map[int][]TestRun // Key is test ID
+	Schedules []Schedule
+	AbortedRuns []int
 
 	// Error simulation
 	ListProjectsError  error
 	ListTestsError     error
 	ListTestRunsError  error
+	GetLatestTestRunError error
 	GetTestRunError    error
 	GetAllTestRunsError error
+	ListSchedulesError  error
+	AbortTestRunError   error
 
 	// Call tracking
 	ListProjectsCalled    int
 	ListTestsCalled       int
 	ListTestRunsCalled    int
+	GetLatestTestRunCalled int
 	GetTestRunCalled      int
 	GetAllTestRunsCalled  int
+	ListSchedulesCalled   int
+	AbortTestRunCalled    int
 }
 
 // NewMockClient creates a new mock client
@@ -93,6 +101,23 @@ func (m *MockClient) ListTestRuns(ctx context.Context, testID int, since *time.T
 	return filtered, nil
 }
 
+// GetLatestTestRun mock implementation
+func (m *MockClient) GetLatestTestRun(ctx context.Context, testID int) (*TestRun, error) {
+	m.GetLatestTestRunCalled++
+	if m.GetLatestTestRunError != nil {
+		return nil, m.GetLatestTestRunError
+	}
+
+	var latest *TestRun
+	for _, run := range m.TestRuns[testID] {
+		if latest == nil || run.Created.After(latest.Created) {
+			runCopy := run
+			latest = &runCopy
+		}
+	}
+	return latest, nil
+}
+
 // GetTestRun mock implementation
 func (m *MockClient) GetTestRun(ctx context.Context, testID, runID int) (*TestRun, error) {
 	m.GetTestRunCalled++
@@ -164,6 +189,25 @@ func (m *MockClient) GetAllTestRuns(ctx context.Context, projectIDs []string, si
 	return allRuns, nil
 }
 
+// ListSchedules mock implementation
+func (m *MockClient) ListSchedules(ctx context.Context) ([]Schedule, error) {
+	m.ListSchedulesCalled++
+	if m.ListSchedulesError != nil {
+		return nil, m.ListSchedulesError
+	}
+	return m.Schedules, nil
+}
+
+// AbortTestRun mock implementation
+func (m *MockClient) AbortTestRun(ctx context.Context, runID int) error {
+	m.AbortTestRunCalled++
+	if m.AbortTestRunError != nil {
+		return m.AbortTestRunError
+	}
+	m.AbortedRuns = append(m.AbortedRuns, runID)
+	return nil
+}
+
 // AddTestData is a helper method to easily add test data
 func (m *MockClient) AddTestData(project Project, test Test, runs ...TestRun) {
 	// Add project if not exists
@@ -202,12 +246,18 @@ func (m *MockClient) Reset() {
 	m.ListProjectsCalled = 0
 	m.ListTestsCalled = 0
 	m.ListTestRunsCalled = 0
+	m.GetLatestTestRunCalled = 0
 	m.GetTestRunCalled = 0
 	m.GetAllTestRunsCalled = 0
+	m.ListSchedulesCalled = 0
+	m.AbortTestRunCalled = 0
 	
 	m.ListProjectsError = nil
 	m.ListTestsError = nil
 	m.ListTestRunsError = nil
+	m.GetLatestTestRunError = nil
 	m.GetTestRunError = nil
 	m.GetAllTestRunsError = nil
+	m.ListSchedulesError = nil
+	m.AbortTestRunError = nil
 }
\ No newline at end of file
@@ -0,0 +1,275 @@
+package k6client
+
+import (
+	"context"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// Reset values above this are Unix timestamps rather than seconds from now
+const unixResetThreshold = 1e9
+
+// RateLimiterMetrics holds the rate limiter metrics
+type RateLimiterMetrics struct {
+	WaitingRequests       prometheus.Gauge
+	ThrottledSecondsTotal prometheus.Counter
+}
+
+// NewRateLimiterMetrics creates the rate limiter metrics and registers them if a registerer is provided.
+// The current tokens are reported by a gauge reading the limiter.
+func NewRateLimiterMetrics(limiter *RateLimiter, reg prometheus.Registerer) *RateLimiterMetrics {
+	metrics := &RateLimiterMetrics{
+		WaitingRequests: prometheus.NewGauge(
+			prometheus.GaugeOpts{
+				Name: "k6_exporter_api_rate_limit_waiting_requests",
+				Help: "Number of k6 API requests waiting for the rate limiter",
+			},
+		),
+		ThrottledSecondsTotal: prometheus.NewCounter(
+			prometheus.CounterOpts{
+				Name: "k6_exporter_api_rate_limit_throttled_seconds_total",
+				Help: "Total time k6 API requests spent waiting for the rate limiter in seconds",
+			},
+		),
+	}
+
+	if reg != nil {
+		reg.MustRegister(
+			metrics.WaitingRequests,
+			metrics.ThrottledSecondsTotal,
+			prometheus.NewGaugeFunc(
+				prometheus.GaugeOpts{
+					Name: "k6_exporter_api_rate_limit_tokens",
+					Help: "Requests the rate limiter currently allows without waiting",
+				},
+				limiter.Tokens,
+			),
+		)
+	}
+
+	return metrics
+}
+
+// RateLimiter is a token bucket shared by all requests of a client. It also pauses
+// all requests when the API reports an exhausted quota with Retry-After or rate
+// limit headers. A rate of zero only applies the pauses.
+type RateLimiter struct {
+	rate    float64 // Tokens per second
+	burst   float64
+	metrics *RateLimiterMetrics
+	now     func() time.Time
+
+	mu          sync.Mutex
+	tokens      float64 // Negative while requests are waiting for reserved tokens
+	last        time.Time
+	pausedUntil time.Time
+}
+
+// NewRateLimiter creates a rate limiter allowing rate requests per second with bursts of up to burst requests
+func NewRateLimiter(rate float64, burst int, reg prometheus.Registerer) *RateLimiter {
+	if burst < 1 {
+		burst = 1
+	}
+	limiter := &RateLimiter{
+		rate:   rate,
+		burst:  float64(burst),
+		now:    time.Now,
+		tokens: float64(burst),
+		last:   time.Now(),
+	}
+	limiter.metrics = NewRateLimiterMetrics(limiter, reg)
+	return limiter
+}
+
+// WithRateLimiter limits the requests of the client and returns the client
+func (c *Client) WithRateLimiter(limiter *RateLimiter) *Client {
+	c.limiter = limiter
+	return c
+}
+
+// Wait blocks until the request may be sent or the context is done
+func (l *RateLimiter) Wait(ctx context.Context) error {
+	delay := l.reserve()
+	if delay <= 0 {
+		return nil
+	}
+
+	l.metrics.WaitingRequests.Inc()
+	defer l.metrics.WaitingRequests.Dec()
+
+	start := l.now()
+	defer func() {
+		l.metrics.ThrottledSecondsTotal.Add(l.now().Sub(start).Seconds())
+	}()
+
+	for delay > 0 {
+		timer := time.NewTimer(delay)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			l.cancel()
+			return ctx.Err()
+		case <-timer.C:
+		}
+
+		// The API may have paused requests while this one was waiting
+		delay = l.pauseRemaining()
+	}
+	return nil
+}
+
+// Tokens returns the number of requests currently allowed without waiting
+func (l *RateLimiter) Tokens() float64 {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	now := l.now()
+	l.refill(now)
+	if l.rate <= 0 || now.Before(l.pausedUntil) || l.tokens < 0 {
+		return 0
+	}
+	return l.tokens
+}
+
+// Observe adapts the limiter to the rate limit headers of a response. Requests are
+// paused until Retry-After on 429 and 503 responses, and until the reset time when
+// the remaining quota is exhausted. A lower remaining quota caps the tokens.
+func (l *RateLimiter) Observe(resp *http.Response) {
+	now := l.now()
+
+	if resp.StatusCode == http.StatusTooManyRequests || resp.StatusCode == http.StatusServiceUnavailable {
+		if until, ok := parseRetryAfter(resp.Header.Get("Retry-After"), now); ok {
+			l.pause(until)
+			return
+		}
+	}
+
+	remaining, err := strconv.ParseFloat(firstHeader(resp.Header, "X-RateLimit-Remaining", "RateLimit-Remaining"), 64)
+	if err != nil {
+		return
+	}
+
+	if remaining <= 0 {
+		if until, ok := parseReset(firstHeader(resp.Header, "X-RateLimit-Reset", "RateLimit-Reset"), now); ok {
+			l.pause(until)
+		}
+		return
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.refill(now)
+	if l.tokens > remaining {
+		l.tokens = remaining
+	}
+}
+
+// reserve takes a token and returns how long to wait until it is available
+func (l *RateLimiter) reserve() time.Duration {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	now := l.now()
+	pause := l.pausedUntil.Sub(now)
+	if l.rate <= 0 {
+		return pause
+	}
+
+	l.refill(now)
+	l.tokens--
+	if l.tokens >= 0 {
+		return pause
+	}
+
+	ready := l.last.Add(time.Duration(-l.tokens / l.rate * float64(time.Second)))
+	if delay := ready.Sub(now); delay > pause {
+		return delay
+	}
+	return pause
+}
+
+// cancel returns the token of a request that stopped waiting
+func (l *RateLimiter) cancel() {
+	if l.rate <= 0 {
+		return
+	}
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.tokens++
+}
+
+// pauseRemaining returns how long requests are still paused
+func (l *RateLimiter) pauseRemaining() time.Duration {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return l.pausedUntil.Sub(l.now())
+}
+
+// pause stops all requests until the given time and empties the bucket, it refills from then on
+func (l *RateLimiter) pause(until time.Time) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if until.After(l.pausedUntil) {
+		l.pausedUntil = until
+	}
+	l.refill(l.now())
+	if l.tokens > 0 {
+		l.tokens = 0
+	}
+	if until.After(l.last) {
+		l.last = until
+	}
+}
+
+// refill adds the tokens accumulated since the last refill. The caller must hold the lock.
+func (l *RateLimiter) refill(now time.Time) {
+	if l.rate <= 0 || !now.After(l.last) {
+		return
+	}
+	l.tokens += now.Sub(l.last).Seconds() * l.rate
+	if l.tokens > l.burst {
+		l.tokens = l.burst
+	}
+	l.last = now
+}
+
+// firstHeader returns the first of the headers that is set
+func firstHeader(header http.Header, names ...string) string {
+	for _, name := range names {
+		if value := header.Get(name); value != "" {
+			return value
+		}
+	}
+	return ""
+}
+
+// parseRetryAfter parses a Retry-After header in seconds or as an HTTP date
+func parseRetryAfter(value string, now time.Time) (time.Time, bool) {
+	if value == "" {
+		return time.Time{}, false
+	}
+	if seconds, err := strconv.ParseFloat(value, 64); err == nil && seconds >= 0 {
+		return now.Add(time.Duration(seconds * float64(time.Second))), true
+	}
+	if t, err := http.ParseTime(value); err == nil {
+		return t, true
+	}
+	return time.Time{}, false
+}
+
+// parseReset parses a rate limit reset header, in seconds from now or as a Unix timestamp
+func parseReset(value string, now time.Time) (time.Time, bool) {
+	seconds, err := strconv.ParseFloat(value, 64)
+	if err != nil || seconds < 0 {
+		return time.Time{}, false
+	}
+	if seconds > unixResetThreshold {
+		return time.Unix(int64(seconds), 0), true
+	}
+	return now.Add(time.Duration(seconds * float64(time.Second))), true
+}
@@ -0,0 +1,99 @@
+package k6client
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap/zaptest"
+)
+
+func TestRateLimiterBurst(t *testing.T) {
+	limiter := NewRateLimiter(20, 2, prometheus.NewRegistry())
+	ctx := context.Background()
+
+	start := time.Now()
+	require.NoError(t, limiter.Wait(ctx))
+	require.NoError(t, limiter.Wait(ctx))
+	assert.Less(t, time.Since(start), 20*time.Millisecond, "the burst is not throttled")
+
+	// The third request waits for a token, 50ms at 20 requests per second
+	require.NoError(t, limiter.Wait(ctx))
+	assert.GreaterOrEqual(t, time.Since(start), 40*time.Millisecond)
+	assert.Greater(t, testutil.ToFloat64(limiter.metrics.ThrottledSecondsTotal), 0.0)
+	assert.Equal(t, float64(0), testutil.ToFloat64(limiter.metrics.WaitingRequests))
+}
+
+func TestRateLimiterContext(t *testing.T) {
+	limiter := NewRateLimiter(0.1, 1, nil)
+	require.NoError(t, limiter.Wait(context.Background()))
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+
+	start := time.Now()
+	err := limiter.Wait(ctx)
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+	assert.Less(t, time.Since(start), time.Second)
+	assert.Equal(t, ErrorClassTimeout, ErrorClass(err))
+}
+
+func TestRateLimiterObserve(t *testing.T) {
+	now := time.Now()
+	limiter := NewRateLimiter(10, 10, nil)
+	limiter.now = func() time.Time { return now }
+	limiter.last = now
+
+	// A lower remaining quota caps the tokens
+	limiter.Observe(&http.Response{StatusCode: http.StatusOK, Header: http.Header{"X-Ratelimit-Remaining": []string{"3"}}})
+	assert.Equal(t, float64(3), limiter.Tokens())
+
+	// An exhausted quota pauses until the reset
+	limiter.Observe(&http.Response{StatusCode: http.StatusOK, Header: http.Header{
+		"X-Ratelimit-Remaining": []string{"0"},
+		"X-Ratelimit-Reset":     []string{strconv.FormatInt(now.Add(30*time.Second).Unix(), 10)},
+	}})
+	assert.Equal(t, float64(0), limiter.Tokens())
+	assert.InDelta(t, 30, limiter.reserve().Seconds(), 1)
+
+	// Retry-After on 429 extends the pause
+	limiter.Observe(&http.Response{StatusCode: http.StatusTooManyRequests, Header: http.Header{"Retry-After": []string{"60"}}})
+	assert.InDelta(t, 60, limiter.pauseRemaining().Seconds(), 0.001)
+
+	// Tokens refill once the pause is over, minus the one reserved above
+	now = now.Add(61 * time.Second)
+	assert.Equal(t, float64(9), limiter.Tokens())
+}
+
+func TestClientRateLimiterRetryAfter(t *testing.T) {
+	var requests atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if requests.Add(1) == 1 {
+			w.Header().Set("Retry-After", "1")
+			w.WriteHeader(http.StatusTooManyRequests)
+			return
+		}
+		w.Write([]byte(`{"value":[]}`))
+	}))
+	defer server.Close()
+
+	client := NewClient(server.URL, "stack", "token", zaptest.NewLogger(t)).WithRateLimiter(NewRateLimiter(0, 1, nil))
+
+	_, err := client.ListProjects(context.Background())
+	assert.Equal(t, ErrorClassRateLimited, ErrorClass(err))
+
+	// The next request waits for the pause and times out cleanly
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	_, err = client.ListProjects(ctx)
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+	assert.Equal(t, int32(1), requests.Load())
+}
@@ -0,0 +1,258 @@
+package k6client
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+// Placeholder for the API token in recorded fixtures
+const redacted = "REDACTED"
+
+// Fixture is a recorded API response
+type Fixture struct {
+	Method string      `json:"method"`
+	URL    string      `json:"url"` // Path and query, without the host
+	Status int         `json:"status"`
+	Header http.Header `json:"header"`
+	Body   string      `json:"body"`
+	Time   time.Time   `json:"time"` // When the response was recorded
+}
+
+// Recorder is an http.RoundTripper that writes every API response to a directory
+// of numbered fixtures. The token of the request is redacted from the recorded
+// headers and bodies. Conditional request headers are removed so every fixture
+// holds a complete response.
+type Recorder struct {
+	dir  string
+	next http.RoundTripper
+
+	mu  sync.Mutex
+	seq int
+}
+
+// NewRecorder creates the fixture directory and records the responses of next to it.
+// Numbering continues after the fixtures already in the directory.
+func NewRecorder(dir string, next http.RoundTripper) (*Recorder, error) {
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, fmt.Errorf("create fixture directory: %w", err)
+	}
+	fixtures, err := fixtureFiles(dir)
+	if err != nil {
+		return nil, err
+	}
+	if next == nil {
+		next = http.DefaultTransport
+	}
+
+	recorder := &Recorder{dir: dir, next: next}
+	if len(fixtures) > 0 {
+		last := strings.TrimSuffix(filepath.Base(fixtures[len(fixtures)-1]), ".json")
+		recorder.seq, _ = strconv.Atoi(last)
+	}
+	return recorder, nil
+}
+
+// RoundTrip implements http.RoundTripper
+func (r *Recorder) RoundTrip(req *http.Request) (*http.Response, error) {
+	req = req.Clone(req.Context())
+	req.Header.Del("If-None-Match")
+	req.Header.Del("If-Modified-Since")
+
+	resp, err := r.next.RoundTrip(req)
+	if err != nil {
+		return nil, err
+	}
+
+	body, err := io.ReadAll(resp.Body)
+	resp.Body.Close()
+	if err != nil {
+		return nil, fmt.Errorf("read response: %w", err)
+	}
+	resp.Body = io.NopCloser(bytes.NewReader(body))
+
+	token := strings.TrimPrefix(req.Header.Get("Authorization"), "Bearer ")
+	fixture := Fixture{
+		Method: req.Method,
+		URL:    req.URL.RequestURI(),
+		Status: resp.StatusCode,
+		Header: redactHeader(resp.Header, token),
+		Body:   redact(string(body), token),
+		Time:   time.Now().UTC(),
+	}
+	if err := r.write(fixture); err != nil {
+		return nil, err
+	}
+	return resp, nil
+}
+
+// write stores the fixture under the next sequence number
+func (r *Recorder) write(fixture Fixture) error {
+	data, err := json.MarshalIndent(fixture, "", "  ")
+	if err != nil {
+		return fmt.Errorf("encode fixture: %w", err)
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.seq++
+	path := filepath.Join(r.dir, fmt.Sprintf("%06d.json", r.seq))
+	if err := os.WriteFile(path, data, 0o600); err != nil {
+		return fmt.Errorf("write fixture: %w", err)
+	}
+	return nil
+}
+
+// Replayer is an http.RoundTripper that serves recorded fixtures instead of the
+// API. Responses to the same method and URL are served in the recorded order,
+// the last one is repeated once they are used up.
+type Replayer struct {
+	logger     *zap.Logger
+	capturedAt time.Time // Time of the first recorded response, zero if the fixtures have none
+	loaded     time.Time
+
+	mu        sync.Mutex
+	responses map[string][]Fixture
+}
+
+// NewReplayer loads the fixtures of the directory
+func NewReplayer(dir string, logger *zap.Logger) (*Replayer, error) {
+	files, err := fixtureFiles(dir)
+	if err != nil {
+		return nil, err
+	}
+	if len(files) == 0 {
+		return nil, fmt.Errorf("no fixtures found in %s", dir)
+	}
+
+	replayer := &Replayer{logger: logger, loaded: time.Now(), responses: make(map[string][]Fixture)}
+	for _, file := range files {
+		data, err := os.ReadFile(file)
+		if err != nil {
+			return nil, fmt.Errorf("read fixture: %w", err)
+		}
+		var fixture Fixture
+		if err := json.Unmarshal(data, &fixture); err != nil {
+			return nil, fmt.Errorf("decode fixture %s: %w", filepath.Base(file), err)
+		}
+		key, err := fixtureKey(fixture.Method, fixture.URL)
+		if err != nil {
+			return nil, fmt.Errorf("fixture %s: %w", filepath.Base(file), err)
+		}
+		replayer.responses[key] = append(replayer.responses[key], fixture)
+		if !fixture.Time.IsZero() && (replayer.capturedAt.IsZero() || fixture.Time.Before(replayer.capturedAt)) {
+			replayer.capturedAt = fixture.Time
+		}
+	}
+
+	if replayer.capturedAt.IsZero() {
+		logger.Warn("API fixtures have no recording time, replaying with the current time", zap.String("dir", dir))
+	}
+	logger.Info("loaded API fixtures", zap.String("dir", dir), zap.Int("count", len(files)), zap.Time("captured_at", replayer.capturedAt))
+	return replayer, nil
+}
+
+// Now returns the time of the recording, advanced by the time since the fixtures were
+// loaded, so a replay sees the runs as they were when they were recorded. It is the
+// current time if the fixtures have no recording time.
+func (r *Replayer) Now() time.Time {
+	if r.capturedAt.IsZero() {
+		return time.Now()
+	}
+	return r.capturedAt.Add(time.Since(r.loaded))
+}
+
+// RoundTrip implements http.RoundTripper
+func (r *Replayer) RoundTrip(req *http.Request) (*http.Response, error) {
+	key, err := fixtureKey(req.Method, req.URL.RequestURI())
+	if err != nil {
+		return nil, err
+	}
+
+	r.mu.Lock()
+	fixtures := r.responses[key]
+	var fixture Fixture
+	found := len(fixtures) > 0
+	if found {
+		fixture = fixtures[0]
+		if len(fixtures) > 1 {
+			r.responses[key] = fixtures[1:]
+		}
+	}
+	r.mu.Unlock()
+
+	if !found {
+		r.logger.Warn("no recorded response", zap.String("method", req.Method), zap.String("url", req.URL.RequestURI()))
+		fixture = Fixture{
+			Status: http.StatusNotFound,
+			Header: http.Header{"Content-Type": []string{"application/json"}},
+			Body:   `{"error":{"message":"no recorded response"}}`,
+		}
+	}
+
+	return &http.Response{
+		StatusCode:    fixture.Status,
+		Status:        fmt.Sprintf("%d %s", fixture.Status, http.StatusText(fixture.Status)),
+		Proto:         "HTTP/1.1",
+		ProtoMajor:    1,
+		ProtoMinor:    1,
+		Header:        fixture.Header.Clone(),
+		Body:          io.NopCloser(strings.NewReader(fixture.Body)),
+		ContentLength: int64(len(fixture.Body)),
+		Request:       req,
+	}, nil
+}
+
+// fixtureFiles returns the fixture files of the directory in recorded order
+func fixtureFiles(dir string) ([]string, error) {
+	files, err := filepath.Glob(filepath.Join(dir, "*.json"))
+	if err != nil {
+		return nil, fmt.Errorf("list fixtures: %w", err)
+	}
+	sort.Strings(files)
+	return files, nil
+}
+
+// fixtureKey identifies a request by method, path and query in canonical order
+func fixtureKey(method, requestURI string) (string, error) {
+	path, rawQuery, _ := strings.Cut(requestURI, "?")
+	query, err := url.ParseQuery(rawQuery)
+	if err != nil {
+		return "", fmt.Errorf("parse query: %w", err)
+	}
+	return method + " " + path + "?" + query.Encode(), nil
+}
+
+// redactHeader returns a copy of the header without cookies and with the token redacted
+func redactHeader(header http.Header, token string) http.Header {
+	redactedHeader := make(http.Header, len(header))
+	for name, values := range header {
+		if name == "Set-Cookie" {
+			continue
+		}
+		for _, value := range values {
+			redactedHeader.Add(name, redact(value, token))
+		}
+	}
+	return redactedHeader
+}
+
+// redact replaces the token in s
+func redact(s, token string) string {
+	if token == "" {
+		return s
+	}
+	return strings.ReplaceAll(s, token, redacted)
+}
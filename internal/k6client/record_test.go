@@ -0,0 +1,90 @@
+package k6client
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap/zaptest"
+)
+
+func TestRecordAndReplay(t *testing.T) {
+	version := "v1"
+	var server *httptest.Server
+	server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("If-None-Match") != "" {
+			w.WriteHeader(http.StatusNotModified)
+			return
+		}
+		w.Header().Set("ETag", `"`+version+`"`)
+		w.Header().Set("Set-Cookie", "session=secret-token")
+		if r.URL.Query().Get("page") == "" {
+			fmt.Fprintf(w, `{"value":[{"id":1,"name":"%s secret-token"}],"next":"%s/cloud/v6/load_tests?page=2"}`, version, server.URL)
+			return
+		}
+		fmt.Fprintf(w, `{"value":[{"id":2,"name":"%s"}]}`, version)
+	}))
+	defer server.Close()
+
+	dir := filepath.Join(t.TempDir(), "fixtures")
+	recorder, err := NewRecorder(dir, nil)
+	require.NoError(t, err)
+	client := NewClient(server.URL, "stack", "secret-token", zaptest.NewLogger(t)).
+		WithHTTPClient(&http.Client{Transport: recorder}).
+		WithCache(NewResponseCache(10, prometheus.NewRegistry()))
+
+	// Conditional requests are not sent while recording, every fixture has a body
+	_, err = client.ListTests(context.Background(), nil)
+	require.NoError(t, err)
+	version = "v2"
+	_, err = client.ListTests(context.Background(), nil)
+	require.NoError(t, err)
+
+	files, err := fixtureFiles(dir)
+	require.NoError(t, err)
+	require.Len(t, files, 4)
+	assert.Equal(t, "000001.json", filepath.Base(files[0]))
+	for _, file := range files {
+		data, err := os.ReadFile(file)
+		require.NoError(t, err)
+		assert.NotContains(t, string(data), "secret-token")
+		assert.NotContains(t, string(data), "Set-Cookie")
+	}
+
+	// Numbering continues after the recorded fixtures
+	recorder, err = NewRecorder(dir, nil)
+	require.NoError(t, err)
+	assert.Equal(t, 4, recorder.seq)
+
+	// Responses are replayed in the recorded order, the last one repeats
+	replayer, err := NewReplayer(dir, zaptest.NewLogger(t))
+	require.NoError(t, err)
+	assert.WithinDuration(t, time.Now(), replayer.Now(), time.Minute, "the replay clock starts at the recording")
+	client = NewClient("https://api.k6.io", "stack", "", zaptest.NewLogger(t)).
+		WithHTTPClient(&http.Client{Transport: replayer}).
+		WithCache(NewResponseCache(10, prometheus.NewRegistry()))
+	for _, want := range []string{"v1 REDACTED", "v2 REDACTED", "v2 REDACTED"} {
+		tests, err := client.ListTests(context.Background(), nil)
+		require.NoError(t, err)
+		require.Len(t, tests, 2)
+		assert.Equal(t, want, tests[0].Name)
+	}
+
+	// Requests that were not recorded are not found
+	_, err = client.ListProjects(context.Background())
+	assert.Equal(t, ErrorClassNotFound, ErrorClass(err))
+}
+
+func TestNewReplayerWithoutFixtures(t *testing.T) {
+	_, err := NewReplayer(t.TempDir(), zaptest.NewLogger(t))
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "no fixtures found")
+}
@@ -0,0 +1,119 @@
+package k6client
+
+import (
+	"fmt"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"go.uber.org/zap"
+)
+
+// TokenFile provides an API token read from a file, such as a mounted secret.
+// The file is reread when its modification time or size changes, so rotated
+// tokens are picked up without a restart. The token itself is never logged.
+type TokenFile struct {
+	path       string
+	lastReload prometheus.Gauge
+	logger     *zap.Logger
+
+	mu      sync.Mutex
+	token   string
+	modTime time.Time
+	size    int64
+}
+
+// NewTokenFile reads the token file and registers its reload metric if a registerer is provided
+func NewTokenFile(path string, logger *zap.Logger, reg prometheus.Registerer) (*TokenFile, error) {
+	tf := &TokenFile{
+		path: path,
+		lastReload: prometheus.NewGauge(
+			prometheus.GaugeOpts{
+				Name: "k6_exporter_token_file_last_reload_timestamp_seconds",
+				Help: "Unix timestamp when the API token was last read from its file",
+			},
+		),
+		logger: logger.With(zap.String("token_file", path)),
+	}
+
+	info, err := os.Stat(path)
+	if err != nil {
+		return nil, fmt.Errorf("stat token file: %w", err)
+	}
+	if err := tf.load(info); err != nil {
+		return nil, err
+	}
+
+	if reg != nil {
+		reg.MustRegister(tf.lastReload)
+	}
+
+	return tf, nil
+}
+
+// Token returns the current token, rereading the file first if it changed
+func (tf *TokenFile) Token() string {
+	tf.mu.Lock()
+	defer tf.mu.Unlock()
+
+	info, err := os.Stat(tf.path)
+	if err != nil {
+		tf.logger.Warn("failed to check token file, using the last token", zap.Error(err))
+		return tf.token
+	}
+	if info.ModTime().Equal(tf.modTime) && info.Size() == tf.size {
+		return tf.token
+	}
+
+	if err := tf.load(info); err != nil {
+		tf.logger.Warn("failed to reload token file, using the last token", zap.Error(err))
+	}
+	return tf.token
+}
+
+// Reload rereads the file regardless of its modification time and returns true
+// if the token changed. It is used after the API rejected the current token.
+func (tf *TokenFile) Reload() bool {
+	tf.mu.Lock()
+	defer tf.mu.Unlock()
+
+	info, err := os.Stat(tf.path)
+	if err != nil {
+		tf.logger.Warn("failed to check token file", zap.Error(err))
+		return false
+	}
+
+	previous := tf.token
+	if err := tf.load(info); err != nil {
+		tf.logger.Warn("failed to reload token file", zap.Error(err))
+		return false
+	}
+	return tf.token != previous
+}
+
+// load reads the token from the file described by info. The caller must hold the lock
+// unless the token file is not shared yet.
+func (tf *TokenFile) load(info os.FileInfo) error {
+	data, err := os.ReadFile(tf.path)
+	if err != nil {
+		return fmt.Errorf("read token file: %w", err)
+	}
+
+	token := strings.TrimSpace(string(data))
+	if token == "" {
+		return fmt.Errorf("token file %s is empty", tf.path)
+	}
+
+	changed := tf.token != "" && token != tf.token
+	tf.token = token
+	tf.modTime = info.ModTime()
+	tf.size = info.Size()
+	tf.lastReload.SetToCurrentTime()
+
+	if changed {
+		tf.logger.Info("reloaded rotated API token")
+	}
+	return nil
+}
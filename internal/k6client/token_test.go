@@ -0,0 +1,76 @@
+package k6client
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap/zaptest"
+)
+
+// writeToken writes the token file with a distinct modification time
+func writeToken(t *testing.T, path, token string, modTime time.Time) {
+	require.NoError(t, os.WriteFile(path, []byte(token+"\n"), 0o600))
+	require.NoError(t, os.Chtimes(path, modTime, modTime))
+}
+
+func TestTokenFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "token")
+	now := time.Now()
+	writeToken(t, path, "first-token", now.Add(-time.Hour))
+
+	registry := prometheus.NewRegistry()
+	tf, err := NewTokenFile(path, zaptest.NewLogger(t), registry)
+	require.NoError(t, err)
+	assert.Equal(t, "first-token", tf.Token())
+	assert.InDelta(t, float64(time.Now().Unix()), testutil.ToFloat64(tf.lastReload), 5)
+
+	// Rotated tokens are picked up on the next use
+	writeToken(t, path, "second-token", now)
+	assert.Equal(t, "second-token", tf.Token())
+
+	// An empty file keeps the last token
+	writeToken(t, path, "", now.Add(time.Minute))
+	assert.Equal(t, "second-token", tf.Token())
+	assert.False(t, tf.Reload())
+
+	_, err = NewTokenFile(filepath.Join(t.TempDir(), "missing"), zaptest.NewLogger(t), nil)
+	assert.Error(t, err)
+}
+
+func TestClientTokenFileReloadOnUnauthorized(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "token")
+	modTime := time.Now().Add(-time.Hour)
+	writeToken(t, path, "old-token", modTime)
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "Bearer new-token" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		w.Write([]byte(`{"value":[]}`))
+	}))
+	defer server.Close()
+
+	tf, err := NewTokenFile(path, zaptest.NewLogger(t), nil)
+	require.NoError(t, err)
+	client := NewClientWithTokenFile(server.URL, "stack", tf, zaptest.NewLogger(t))
+
+	// The file is rewritten with the same modification time, only the 401 triggers a reread
+	writeToken(t, path, "new-token", modTime)
+	_, err = client.ListProjects(context.Background())
+	require.NoError(t, err)
+
+	// Without a different token the 401 is returned
+	writeToken(t, path, "bad-token", modTime.Add(time.Minute))
+	_, err = client.ListProjects(context.Background())
+	assert.Equal(t, ErrorClassUnauthorized, ErrorClass(err))
+}
@@ -0,0 +1,109 @@
+package k6client
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"net"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+	"time"
+)
+
+// TransportConfig configures the HTTP client used for the k6 API
+type TransportConfig struct {
+	ProxyURL       string   // Proxy for all requests, HTTPS_PROXY and NO_PROXY are used if empty
+	NoProxy        []string // Hosts and domains reached without a proxy, in addition to NO_PROXY
+	CAFile         string   // PEM certificates trusted in addition to the system roots
+	ClientCertFile string   // Client certificate for mutual TLS
+	ClientKeyFile  string
+	MaxIdleConns   int
+	KeepAlive      time.Duration // TCP keep-alive period, 0 disables keep-alive probes
+	Timeout        time.Duration // Request timeout, 0 means none
+}
+
+// NewHTTPClient builds an HTTP client from the transport configuration
+func NewHTTPClient(cfg TransportConfig) (*http.Client, error) {
+	tlsConfig := &tls.Config{MinVersion: tls.VersionTLS12}
+
+	if cfg.CAFile != "" {
+		pem, err := os.ReadFile(cfg.CAFile)
+		if err != nil {
+			return nil, fmt.Errorf("read CA file: %w", err)
+		}
+		pool, err := x509.SystemCertPool()
+		if err != nil {
+			pool = x509.NewCertPool()
+		}
+		if !pool.AppendCertsFromPEM(pem) {
+			return nil, fmt.Errorf("no certificates found in CA file %s", cfg.CAFile)
+		}
+		tlsConfig.RootCAs = pool
+	}
+
+	if cfg.ClientCertFile != "" {
+		cert, err := tls.LoadX509KeyPair(cfg.ClientCertFile, cfg.ClientKeyFile)
+		if err != nil {
+			return nil, fmt.Errorf("load client certificate: %w", err)
+		}
+		tlsConfig.Certificates = []tls.Certificate{cert}
+	}
+
+	proxy := http.ProxyFromEnvironment
+	if cfg.ProxyURL != "" {
+		proxyURL, err := url.Parse(cfg.ProxyURL)
+		if err != nil {
+			return nil, fmt.Errorf("parse proxy URL: %w", err)
+		}
+		proxy = http.ProxyURL(proxyURL)
+	}
+	if len(cfg.NoProxy) > 0 {
+		proxy = proxyFunc(proxy, cfg.NoProxy)
+	}
+
+	keepAlive := cfg.KeepAlive
+	if keepAlive == 0 {
+		keepAlive = -1 // Disabled, the dialer would otherwise use its default period
+	}
+	dialer := &net.Dialer{Timeout: 30 * time.Second, KeepAlive: keepAlive}
+
+	transport := &http.Transport{
+		Proxy:                 proxy,
+		DialContext:           dialer.DialContext,
+		TLSClientConfig:       tlsConfig,
+		ForceAttemptHTTP2:     true,
+		MaxIdleConns:          cfg.MaxIdleConns,
+		MaxIdleConnsPerHost:   cfg.MaxIdleConns, // All requests go to the same host
+		IdleConnTimeout:       90 * time.Second,
+		TLSHandshakeTimeout:   10 * time.Second,
+		ExpectContinueTimeout: time.Second,
+	}
+
+	return &http.Client{Transport: transport, Timeout: cfg.Timeout}, nil
+}
+
+// proxyFunc returns a proxy function that sends requests through the proxy of next,
+// except for requests to the hosts and domains of noProxy
+func proxyFunc(next func(*http.Request) (*url.URL, error), noProxy []string) func(*http.Request) (*url.URL, error) {
+	return func(req *http.Request) (*url.URL, error) {
+		host := strings.ToLower(req.URL.Hostname())
+		for _, entry := range noProxy {
+			entry = strings.ToLower(strings.TrimSpace(entry))
+			if entry == "" {
+				continue
+			}
+			if entry == "*" || host == strings.TrimPrefix(entry, ".") || strings.HasSuffix(host, "."+strings.TrimPrefix(entry, ".")) {
+				return nil, nil
+			}
+		}
+		return next(req)
+	}
+}
+
+// WithHTTPClient replaces the HTTP client used for API requests and returns the client
+func (c *Client) WithHTTPClient(httpClient *http.Client) *Client {
+	c.httpClient = httpClient
+	return c
+}
@@ -0,0 +1,120 @@
+package k6client
+
+import (
+	"context"
+	"encoding/pem"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap/zaptest"
+)
+
+func TestNewHTTPClientCAFile(t *testing.T) {
+	server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"value":[]}`))
+	}))
+	defer server.Close()
+
+	// The test server certificate is not trusted by the system roots
+	httpClient, err := NewHTTPClient(TransportConfig{Timeout: 5 * time.Second})
+	require.NoError(t, err)
+	client := NewClient(server.URL, "stack", "token", zaptest.NewLogger(t)).WithHTTPClient(httpClient)
+	_, err = client.ListProjects(context.Background())
+	assert.Error(t, err)
+
+	caFile := filepath.Join(t.TempDir(), "ca.pem")
+	certPEM := pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: server.Certificate().Raw})
+	require.NoError(t, os.WriteFile(caFile, certPEM, 0o600))
+
+	httpClient, err = NewHTTPClient(TransportConfig{CAFile: caFile, MaxIdleConns: 5, KeepAlive: 30 * time.Second, Timeout: 5 * time.Second})
+	require.NoError(t, err)
+	assert.Equal(t, 5*time.Second, httpClient.Timeout)
+	client = NewClient(server.URL, "stack", "token", zaptest.NewLogger(t)).WithHTTPClient(httpClient)
+	_, err = client.ListProjects(context.Background())
+	assert.NoError(t, err)
+}
+
+func TestNewHTTPClientInvalidFiles(t *testing.T) {
+	invalid := filepath.Join(t.TempDir(), "invalid.pem")
+	require.NoError(t, os.WriteFile(invalid, []byte("not a certificate"), 0o600))
+
+	_, err := NewHTTPClient(TransportConfig{CAFile: invalid})
+	assert.ErrorContains(t, err, "no certificates found")
+
+	_, err = NewHTTPClient(TransportConfig{CAFile: filepath.Join(t.TempDir(), "missing.pem")})
+	assert.ErrorContains(t, err, "read CA file")
+
+	_, err = NewHTTPClient(TransportConfig{ClientCertFile: invalid, ClientKeyFile: invalid})
+	assert.ErrorContains(t, err, "load client certificate")
+}
+
+func TestNewHTTPClientProxy(t *testing.T) {
+	var proxied string
+	proxy := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		// Proxies receive the absolute URL of the target
+		proxied = r.URL.String()
+		w.Write([]byte(`{"value":[]}`))
+	}))
+	defer proxy.Close()
+
+	httpClient, err := NewHTTPClient(TransportConfig{ProxyURL: proxy.URL, Timeout: 5 * time.Second})
+	require.NoError(t, err)
+	client := NewClient("http://api.k6.example", "stack", "token", zaptest.NewLogger(t)).WithHTTPClient(httpClient)
+	_, err = client.ListProjects(context.Background())
+	require.NoError(t, err)
+	assert.Contains(t, proxied, "http://api.k6.example/cloud/v6/projects")
+}
+
+func TestProxyFunc(t *testing.T) {
+	proxyURL, err := url.Parse("http://proxy.internal:3128")
+	require.NoError(t, err)
+	proxy := proxyFunc(http.ProxyURL(proxyURL), []string{"internal.example", ".corp", " localhost "})
+
+	tests := []struct {
+		target string
+		want   *url.URL
+	}{
+		{target: "https://api.k6.io/cloud/v6/projects", want: proxyURL},
+		{target: "https://internal.example/api", want: nil},
+		{target: "https://api.internal.example/api", want: nil},
+		{target: "https://k6.corp:8443/api", want: nil},
+		{target: "http://LOCALHOST:9090/api", want: nil},
+		{target: "https://notinternal.example/api", want: proxyURL},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.target, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, tt.target, nil)
+			got, err := proxy(req)
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestNewHTTPClientNoProxyWithoutProxyURL(t *testing.T) {
+	// The proxy of the environment is bypassed for the hosts of NoProxy as well
+	httpClient, err := NewHTTPClient(TransportConfig{NoProxy: []string{"api.k6.example"}})
+	require.NoError(t, err)
+	transport := httpClient.Transport.(*http.Transport)
+	got, err := transport.Proxy(httptest.NewRequest(http.MethodGet, "https://api.k6.example/cloud/v6/projects", nil))
+	require.NoError(t, err)
+	assert.Nil(t, got)
+
+	envProxy, err := url.Parse("http://env-proxy.internal:3128")
+	require.NoError(t, err)
+	proxy := proxyFunc(func(*http.Request) (*url.URL, error) { return envProxy, nil }, []string{"api.k6.example"})
+	got, err = proxy(httptest.NewRequest(http.MethodGet, "https://api.k6.example/cloud/v6/projects", nil))
+	require.NoError(t, err)
+	assert.Nil(t, got)
+	got, err = proxy(httptest.NewRequest(http.MethodGet, "https://api.k6.io/cloud/v6/projects", nil))
+	require.NoError(t, err)
+	assert.Equal(t, envProxy, got)
+}
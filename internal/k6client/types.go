@@ -1,6 +1,9 @@
 package k6client
 
 import (
+	"sort"
+	"strconv"
+	"strings"
 	"time"
 )
 
@@ -28,6 +31,14 @@ type TestRun struct {
 	Result        *string                `json:"result"`
 	ResultDetails map[string]interface{} `json:"result_details"`
 	Cost          *Cost                  `json:"cost"`
+	Distribution  []LoadZoneDistribution `json:"distribution"`
+	Options       map[string]interface{} `json:"options"`
+}
+
+// LoadZoneDistribution represents the share of a test run's VUs assigned to a load zone
+type LoadZoneDistribution struct {
+	LoadZone string  `json:"load_zone"`
+	Percent  float64 `json:"percent"`
 }
 
 // StatusHistoryEntry represents a status change in a test run
@@ -54,6 +65,26 @@ type Project struct {
 	Organization int       `json:"organization"`
 }
 
+// Schedule represents a k6 test schedule
+type Schedule struct {
+	ID             int             `json:"id"`
+	LoadTestID     int             `json:"load_test_id"`
+	Starts         time.Time       `json:"starts"`
+	RecurrenceRule *RecurrenceRule `json:"recurrence_rule"`
+	Deactivated    bool            `json:"deactivated"`
+	NextRun        *time.Time      `json:"next_run"`
+	CreatedBy      string          `json:"created_by"`
+}
+
+// RecurrenceRule describes how often a schedule fires
+type RecurrenceRule struct {
+	Frequency string     `json:"frequency"`
+	Interval  int        `json:"interval"`
+	ByDay     []string   `json:"byday"`
+	Until     *time.Time `json:"until"`
+	Count     *int       `json:"count"`
+}
+
 // ListResponse represents a paginated list response
 type ListResponse struct {
 	Count    int     `json:"count"`
@@ -86,6 +117,14 @@ type ProjectListResponse struct {
 	Value    []Project `json:"value"`
 }
 
+// ScheduleListResponse represents a list of schedules
+type ScheduleListResponse struct {
+	Count    int        `json:"count"`
+	Next     *string    `json:"next"`
+	Previous *string    `json:"previous"`
+	Value    []Schedule `json:"value"`
+}
+
 // Known test run status values
 const (
 	StatusCreated           = "created"
@@ -120,9 +159,14 @@ func (tr *TestRun) GetResult() string {
 
 // GetDuration returns the duration of the test run in seconds
 func (tr *TestRun) GetDuration() float64 {
+	return tr.GetDurationAt(time.Now())
+}
+
+// GetDurationAt returns the duration of the test run in seconds, active runs are measured up to now
+func (tr *TestRun) GetDurationAt(now time.Time) float64 {
 	if tr.Ended == nil {
 		// Still running, calculate from start time
-		return time.Since(tr.Created).Seconds()
+		return now.Sub(tr.Created).Seconds()
 	}
 	return tr.Ended.Sub(tr.Created).Seconds()
 }
@@ -134,3 +178,132 @@ func (tr *TestRun) GetVUH() float64 {
 	}
 	return tr.Cost.VUH
 }
+
+// Recurrence returns the recurrence rule in RRULE notation, or "once" for one-off schedules
+func (s *Schedule) Recurrence() string {
+	if s.RecurrenceRule == nil || s.RecurrenceRule.Frequency == "" {
+		return "once"
+	}
+
+	rule := "FREQ=" + strings.ToUpper(s.RecurrenceRule.Frequency)
+	if s.RecurrenceRule.Interval > 1 {
+		rule += ";INTERVAL=" + strconv.Itoa(s.RecurrenceRule.Interval)
+	}
+	if len(s.RecurrenceRule.ByDay) > 0 {
+		rule += ";BYDAY=" + strings.ToUpper(strings.Join(s.RecurrenceRule.ByDay, ","))
+	}
+	if s.RecurrenceRule.Count != nil {
+		rule += ";COUNT=" + strconv.Itoa(*s.RecurrenceRule.Count)
+	}
+	if s.RecurrenceRule.Until != nil {
+		rule += ";UNTIL=" + s.RecurrenceRule.Until.UTC().Format("20060102T150405Z")
+	}
+	return rule
+}
+
+// GetMaxVUs returns the maximum number of VUs configured in the run options, or 0 if unknown
+func (tr *TestRun) GetMaxVUs() float64 {
+	scenarios, ok := tr.Options["scenarios"].(map[string]interface{})
+	if !ok || len(scenarios) == 0 {
+		return maxScenarioVUs(tr.Options)
+	}
+
+	// Scenarios run in parallel, so their VUs add up
+	total := 0.0
+	for _, scenario := range scenarios {
+		if options, ok := scenario.(map[string]interface{}); ok {
+			total += maxScenarioVUs(options)
+		}
+	}
+	return total
+}
+
+// GetLoadZoneVUs returns the number of VUs allocated to each load zone of the run
+func (tr *TestRun) GetLoadZoneVUs() map[string]float64 {
+	maxVUs := tr.GetMaxVUs()
+	allocation := make(map[string]float64, len(tr.Distribution))
+	for _, zone := range tr.Distribution {
+		allocation[zone.LoadZone] += maxVUs * zone.Percent / 100
+	}
+	return allocation
+}
+
+// maxScenarioVUs returns the largest VU count found in a set of k6 options
+func maxScenarioVUs(options map[string]interface{}) float64 {
+	maxVUs := 0.0
+	for _, key := range []string{"vus", "maxVUs", "preAllocatedVUs"} {
+		if v, ok := options[key].(float64); ok && v > maxVUs {
+			maxVUs = v
+		}
+	}
+
+	// Arrival rate stages target iterations rather than VUs
+	if executor, _ := options["executor"].(string); strings.HasSuffix(executor, "arrival-rate") {
+		return maxVUs
+	}
+
+	stages, _ := options["stages"].([]interface{})
+	for _, stage := range stages {
+		if s, ok := stage.(map[string]interface{}); ok {
+			if target, ok := s["target"].(float64); ok && target > maxVUs {
+				maxVUs = target
+			}
+		}
+	}
+	return maxVUs
+}
+
+// GetStatusTimes returns when the run entered each status according to its status history
+func (tr *TestRun) GetStatusTimes() map[string]time.Time {
+	times := make(map[string]time.Time, len(tr.StatusHistory))
+	for _, entry := range tr.StatusHistory {
+		// Keep the latest time a status was entered
+		if existing, ok := times[entry.Type]; !ok || entry.Entered.After(existing) {
+			times[entry.Type] = entry.Entered
+		}
+	}
+	return times
+}
+
+// GetFailedThresholds returns the names of the failed thresholds reported in the
+// result details, sorted, or nil if the API did not report threshold results
+func (tr *TestRun) GetFailedThresholds() []string {
+	var failed []string
+
+	switch thresholds := tr.ResultDetails["thresholds"].(type) {
+	case map[string]interface{}:
+		// Threshold name -> {"passed": bool} or bool
+		for name, value := range thresholds {
+			if !thresholdPassed(value) {
+				failed = append(failed, name)
+			}
+		}
+	case []interface{}:
+		// [{"name": string, "passed": bool}]
+		for _, value := range thresholds {
+			entry, ok := value.(map[string]interface{})
+			if !ok {
+				continue
+			}
+			if name, ok := entry["name"].(string); ok && !thresholdPassed(entry) {
+				failed = append(failed, name)
+			}
+		}
+	}
+
+	sort.Strings(failed)
+	return failed
+}
+
+// thresholdPassed returns false only if the threshold result explicitly reports a failure
+func thresholdPassed(value interface{}) bool {
+	switch v := value.(type) {
+	case bool:
+		return v
+	case map[string]interface{}:
+		if passed, ok := v["passed"].(bool); ok {
+			return passed
+		}
+	}
+	return true
+}
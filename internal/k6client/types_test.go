@@ -258,6 +258,153 @@ func TestCostBreakdown(t *testing.T) {
 	assert.Equal(t, 15.0, cost.BilledDollars)
 }
 
+func TestScheduleRecurrence(t *testing.T) {
+	until := time.Date(2025, 1, 31, 0, 0, 0, 0, time.UTC)
+	count := 10
+
+	tests := []struct {
+		name     string
+		schedule Schedule
+		expected string
+	}{
+		{
+			name:     "one_off",
+			schedule: Schedule{},
+			expected: "once",
+		},
+		{
+			name:     "daily",
+			schedule: Schedule{RecurrenceRule: &RecurrenceRule{Frequency: "DAILY", Interval: 1}},
+			expected: "FREQ=DAILY",
+		},
+		{
+			name:     "weekly_on_weekdays",
+			schedule: Schedule{RecurrenceRule: &RecurrenceRule{Frequency: "weekly", Interval: 2, ByDay: []string{"MO", "WE"}}},
+			expected: "FREQ=WEEKLY;INTERVAL=2;BYDAY=MO,WE",
+		},
+		{
+			name:     "hourly_with_limits",
+			schedule: Schedule{RecurrenceRule: &RecurrenceRule{Frequency: "HOURLY", Count: &count, Until: &until}},
+			expected: "FREQ=HOURLY;COUNT=10;UNTIL=20250131T000000Z",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, tt.schedule.Recurrence())
+		})
+	}
+}
+
+func TestGetLoadZoneVUs(t *testing.T) {
+	tests := []struct {
+		name     string
+		testRun  TestRun
+		maxVUs   float64
+		expected map[string]float64
+	}{
+		{
+			name: "simple_vus",
+			testRun: TestRun{
+				Options: map[string]interface{}{"vus": float64(100)},
+				Distribution: []LoadZoneDistribution{
+					{LoadZone: "amazon:us:ashburn", Percent: 60},
+					{LoadZone: "amazon:ie:dublin", Percent: 40},
+				},
+			},
+			maxVUs: 100,
+			expected: map[string]float64{
+				"amazon:us:ashburn": 60,
+				"amazon:ie:dublin":  40,
+			},
+		},
+		{
+			name: "ramping_stages",
+			testRun: TestRun{
+				Options: map[string]interface{}{
+					"stages": []interface{}{
+						map[string]interface{}{"duration": "1m", "target": float64(50)},
+						map[string]interface{}{"duration": "5m", "target": float64(200)},
+					},
+				},
+				Distribution: []LoadZoneDistribution{{LoadZone: "amazon:us:ashburn", Percent: 100}},
+			},
+			maxVUs:   200,
+			expected: map[string]float64{"amazon:us:ashburn": 200},
+		},
+		{
+			name: "parallel_scenarios",
+			testRun: TestRun{
+				Options: map[string]interface{}{
+					"scenarios": map[string]interface{}{
+						"browse": map[string]interface{}{"executor": "constant-vus", "vus": float64(20)},
+						"checkout": map[string]interface{}{
+							"executor":        "ramping-arrival-rate",
+							"preAllocatedVUs": float64(10),
+							"maxVUs":          float64(30),
+							"stages":          []interface{}{map[string]interface{}{"target": float64(500)}},
+						},
+					},
+				},
+				Distribution: []LoadZoneDistribution{{LoadZone: "amazon:de:frankfurt", Percent: 50}},
+			},
+			maxVUs:   50,
+			expected: map[string]float64{"amazon:de:frankfurt": 25},
+		},
+		{
+			name:     "no_distribution",
+			testRun:  TestRun{},
+			maxVUs:   0,
+			expected: map[string]float64{},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.maxVUs, tt.testRun.GetMaxVUs())
+			assert.Equal(t, tt.expected, tt.testRun.GetLoadZoneVUs())
+		})
+	}
+}
+
+func TestGetFailedThresholds(t *testing.T) {
+	tests := []struct {
+		name     string
+		details  map[string]interface{}
+		expected []string
+	}{
+		{
+			name: "map",
+			details: map[string]interface{}{"thresholds": map[string]interface{}{
+				"http_req_duration": map[string]interface{}{"passed": false},
+				"http_req_failed":   map[string]interface{}{"passed": true},
+				"checks":            false,
+			}},
+			expected: []string{"checks", "http_req_duration"},
+		},
+		{
+			name: "list",
+			details: map[string]interface{}{"thresholds": []interface{}{
+				map[string]interface{}{"name": "http_req_duration", "passed": false},
+				map[string]interface{}{"name": "checks", "passed": true},
+			}},
+			expected: []string{"http_req_duration"},
+		},
+		{
+			name:     "not_reported",
+			details:  nil,
+			expected: nil,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			testRun := TestRun{ResultDetails: tt.details}
+			assert.Equal(t, tt.expected, testRun.GetFailedThresholds())
+		})
+	}
+}
+
 // Helper function for tests
 func stringPtr(s string) *string {
 	return &s
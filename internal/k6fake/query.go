@@ -0,0 +1,240 @@
+package k6fake
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// listResponse is the envelope of the v6 list endpoints
+type listResponse struct {
+	Count    int     `json:"count"`
+	Next     *string `json:"next"`
+	Previous *string `json:"previous"`
+	Value    any     `json:"value"`
+}
+
+// writeList applies $filter, $orderby, $skip and $top to the items and writes a
+// page with absolute next and previous links that keep the other query parameters
+func (s *Server) writeList(w http.ResponseWriter, r *http.Request, items any) {
+	query := r.URL.Query()
+
+	raw, err := json.Marshal(items)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	var values []json.RawMessage
+	var fields []map[string]any
+	if err := json.Unmarshal(raw, &values); err != nil {
+		writeError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	if err := json.Unmarshal(raw, &fields); err != nil {
+		writeError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	conditions, err := parseFilter(query.Get("$filter"))
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	indexes := make([]int, 0, len(values))
+	for i := range values {
+		if matchesAll(fields[i], conditions) {
+			indexes = append(indexes, i)
+		}
+	}
+
+	if orderBy := query.Get("$orderby"); orderBy != "" {
+		field, desc, err := parseOrderBy(orderBy)
+		if err != nil {
+			writeError(w, http.StatusBadRequest, err.Error())
+			return
+		}
+		sort.SliceStable(indexes, func(a, b int) bool {
+			cmp := compare(fields[indexes[a]][field], fields[indexes[b]][field])
+			if desc {
+				return cmp > 0
+			}
+			return cmp < 0
+		})
+	}
+
+	s.mu.Lock()
+	top := s.pageSize
+	s.mu.Unlock()
+	skip := 0
+	if raw := query.Get("$top"); raw != "" {
+		if top, err = strconv.Atoi(raw); err != nil || top < 1 {
+			writeError(w, http.StatusBadRequest, "invalid $top")
+			return
+		}
+	}
+	if raw := query.Get("$skip"); raw != "" {
+		if skip, err = strconv.Atoi(raw); err != nil || skip < 0 {
+			writeError(w, http.StatusBadRequest, "invalid $skip")
+			return
+		}
+	}
+
+	response := listResponse{Count: len(indexes)}
+	page := make([]json.RawMessage, 0, top)
+	for i := skip; i < len(indexes) && i < skip+top; i++ {
+		page = append(page, values[indexes[i]])
+	}
+	response.Value = page
+
+	if skip+top < len(indexes) {
+		next := s.pageURL(r, skip+top, top)
+		response.Next = &next
+	}
+	if skip > 0 {
+		previous := s.pageURL(r, max(skip-top, 0), top)
+		response.Previous = &previous
+	}
+
+	writeJSON(w, r, response)
+}
+
+// pageURL returns the absolute URL of the page starting at skip
+func (s *Server) pageURL(r *http.Request, skip, top int) string {
+	query := r.URL.Query()
+	query.Set("$skip", strconv.Itoa(skip))
+	query.Set("$top", strconv.Itoa(top))
+	u := url.URL{Scheme: "http", Host: r.Host, Path: r.URL.Path, RawQuery: query.Encode()}
+	return u.String()
+}
+
+// condition is a single comparison of a $filter expression
+type condition struct {
+	field string
+	op    string
+	value string
+}
+
+// parseFilter parses a $filter of comparisons joined with "and", for example
+// "status eq 'running' and created gt 2024-01-01T00:00:00Z". Supported operators
+// are eq, ne, gt, ge, lt and le.
+func parseFilter(filter string) ([]condition, error) {
+	if strings.TrimSpace(filter) == "" {
+		return nil, nil
+	}
+
+	var conditions []condition
+	for _, clause := range splitAnd(filter) {
+		parts := strings.SplitN(strings.TrimSpace(clause), " ", 3)
+		if len(parts) != 3 {
+			return nil, fmt.Errorf("invalid $filter clause %q", clause)
+		}
+		op := strings.ToLower(parts[1])
+		switch op {
+		case "eq", "ne", "gt", "ge", "lt", "le":
+		default:
+			return nil, fmt.Errorf("unsupported $filter operator %q", parts[1])
+		}
+		conditions = append(conditions, condition{
+			field: parts[0],
+			op:    op,
+			value: strings.Trim(strings.TrimSpace(parts[2]), "'"),
+		})
+	}
+	return conditions, nil
+}
+
+// splitAnd splits a filter on " and " outside of quoted strings
+func splitAnd(filter string) []string {
+	var clauses []string
+	quoted := false
+	start := 0
+	for i := 0; i < len(filter); i++ {
+		switch {
+		case filter[i] == '\'':
+			quoted = !quoted
+		case !quoted && i+5 <= len(filter) && strings.EqualFold(filter[i:i+5], " and "):
+			clauses = append(clauses, filter[start:i])
+			start = i + 5
+			i += 4
+		}
+	}
+	return append(clauses, filter[start:])
+}
+
+// parseOrderBy parses an $orderby of a single field with an optional direction
+func parseOrderBy(orderBy string) (string, bool, error) {
+	parts := strings.Fields(orderBy)
+	switch {
+	case len(parts) == 1:
+		return parts[0], false, nil
+	case len(parts) == 2 && strings.EqualFold(parts[1], "asc"):
+		return parts[0], false, nil
+	case len(parts) == 2 && strings.EqualFold(parts[1], "desc"):
+		return parts[0], true, nil
+	}
+	return "", false, fmt.Errorf("invalid $orderby %q", orderBy)
+}
+
+// matchesAll returns true if the item satisfies every condition
+func matchesAll(item map[string]any, conditions []condition) bool {
+	for _, c := range conditions {
+		cmp := compare(item[c.field], c.value)
+		var ok bool
+		switch c.op {
+		case "eq":
+			ok = cmp == 0
+		case "ne":
+			ok = cmp != 0
+		case "gt":
+			ok = cmp > 0
+		case "ge":
+			ok = cmp >= 0
+		case "lt":
+			ok = cmp < 0
+		case "le":
+			ok = cmp <= 0
+		}
+		if !ok {
+			return false
+		}
+	}
+	return true
+}
+
+// compare compares two JSON values as numbers, times or strings, in that order of preference
+func compare(a, b any) int {
+	as, bs := fmt.Sprint(a), fmt.Sprint(b)
+	if a == nil {
+		as = "null"
+	}
+	if b == nil {
+		bs = "null"
+	}
+
+	if af, err := strconv.ParseFloat(as, 64); err == nil {
+		if bf, err := strconv.ParseFloat(bs, 64); err == nil {
+			return compareOrdered(af, bf)
+		}
+	}
+	if at, err := time.Parse(time.RFC3339Nano, as); err == nil {
+		if bt, err := time.Parse(time.RFC3339Nano, bs); err == nil {
+			return at.Compare(bt)
+		}
+	}
+	return strings.Compare(as, bs)
+}
+
+func compareOrdered(a, b float64) int {
+	switch {
+	case a < b:
+		return -1
+	case a > b:
+		return 1
+	}
+	return 0
+}
@@ -0,0 +1,423 @@
+// Package k6fake provides an in-process stand-in for the Grafana Cloud k6 v6 API,
+// so tests can drive the real k6client.Client and everything built on it over HTTP.
+package k6fake
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/grafana-cloud-k6-prometheus-exporter/internal/k6client"
+)
+
+// DefaultPageSize is the page size of list endpoints without $top
+const DefaultPageSize = 10
+
+// Server is an httptest server serving the v6 endpoints used by the exporter.
+// Projects, tests, runs and schedules are seeded with the Add methods, failures
+// are scripted with Fail and every request is recorded.
+type Server struct {
+	*httptest.Server
+
+	token   string
+	stackID string
+
+	mu        sync.Mutex
+	pageSize  int
+	projects  []k6client.Project
+	tests     []k6client.Test
+	runs      []k6client.TestRun
+	schedules []k6client.Schedule
+	failures  []*Failure
+	requests  []Request
+}
+
+// Failure scripts an error response or a delay for the matching requests
+type Failure struct {
+	Method     string        // Matches any method if empty
+	Path       string        // Path prefix, matches any path if empty
+	StatusCode int           // Status to respond with, 0 serves the request normally after Delay
+	Header     http.Header   // Extra response headers, e.g. Retry-After
+	Delay      time.Duration // Delay before responding, cut short if the client gives up
+	Times      int           // Number of matching requests affected, 0 affects all of them
+}
+
+// Request is a request received by the server
+type Request struct {
+	Method     string
+	Path       string
+	Query      url.Values
+	Header     http.Header
+	StatusCode int
+}
+
+// NewServer starts a server that accepts the token and stack ID. It must be closed by the caller.
+func NewServer(token, stackID string) *Server {
+	s := &Server{
+		token:    token,
+		stackID:  stackID,
+		pageSize: DefaultPageSize,
+	}
+	s.Server = httptest.NewServer(http.HandlerFunc(s.serveHTTP))
+	return s
+}
+
+// SetPageSize sets the page size of list endpoints without $top
+func (s *Server) SetPageSize(size int) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.pageSize = size
+}
+
+// AddProject adds a project or replaces the project with the same ID
+func (s *Server) AddProject(project k6client.Project) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.projects = upsert(s.projects, project, func(p k6client.Project) int { return p.ID })
+}
+
+// AddTest adds a test or replaces the test with the same ID
+func (s *Server) AddTest(test k6client.Test) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.tests = upsert(s.tests, test, func(t k6client.Test) int { return t.ID })
+}
+
+// AddRun adds a test run or replaces the run with the same ID. The project is
+// taken from the test if the run has none.
+func (s *Server) AddRun(run k6client.TestRun) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if run.ProjectID == 0 {
+		for _, test := range s.tests {
+			if test.ID == run.TestID {
+				run.ProjectID = test.ProjectID
+			}
+		}
+	}
+	s.runs = upsert(s.runs, run, func(r k6client.TestRun) int { return r.ID })
+}
+
+// AddSchedule adds a schedule or replaces the schedule with the same ID
+func (s *Server) AddSchedule(schedule k6client.Schedule) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.schedules = upsert(s.schedules, schedule, func(sc k6client.Schedule) int { return sc.ID })
+}
+
+// DeleteTest deletes a test with its runs and schedules
+func (s *Server) DeleteTest(testID int) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.tests = remove(s.tests, func(t k6client.Test) bool { return t.ID == testID })
+	s.runs = remove(s.runs, func(r k6client.TestRun) bool { return r.TestID == testID })
+	s.schedules = remove(s.schedules, func(sc k6client.Schedule) bool { return sc.LoadTestID == testID })
+}
+
+// Run returns the current state of a test run
+func (s *Server) Run(runID int) (k6client.TestRun, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, run := range s.runs {
+		if run.ID == runID {
+			return run, true
+		}
+	}
+	return k6client.TestRun{}, false
+}
+
+// Fail scripts a failure. Failures are matched in the order they were added.
+func (s *Server) Fail(failure Failure) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	f := failure
+	s.failures = append(s.failures, &f)
+}
+
+// ClearFailures removes all scripted failures
+func (s *Server) ClearFailures() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.failures = nil
+}
+
+// Requests returns the requests received so far
+func (s *Server) Requests() []Request {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]Request(nil), s.requests...)
+}
+
+// ResetRequests forgets the recorded requests
+func (s *Server) ResetRequests() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.requests = nil
+}
+
+// statusRecorder records the status code written by a handler
+type statusRecorder struct {
+	http.ResponseWriter
+	statusCode int
+}
+
+func (r *statusRecorder) WriteHeader(statusCode int) {
+	r.statusCode = statusCode
+	r.ResponseWriter.WriteHeader(statusCode)
+}
+
+func (s *Server) serveHTTP(w http.ResponseWriter, r *http.Request) {
+	recorder := &statusRecorder{ResponseWriter: w, statusCode: http.StatusOK}
+	defer func() {
+		s.mu.Lock()
+		defer s.mu.Unlock()
+		s.requests = append(s.requests, Request{
+			Method:     r.Method,
+			Path:       r.URL.Path,
+			Query:      r.URL.Query(),
+			Header:     r.Header.Clone(),
+			StatusCode: recorder.statusCode,
+		})
+	}()
+
+	if failure := s.matchFailure(r); failure != nil {
+		if failure.Delay > 0 {
+			timer := time.NewTimer(failure.Delay)
+			select {
+			case <-r.Context().Done():
+				timer.Stop()
+				return
+			case <-timer.C:
+			}
+		}
+		if failure.StatusCode != 0 {
+			for name, values := range failure.Header {
+				recorder.Header()[name] = values
+			}
+			writeError(recorder, failure.StatusCode, http.StatusText(failure.StatusCode))
+			return
+		}
+	}
+
+	if r.Header.Get("Authorization") != "Bearer "+s.token {
+		writeError(recorder, http.StatusUnauthorized, "Invalid token.")
+		return
+	}
+	if r.Header.Get("X-Stack-Id") != s.stackID {
+		writeError(recorder, http.StatusForbidden, "Unknown stack.")
+		return
+	}
+
+	s.route(recorder, r)
+}
+
+// matchFailure returns the first scripted failure matching the request and uses it up
+func (s *Server) matchFailure(r *http.Request) *Failure {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for i, failure := range s.failures {
+		if failure.Method != "" && failure.Method != r.Method {
+			continue
+		}
+		if !strings.HasPrefix(r.URL.Path, failure.Path) {
+			continue
+		}
+		if failure.Times > 0 {
+			failure.Times--
+			if failure.Times == 0 {
+				s.failures = append(s.failures[:i:i], s.failures[i+1:]...)
+			}
+		}
+		return failure
+	}
+	return nil
+}
+
+// route dispatches the request to the endpoint handlers
+func (s *Server) route(w http.ResponseWriter, r *http.Request) {
+	parts := strings.Split(strings.Trim(strings.TrimPrefix(r.URL.Path, "/cloud/v6"), "/"), "/")
+
+	switch {
+	case r.Method == http.MethodGet && len(parts) == 1 && parts[0] == "projects":
+		s.mu.Lock()
+		projects := append([]k6client.Project(nil), s.projects...)
+		s.mu.Unlock()
+		s.writeList(w, r, projects)
+
+	case r.Method == http.MethodGet && len(parts) == 1 && parts[0] == "load_tests":
+		s.writeList(w, r, s.filterTests(func(k6client.Test) bool { return true }))
+
+	case r.Method == http.MethodGet && len(parts) == 3 && parts[0] == "projects" && parts[2] == "load_tests":
+		projectID, ok := s.lookupProject(parts[1])
+		if !ok {
+			writeError(w, http.StatusNotFound, "Project not found.")
+			return
+		}
+		s.writeList(w, r, s.filterTests(func(t k6client.Test) bool { return t.ProjectID == projectID }))
+
+	case r.Method == http.MethodGet && len(parts) == 3 && parts[0] == "load_tests" && parts[2] == "test_runs":
+		testID, ok := s.lookupTest(parts[1])
+		if !ok {
+			writeError(w, http.StatusNotFound, "Load test not found.")
+			return
+		}
+		s.writeList(w, r, s.filterRuns(func(run k6client.TestRun) bool { return run.TestID == testID }))
+
+	case r.Method == http.MethodGet && len(parts) == 4 && parts[0] == "load_tests" && parts[2] == "test_runs":
+		testID, _ := strconv.Atoi(parts[1])
+		runID, _ := strconv.Atoi(parts[3])
+		runs := s.filterRuns(func(run k6client.TestRun) bool { return run.TestID == testID && run.ID == runID })
+		if len(runs) == 0 {
+			writeError(w, http.StatusNotFound, "Test run not found.")
+			return
+		}
+		writeJSON(w, r, runs[0])
+
+	case r.Method == http.MethodPost && len(parts) == 3 && parts[0] == "test_runs" && parts[2] == "abort":
+		runID, _ := strconv.Atoi(parts[1])
+		s.abort(w, runID)
+
+	case r.Method == http.MethodGet && len(parts) == 1 && parts[0] == "schedules":
+		s.mu.Lock()
+		schedules := append([]k6client.Schedule(nil), s.schedules...)
+		s.mu.Unlock()
+		s.writeList(w, r, schedules)
+
+	default:
+		writeError(w, http.StatusNotFound, "Not found.")
+	}
+}
+
+// abort aborts an active test run
+func (s *Server) abort(w http.ResponseWriter, runID int) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for i := range s.runs {
+		run := &s.runs[i]
+		if run.ID != runID {
+			continue
+		}
+		if k6client.IsTerminalStatus(run.Status) {
+			writeError(w, http.StatusConflict, "Test run is not active.")
+			return
+		}
+		now := time.Now().UTC()
+		run.Status = k6client.StatusAborted
+		run.Ended = &now
+		run.StatusHistory = append(run.StatusHistory, k6client.StatusHistoryEntry{Type: k6client.StatusAborted, Entered: now})
+		w.WriteHeader(http.StatusNoContent)
+		return
+	}
+	writeError(w, http.StatusNotFound, "Test run not found.")
+}
+
+// lookupProject parses a project ID and returns it if the project exists
+func (s *Server) lookupProject(raw string) (int, bool) {
+	projectID, err := strconv.Atoi(raw)
+	if err != nil {
+		return 0, false
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, project := range s.projects {
+		if project.ID == projectID {
+			return projectID, true
+		}
+	}
+	return 0, false
+}
+
+// lookupTest parses a test ID and returns it if the test exists
+func (s *Server) lookupTest(raw string) (int, bool) {
+	testID, err := strconv.Atoi(raw)
+	if err != nil {
+		return 0, false
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, test := range s.tests {
+		if test.ID == testID {
+			return testID, true
+		}
+	}
+	return 0, false
+}
+
+// filterTests returns a copy of the tests that match
+func (s *Server) filterTests(match func(k6client.Test) bool) []k6client.Test {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return filter(s.tests, match)
+}
+
+// filterRuns returns a copy of the runs that match
+func (s *Server) filterRuns(match func(k6client.TestRun) bool) []k6client.TestRun {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return filter(s.runs, match)
+}
+
+// writeJSON writes the value with an ETag, answering a matching If-None-Match with 304
+func writeJSON(w http.ResponseWriter, r *http.Request, value any) {
+	body, err := json.Marshal(value)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	sum := sha256.Sum256(body)
+	etag := `"` + hex.EncodeToString(sum[:8]) + `"`
+	w.Header().Set("ETag", etag)
+	if r.Header.Get("If-None-Match") == etag {
+		w.WriteHeader(http.StatusNotModified)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.Write(body)
+}
+
+// writeError writes an error response in the format of the k6 API
+func writeError(w http.ResponseWriter, statusCode int, message string) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(statusCode)
+	json.NewEncoder(w).Encode(map[string]any{
+		"error": map[string]string{"message": message},
+	})
+}
+
+// upsert replaces the item with the same ID or appends it
+func upsert[T any](items []T, item T, id func(T) int) []T {
+	for i := range items {
+		if id(items[i]) == id(item) {
+			items[i] = item
+			return items
+		}
+	}
+	return append(items, item)
+}
+
+// remove returns the items that do not match
+func remove[T any](items []T, match func(T) bool) []T {
+	return filter(items, func(item T) bool { return !match(item) })
+}
+
+// filter returns a copy of the items that match
+func filter[T any](items []T, match func(T) bool) []T {
+	matched := make([]T, 0, len(items))
+	for _, item := range items {
+		if match(item) {
+			matched = append(matched, item)
+		}
+	}
+	return matched
+}
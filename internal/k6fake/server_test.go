@@ -0,0 +1,187 @@
+package k6fake
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap/zaptest"
+
+	"github.com/grafana-cloud-k6-prometheus-exporter/internal/k6client"
+)
+
+// newSeededServer returns a server with two projects, five tests and three runs
+func newSeededServer(t *testing.T) *Server {
+	t.Helper()
+
+	server := NewServer("token", "stack")
+	t.Cleanup(server.Close)
+
+	now := time.Now().UTC().Truncate(time.Second)
+	server.AddProject(k6client.Project{ID: 100, Name: "Checkout"})
+	server.AddProject(k6client.Project{ID: 200, Name: "Search"})
+	for id := 1; id <= 4; id++ {
+		server.AddTest(k6client.Test{ID: id, ProjectID: 100, Name: "Checkout " + string(rune('A'+id-1))})
+	}
+	server.AddTest(k6client.Test{ID: 5, ProjectID: 200, Name: "Search"})
+	server.AddRun(k6client.TestRun{ID: 10, TestID: 1, Status: k6client.StatusCompleted, Created: now.Add(-3 * time.Hour), Ended: timePtr(now.Add(-2 * time.Hour))})
+	server.AddRun(k6client.TestRun{ID: 11, TestID: 1, Status: k6client.StatusRunning, Created: now.Add(-time.Minute)})
+	server.AddRun(k6client.TestRun{ID: 12, TestID: 1, Status: k6client.StatusCompleted, Created: now.Add(-2 * time.Hour), Ended: timePtr(now.Add(-time.Hour))})
+	return server
+}
+
+func newClient(t *testing.T, server *Server) *k6client.Client {
+	return k6client.NewClient(server.URL, "stack", "token", zaptest.NewLogger(t))
+}
+
+func timePtr(t time.Time) *time.Time {
+	return &t
+}
+
+func TestServerPagination(t *testing.T) {
+	server := newSeededServer(t)
+	server.SetPageSize(2)
+	client := newClient(t, server)
+
+	tests, err := client.ListTests(context.Background(), nil)
+	require.NoError(t, err)
+	assert.Len(t, tests, 5)
+
+	requests := server.Requests()
+	require.Len(t, requests, 3)
+	assert.Equal(t, "", requests[0].Query.Get("$skip"))
+	assert.Equal(t, "2", requests[1].Query.Get("$skip"))
+	assert.Equal(t, "4", requests[2].Query.Get("$skip"))
+
+	// Runs are ordered newest first and stop at the since time
+	since := time.Now().Add(-150 * time.Minute)
+	runs, err := client.ListTestRuns(context.Background(), 1, &since)
+	require.NoError(t, err)
+	require.Len(t, runs, 2)
+	assert.Equal(t, 11, runs[0].ID)
+	assert.Equal(t, 12, runs[1].ID)
+	assert.Equal(t, 100, runs[0].ProjectID, "the project is taken from the test")
+
+	projectTests, err := client.ListTests(context.Background(), intPtr(200))
+	require.NoError(t, err)
+	require.Len(t, projectTests, 1)
+	assert.Equal(t, "Search", projectTests[0].Name)
+
+	_, err = client.ListTests(context.Background(), intPtr(300))
+	assert.Equal(t, k6client.ErrorClassNotFound, k6client.ErrorClass(err))
+}
+
+func intPtr(i int) *int {
+	return &i
+}
+
+func TestServerFilter(t *testing.T) {
+	server := newSeededServer(t)
+
+	get := func(query url.Values) (int, []k6client.TestRun) {
+		req, err := http.NewRequest(http.MethodGet, server.URL+"/cloud/v6/load_tests/1/test_runs?"+query.Encode(), nil)
+		require.NoError(t, err)
+		req.Header.Set("Authorization", "Bearer token")
+		req.Header.Set("X-Stack-Id", "stack")
+		resp, err := http.DefaultClient.Do(req)
+		require.NoError(t, err)
+		defer resp.Body.Close()
+
+		var result k6client.TestRunListResponse
+		if resp.StatusCode == http.StatusOK {
+			require.NoError(t, json.NewDecoder(resp.Body).Decode(&result))
+		}
+		return resp.StatusCode, result.Value
+	}
+
+	status, runs := get(url.Values{"$filter": {"status eq 'completed'"}, "$orderby": {"created asc"}})
+	require.Equal(t, http.StatusOK, status)
+	require.Len(t, runs, 2)
+	assert.Equal(t, 10, runs[0].ID)
+
+	since := time.Now().Add(-150 * time.Minute).UTC().Format(time.RFC3339)
+	status, runs = get(url.Values{"$filter": {"status eq 'completed' and created gt " + since}})
+	require.Equal(t, http.StatusOK, status)
+	require.Len(t, runs, 1)
+	assert.Equal(t, 12, runs[0].ID)
+
+	status, _ = get(url.Values{"$filter": {"status contains 'run'"}})
+	assert.Equal(t, http.StatusBadRequest, status)
+}
+
+func TestServerFailures(t *testing.T) {
+	server := newSeededServer(t)
+	client := newClient(t, server)
+	ctx := context.Background()
+
+	// Failures are used up in order
+	server.Fail(Failure{Path: "/cloud/v6/projects", StatusCode: http.StatusTooManyRequests, Header: http.Header{"Retry-After": {"1"}}, Times: 1})
+	server.Fail(Failure{Path: "/cloud/v6/projects", StatusCode: http.StatusInternalServerError, Times: 1})
+	_, err := client.ListProjects(ctx)
+	assert.Equal(t, k6client.ErrorClassRateLimited, k6client.ErrorClass(err))
+	_, err = client.ListProjects(ctx)
+	assert.Equal(t, k6client.ErrorClassServerError, k6client.ErrorClass(err))
+	projects, err := client.ListProjects(ctx)
+	require.NoError(t, err)
+	assert.Len(t, projects, 2)
+
+	// Slow responses are cut short when the client gives up
+	server.Fail(Failure{Method: http.MethodGet, Delay: time.Minute})
+	timeoutCtx, cancel := context.WithTimeout(ctx, 50*time.Millisecond)
+	defer cancel()
+	_, err = client.ListSchedules(timeoutCtx)
+	assert.Equal(t, k6client.ErrorClassTimeout, k6client.ErrorClass(err))
+	server.ClearFailures()
+
+	requests := server.Requests()
+	assert.Equal(t, http.StatusTooManyRequests, requests[0].StatusCode)
+	assert.Equal(t, "Bearer token", requests[0].Header.Get("Authorization"))
+
+	// Requests with a wrong token are rejected
+	_, err = k6client.NewClient(server.URL, "stack", "wrong", zaptest.NewLogger(t)).ListProjects(ctx)
+	assert.Equal(t, k6client.ErrorClassUnauthorized, k6client.ErrorClass(err))
+}
+
+func TestServerAbortAndConditionalRequests(t *testing.T) {
+	server := newSeededServer(t)
+	client := newClient(t, server).WithCache(k6client.NewResponseCache(10, prometheus.NewRegistry()))
+	ctx := context.Background()
+
+	require.NoError(t, client.AbortTestRun(ctx, 11))
+	run, ok := server.Run(11)
+	require.True(t, ok)
+	assert.Equal(t, k6client.StatusAborted, run.Status)
+	assert.NotNil(t, run.Ended)
+
+	err := client.AbortTestRun(ctx, 11)
+	var apiErr *k6client.APIError
+	require.ErrorAs(t, err, &apiErr)
+	assert.Equal(t, http.StatusConflict, apiErr.StatusCode)
+
+	// Unchanged lists are answered with 304
+	server.ResetRequests()
+	for i := 0; i < 2; i++ {
+		schedules, err := client.ListSchedules(ctx)
+		require.NoError(t, err)
+		assert.Empty(t, schedules)
+	}
+	requests := server.Requests()
+	require.Len(t, requests, 2)
+	assert.Equal(t, http.StatusOK, requests[0].StatusCode)
+	assert.Equal(t, http.StatusNotModified, requests[1].StatusCode)
+
+	// Deleted tests are gone with their runs
+	_, err = client.GetTestRun(ctx, 1, 10)
+	require.NoError(t, err)
+	server.DeleteTest(1)
+	_, err = client.GetTestRun(ctx, 1, 10)
+	assert.NoError(t, err, "finished runs are served from the cache")
+	_, err = client.ListTestRuns(ctx, 1, nil)
+	assert.Equal(t, k6client.ErrorClassNotFound, k6client.ErrorClass(err))
+}
@@ -0,0 +1,267 @@
+package notify
+
+import (
+	"fmt"
+	"net/url"
+	"os"
+	"regexp"
+	"time"
+
+	"gopkg.in/yaml.v3"
+
+	"github.com/grafana-cloud-k6-prometheus-exporter/internal/state"
+)
+
+// Webhook defaults
+const (
+	defaultTimeout      = 10 * time.Second
+	defaultMaxRetries   = 3
+	defaultRetryBackoff = time.Second
+	defaultQueueSize    = 100
+)
+
+// Slack defaults
+const (
+	defaultGroupWindow = 30 * time.Second
+	defaultMinInterval = time.Second
+)
+
+// Config is the notifications configuration file
+type Config struct {
+	Webhooks []WebhookConfig `yaml:"webhooks"`
+	Slack    *SlackConfig    `yaml:"slack"`
+}
+
+// SlackConfig configures the Slack notifications of finished runs
+type SlackConfig struct {
+	Routes      []SlackRoute  `yaml:"routes"`
+	GroupWindow time.Duration `yaml:"group_window"` // Runs finishing within the window are sent as one message
+	MinInterval time.Duration `yaml:"min_interval"` // Minimum time between two messages of a route
+	QueueSize   int           `yaml:"queue_size"`
+}
+
+// SlackRoute sends the matching runs to a Slack incoming webhook
+type SlackRoute struct {
+	Name        string   `yaml:"name"`
+	WebhookURL  string   `yaml:"webhook_url"`
+	Projects    []int    `yaml:"projects"` // Project IDs to notify for, all if empty
+	TestPattern string   `yaml:"test_pattern"`
+	Results     []string `yaml:"results"` // Results to notify for, failed and aborted by default
+
+	testPattern *regexp.Regexp
+}
+
+// WebhookConfig configures a single webhook receiver
+type WebhookConfig struct {
+	Name         string            `yaml:"name"`
+	URL          string            `yaml:"url"`
+	Secret       string            `yaml:"secret"` // Signs the payload with HMAC-SHA256 if set
+	Headers      map[string]string `yaml:"headers"`
+	Events       []string          `yaml:"events"`   // Event types to send, all but status_changed if empty
+	Projects     []int             `yaml:"projects"` // Project IDs to send events for, all if empty
+	TestPattern  string            `yaml:"test_pattern"`
+	Results      []string          `yaml:"results"` // Results of run_finished events to send, all if empty
+	Timeout      time.Duration     `yaml:"timeout"`
+	MaxRetries   *int              `yaml:"max_retries"`
+	RetryBackoff time.Duration     `yaml:"retry_backoff"`
+	QueueSize    int               `yaml:"queue_size"`
+
+	testPattern *regexp.Regexp
+}
+
+// LoadConfig reads and validates a notifications configuration file
+func LoadConfig(path string) (*Config, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("read notifications config: %w", err)
+	}
+
+	var cfg Config
+	if err := yaml.Unmarshal(data, &cfg); err != nil {
+		return nil, fmt.Errorf("parse notifications config: %w", err)
+	}
+
+	if err := cfg.Validate(); err != nil {
+		return nil, fmt.Errorf("invalid notifications config: %w", err)
+	}
+
+	return &cfg, nil
+}
+
+// Validate checks the configuration and applies the defaults
+func (c *Config) Validate() error {
+	names := make(map[string]bool, len(c.Webhooks))
+	for i := range c.Webhooks {
+		webhook := &c.Webhooks[i]
+		if webhook.Name == "" {
+			return fmt.Errorf("webhooks[%d]: name is required", i)
+		}
+		if names[webhook.Name] {
+			return fmt.Errorf("webhooks[%d]: duplicate name %q", i, webhook.Name)
+		}
+		names[webhook.Name] = true
+
+		if err := webhook.validate(); err != nil {
+			return fmt.Errorf("webhook %q: %w", webhook.Name, err)
+		}
+	}
+
+	if c.Slack != nil {
+		if err := c.Slack.validate(); err != nil {
+			return fmt.Errorf("slack: %w", err)
+		}
+	}
+	return nil
+}
+
+// validate checks the Slack configuration and applies the defaults
+func (s *SlackConfig) validate() error {
+	if len(s.Routes) == 0 {
+		return fmt.Errorf("at least one route is required")
+	}
+
+	names := make(map[string]bool, len(s.Routes))
+	for i := range s.Routes {
+		route := &s.Routes[i]
+		if route.Name == "" {
+			return fmt.Errorf("routes[%d]: name is required", i)
+		}
+		if names[route.Name] {
+			return fmt.Errorf("routes[%d]: duplicate name %q", i, route.Name)
+		}
+		names[route.Name] = true
+
+		u, err := url.Parse(route.WebhookURL)
+		if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+			return fmt.Errorf("route %q: webhook_url must be an http or https URL", route.Name)
+		}
+		if route.TestPattern != "" {
+			pattern, err := regexp.Compile(route.TestPattern)
+			if err != nil {
+				return fmt.Errorf("route %q: invalid test_pattern: %w", route.Name, err)
+			}
+			route.testPattern = pattern
+		}
+		if len(route.Results) == 0 {
+			route.Results = []string{"failed", "aborted"}
+		}
+	}
+
+	if s.GroupWindow < 0 {
+		return fmt.Errorf("group_window must not be negative")
+	}
+	if s.GroupWindow == 0 {
+		s.GroupWindow = defaultGroupWindow
+	}
+	if s.MinInterval <= 0 {
+		s.MinInterval = defaultMinInterval
+	}
+	if s.QueueSize <= 0 {
+		s.QueueSize = defaultQueueSize
+	}
+	return nil
+}
+
+// matches returns true if the route watches the finished run
+func (r *SlackRoute) matches(event state.Event) bool {
+	if event.Type != state.EventRunFinished || !contains(r.Results, event.Result) {
+		return false
+	}
+	if len(r.Projects) > 0 && !containsInt(r.Projects, event.ProjectID) {
+		return false
+	}
+	if r.testPattern != nil && !r.testPattern.MatchString(event.TestName) {
+		return false
+	}
+	return true
+}
+
+// validate checks a webhook and applies the defaults
+func (w *WebhookConfig) validate() error {
+	u, err := url.Parse(w.URL)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return fmt.Errorf("url must be an http or https URL")
+	}
+
+	for _, eventType := range w.Events {
+		if !isEventType(eventType) {
+			return fmt.Errorf("invalid event %q", eventType)
+		}
+	}
+
+	if w.TestPattern != "" {
+		pattern, err := regexp.Compile(w.TestPattern)
+		if err != nil {
+			return fmt.Errorf("invalid test_pattern: %w", err)
+		}
+		w.testPattern = pattern
+	}
+
+	if w.Timeout <= 0 {
+		w.Timeout = defaultTimeout
+	}
+	if w.MaxRetries == nil {
+		retries := defaultMaxRetries
+		w.MaxRetries = &retries
+	} else if *w.MaxRetries < 0 {
+		return fmt.Errorf("max_retries must not be negative")
+	}
+	if w.RetryBackoff <= 0 {
+		w.RetryBackoff = defaultRetryBackoff
+	}
+	if w.QueueSize <= 0 {
+		w.QueueSize = defaultQueueSize
+	}
+
+	return nil
+}
+
+// matches returns true if the event passes the webhook filters
+func (w *WebhookConfig) matches(event state.Event) bool {
+	if len(w.Events) > 0 && !contains(w.Events, event.Type) {
+		return false
+	}
+	if len(w.Events) == 0 && event.Type == state.EventStatusChanged {
+		// Status changes are only sent when asked for explicitly
+		return false
+	}
+	if len(w.Projects) > 0 && !containsInt(w.Projects, event.ProjectID) {
+		return false
+	}
+	if w.testPattern != nil && !w.testPattern.MatchString(event.TestName) {
+		return false
+	}
+	if len(w.Results) > 0 && event.Type == state.EventRunFinished && !contains(w.Results, event.Result) {
+		return false
+	}
+	return true
+}
+
+// isEventType returns true for the event types that can be sent
+func isEventType(eventType string) bool {
+	switch eventType {
+	case state.EventRunStarted, state.EventStatusChanged, state.EventRunFinished, state.EventRunStuck:
+		return true
+	}
+	return false
+}
+
+// contains returns true if the slice contains the value
+func contains(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
+
+// containsInt returns true if the slice contains the value
+func containsInt(values []int, value int) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
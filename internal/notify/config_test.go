@@ -0,0 +1,227 @@
+package notify
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana-cloud-k6-prometheus-exporter/internal/state"
+)
+
+func TestLoadConfig(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+		wantErr bool
+		errMsg  string
+		verify  func(t *testing.T, cfg *Config)
+	}{
+		{
+			name: "defaults",
+			content: `
+webhooks:
+  - name: ci
+    url: https://hooks.example.com/k6
+`,
+			verify: func(t *testing.T, cfg *Config) {
+				require.Len(t, cfg.Webhooks, 1)
+				webhook := cfg.Webhooks[0]
+				assert.Equal(t, 10*time.Second, webhook.Timeout)
+				assert.Equal(t, 3, *webhook.MaxRetries)
+				assert.Equal(t, time.Second, webhook.RetryBackoff)
+				assert.Equal(t, 100, webhook.QueueSize)
+			},
+		},
+		{
+			name: "filters",
+			content: `
+webhooks:
+  - name: checkout
+    url: https://hooks.example.com/k6
+    secret: s3cr3t
+    events: [run_finished, run_stuck]
+    projects: [100]
+    test_pattern: ^checkout-
+    results: [failed, aborted]
+    max_retries: 0
+`,
+			verify: func(t *testing.T, cfg *Config) {
+				webhook := cfg.Webhooks[0]
+				assert.Equal(t, 0, *webhook.MaxRetries)
+				assert.NotNil(t, webhook.testPattern)
+			},
+		},
+		{
+			name: "slack_defaults",
+			content: `
+slack:
+  routes:
+    - name: checkout
+      webhook_url: https://hooks.slack.com/services/T000/B000/XXX
+      test_pattern: ^checkout-
+`,
+			verify: func(t *testing.T, cfg *Config) {
+				require.NotNil(t, cfg.Slack)
+				assert.Equal(t, 30*time.Second, cfg.Slack.GroupWindow)
+				assert.Equal(t, time.Second, cfg.Slack.MinInterval)
+				assert.Equal(t, 100, cfg.Slack.QueueSize)
+				assert.Equal(t, []string{"failed", "aborted"}, cfg.Slack.Routes[0].Results)
+			},
+		},
+		{
+			name: "slack_without_routes",
+			content: `
+slack:
+  group_window: 1m
+`,
+			wantErr: true,
+			errMsg:  "at least one route is required",
+		},
+		{
+			name: "slack_invalid_url",
+			content: `
+slack:
+  routes:
+    - name: checkout
+      webhook_url: hooks.slack.com
+`,
+			wantErr: true,
+			errMsg:  "webhook_url must be an http or https URL",
+		},
+		{
+			name: "missing_name",
+			content: `
+webhooks:
+  - url: https://hooks.example.com/k6
+`,
+			wantErr: true,
+			errMsg:  "name is required",
+		},
+		{
+			name: "duplicate_name",
+			content: `
+webhooks:
+  - name: ci
+    url: https://hooks.example.com/a
+  - name: ci
+    url: https://hooks.example.com/b
+`,
+			wantErr: true,
+			errMsg:  "duplicate name",
+		},
+		{
+			name: "invalid_url",
+			content: `
+webhooks:
+  - name: ci
+    url: hooks.example.com
+`,
+			wantErr: true,
+			errMsg:  "url must be an http or https URL",
+		},
+		{
+			name: "invalid_event",
+			content: `
+webhooks:
+  - name: ci
+    url: https://hooks.example.com/k6
+    events: [run_exploded]
+`,
+			wantErr: true,
+			errMsg:  "invalid event",
+		},
+		{
+			name: "invalid_pattern",
+			content: `
+webhooks:
+  - name: ci
+    url: https://hooks.example.com/k6
+    test_pattern: "("
+`,
+			wantErr: true,
+			errMsg:  "invalid test_pattern",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "notifications.yaml")
+			require.NoError(t, os.WriteFile(path, []byte(tt.content), 0o600))
+
+			cfg, err := LoadConfig(path)
+			if tt.wantErr {
+				require.Error(t, err)
+				assert.Contains(t, err.Error(), tt.errMsg)
+				return
+			}
+			require.NoError(t, err)
+			tt.verify(t, cfg)
+		})
+	}
+}
+
+func TestWebhookMatches(t *testing.T) {
+	cfg := &Config{Webhooks: []WebhookConfig{{
+		Name:        "checkout",
+		URL:         "https://hooks.example.com/k6",
+		Projects:    []int{100},
+		TestPattern: "^checkout-",
+		Results:     []string{"failed", "aborted"},
+	}}}
+	require.NoError(t, cfg.Validate())
+	webhook := &cfg.Webhooks[0]
+
+	tests := []struct {
+		name  string
+		event state.Event
+		want  bool
+	}{
+		{name: "failed", event: state.Event{Type: state.EventRunFinished, ProjectID: 100, TestName: "checkout-api", Result: "failed"}, want: true},
+		{name: "passed", event: state.Event{Type: state.EventRunFinished, ProjectID: 100, TestName: "checkout-api", Result: "passed"}, want: false},
+		{name: "started", event: state.Event{Type: state.EventRunStarted, ProjectID: 100, TestName: "checkout-api"}, want: true},
+		{name: "stuck", event: state.Event{Type: state.EventRunStuck, ProjectID: 100, TestName: "checkout-web"}, want: true},
+		{name: "status_changed_not_default", event: state.Event{Type: state.EventStatusChanged, ProjectID: 100, TestName: "checkout-api"}, want: false},
+		{name: "other_project", event: state.Event{Type: state.EventRunStarted, ProjectID: 200, TestName: "checkout-api"}, want: false},
+		{name: "other_test", event: state.Event{Type: state.EventRunStarted, ProjectID: 100, TestName: "search"}, want: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, webhook.matches(tt.event))
+		})
+	}
+}
+
+func TestSlackRouteMatches(t *testing.T) {
+	cfg := &Config{Slack: &SlackConfig{Routes: []SlackRoute{{
+		Name:        "checkout",
+		WebhookURL:  "https://hooks.slack.com/services/T000/B000/XXX",
+		Projects:    []int{100},
+		TestPattern: "^checkout-",
+	}}}}
+	require.NoError(t, cfg.Validate())
+	route := &cfg.Slack.Routes[0]
+
+	tests := []struct {
+		name  string
+		event state.Event
+		want  bool
+	}{
+		{name: "failed", event: state.Event{Type: state.EventRunFinished, ProjectID: 100, TestName: "checkout-api", Result: "failed"}, want: true},
+		{name: "aborted", event: state.Event{Type: state.EventRunFinished, ProjectID: 100, TestName: "checkout-api", Result: "aborted"}, want: true},
+		{name: "passed", event: state.Event{Type: state.EventRunFinished, ProjectID: 100, TestName: "checkout-api", Result: "passed"}, want: false},
+		{name: "stuck", event: state.Event{Type: state.EventRunStuck, ProjectID: 100, TestName: "checkout-api"}, want: false},
+		{name: "other_project", event: state.Event{Type: state.EventRunFinished, ProjectID: 200, TestName: "checkout-api", Result: "failed"}, want: false},
+		{name: "other_test", event: state.Event{Type: state.EventRunFinished, ProjectID: 100, TestName: "search", Result: "failed"}, want: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, route.matches(tt.event))
+		})
+	}
+}
@@ -0,0 +1,314 @@
+package notify
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"go.uber.org/zap"
+
+	"github.com/grafana-cloud-k6-prometheus-exporter/internal/state"
+)
+
+// Runs listed in a grouped Slack message, the others are summarized
+const maxGroupedRuns = 10
+
+// Slack delivery limits
+const (
+	slackMaxAttempts   = 3
+	slackMaxRetryAfter = time.Minute
+)
+
+// slackRoute is a configured Slack route with its queue
+type slackRoute struct {
+	config   *SlackRoute
+	receiver string // Label used in metrics and logs
+	client   *http.Client
+	queue    chan state.Event
+}
+
+// slackMessage is the body of a Slack incoming webhook request
+type slackMessage struct {
+	Text   string       `json:"text"` // Fallback for notifications
+	Blocks []slackBlock `json:"blocks"`
+}
+
+// slackBlock is a Block Kit layout block
+type slackBlock struct {
+	Type     string      `json:"type"`
+	Text     *slackText  `json:"text,omitempty"`
+	Fields   []slackText `json:"fields,omitempty"`
+	Elements []slackText `json:"elements,omitempty"`
+}
+
+// slackText is a Block Kit text object
+type slackText struct {
+	Type string `json:"type"`
+	Text string `json:"text"`
+}
+
+// handleSlack queues a finished run for every matching Slack route
+func (d *Dispatcher) handleSlack(event state.Event) {
+	event.StartedBy = d.starter(event.StartedBy)
+	for _, route := range d.slackRoutes {
+		if !route.config.matches(event) {
+			continue
+		}
+
+		select {
+		case route.queue <- event:
+			d.metrics.QueueLength.WithLabelValues(route.receiver).Set(float64(len(route.queue)))
+		default:
+			d.metrics.DeliveriesTotal.WithLabelValues(route.receiver, OutcomeDropped).Inc()
+			d.logger.Warn("slack queue is full, dropping event",
+				zap.String("route", route.config.Name),
+				zap.Int("run_id", event.RunID),
+			)
+		}
+	}
+}
+
+// runSlack sends the queued runs of a route. Runs finishing within the group
+// window of the first one are sent as a single message, and messages are at
+// least the minimum interval apart.
+func (d *Dispatcher) runSlack(ctx context.Context, route *slackRoute) {
+	var lastSent time.Time
+
+	for {
+		var batch []state.Event
+		select {
+		case <-ctx.Done():
+			return
+		case event := <-route.queue:
+			batch = append(batch, event)
+		}
+
+		window := time.NewTimer(d.slack.GroupWindow)
+	group:
+		for {
+			select {
+			case <-ctx.Done():
+				window.Stop()
+				return
+			case event := <-route.queue:
+				batch = append(batch, event)
+			case <-window.C:
+				break group
+			}
+		}
+		d.metrics.QueueLength.WithLabelValues(route.receiver).Set(float64(len(route.queue)))
+
+		if wait := d.slack.MinInterval - time.Since(lastSent); wait > 0 {
+			select {
+			case <-ctx.Done():
+				return
+			case <-time.After(wait):
+			}
+		}
+
+		outcome := OutcomeSuccess
+		if err := d.deliverSlack(ctx, route, newSlackMessage(batch)); err != nil {
+			outcome = OutcomeFailure
+			d.logger.Error("failed to deliver slack message",
+				zap.String("route", route.config.Name),
+				zap.Int("runs", len(batch)),
+				zap.Error(err),
+			)
+		}
+		lastSent = time.Now()
+		d.metrics.DeliveriesTotal.WithLabelValues(route.receiver, outcome).Add(float64(len(batch)))
+	}
+}
+
+// deliverSlack posts the message, waiting as long as Slack asks for when rate
+// limited and retrying server and network errors
+func (d *Dispatcher) deliverSlack(ctx context.Context, route *slackRoute, message slackMessage) error {
+	body, err := json.Marshal(message)
+	if err != nil {
+		return fmt.Errorf("encode message: %w", err)
+	}
+
+	for attempt := 1; ; attempt++ {
+		wait, err := route.post(ctx, body)
+		if err == nil {
+			return nil
+		}
+		if wait == 0 || attempt >= slackMaxAttempts {
+			return err
+		}
+
+		d.logger.Debug("retrying slack message",
+			zap.String("route", route.config.Name),
+			zap.Int("attempt", attempt),
+			zap.Duration("wait", wait),
+			zap.Error(err),
+		)
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(wait):
+		}
+	}
+}
+
+// post sends the body once and returns how long to wait before retrying, zero
+// if the failure must not be retried
+func (r *slackRoute) post(ctx context.Context, body []byte) (time.Duration, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, r.config.WebhookURL, bytes.NewReader(body))
+	if err != nil {
+		return 0, fmt.Errorf("create request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", "k6-prometheus-exporter")
+
+	resp, err := r.client.Do(req)
+	if err != nil {
+		return defaultRetryBackoff, fmt.Errorf("perform request: %w", err)
+	}
+	defer resp.Body.Close()
+	io.Copy(io.Discard, resp.Body)
+
+	switch {
+	case resp.StatusCode >= 200 && resp.StatusCode < 300:
+		return 0, nil
+	case resp.StatusCode == http.StatusTooManyRequests:
+		return retryAfter(resp.Header.Get("Retry-After")), fmt.Errorf("rate limited by slack")
+	case resp.StatusCode >= 500:
+		return defaultRetryBackoff, fmt.Errorf("unexpected status %d", resp.StatusCode)
+	default:
+		return 0, fmt.Errorf("unexpected status %d", resp.StatusCode)
+	}
+}
+
+// retryAfter parses the Retry-After header in seconds, bounded to a minute
+func retryAfter(value string) time.Duration {
+	seconds, err := strconv.Atoi(value)
+	if err != nil || seconds <= 0 {
+		return defaultRetryBackoff
+	}
+	wait := time.Duration(seconds) * time.Second
+	if wait > slackMaxRetryAfter {
+		wait = slackMaxRetryAfter
+	}
+	return wait
+}
+
+// newSlackMessage formats a single run in detail or a group of runs as a summary
+func newSlackMessage(events []state.Event) slackMessage {
+	if len(events) == 1 {
+		return newSlackRunMessage(events[0])
+	}
+
+	title := fmt.Sprintf("%d k6 test runs failed or were aborted", len(events))
+	message := slackMessage{
+		Text: title,
+		Blocks: []slackBlock{
+			{Type: "header", Text: &slackText{Type: "plain_text", Text: title}},
+		},
+	}
+
+	for i, event := range events {
+		if i == maxGroupedRuns {
+			message.Blocks = append(message.Blocks, slackBlock{
+				Type:     "context",
+				Elements: []slackText{{Type: "mrkdwn", Text: fmt.Sprintf("and %d more", len(events)-maxGroupedRuns)}},
+			})
+			break
+		}
+
+		details := []string{
+			fmt.Sprintf("%s *%s* %s", resultEmoji(event.Result), slackEscape(event.TestName), event.Result),
+			"project " + projectName(event),
+			runDuration(event),
+		}
+		if event.StartedBy != "" {
+			details = append(details, "by "+slackEscape(event.StartedBy))
+		}
+		if len(event.FailedThresholds) > 0 {
+			details = append(details, "thresholds: "+slackEscape(strings.Join(event.FailedThresholds, ", ")))
+		}
+		message.Blocks = append(message.Blocks, slackBlock{
+			Type: "section",
+			Text: &slackText{Type: "mrkdwn", Text: strings.Join(details, " · ")},
+		})
+	}
+
+	return message
+}
+
+// projectName returns the project ID, qualified with the stack if there are several
+func projectName(event state.Event) string {
+	if event.Stack != "" {
+		return fmt.Sprintf("%d (%s)", event.ProjectID, event.Stack)
+	}
+	return strconv.Itoa(event.ProjectID)
+}
+
+// newSlackRunMessage formats a single finished run
+func newSlackRunMessage(event state.Event) slackMessage {
+	title := fmt.Sprintf("k6 test %s %s", event.TestName, event.Result)
+
+	startedBy := event.StartedBy
+	if startedBy == "" {
+		startedBy = "unknown"
+	}
+
+	message := slackMessage{
+		Text: title,
+		Blocks: []slackBlock{
+			{Type: "header", Text: &slackText{Type: "plain_text", Text: resultEmoji(event.Result) + " " + title}},
+			{Type: "section", Fields: []slackText{
+				{Type: "mrkdwn", Text: "*Project*\n" + projectName(event)},
+				{Type: "mrkdwn", Text: "*Duration*\n" + runDuration(event)},
+				{Type: "mrkdwn", Text: "*Started by*\n" + slackEscape(startedBy)},
+				{Type: "mrkdwn", Text: fmt.Sprintf("*VUH*\n%.2f", event.VUH)},
+			}},
+		},
+	}
+
+	if len(event.FailedThresholds) > 0 {
+		lines := make([]string, 0, len(event.FailedThresholds))
+		for _, threshold := range event.FailedThresholds {
+			lines = append(lines, "• "+slackEscape(threshold))
+		}
+		message.Blocks = append(message.Blocks, slackBlock{
+			Type: "section",
+			Text: &slackText{Type: "mrkdwn", Text: "*Failed thresholds*\n" + strings.Join(lines, "\n")},
+		})
+	}
+
+	message.Blocks = append(message.Blocks, slackBlock{
+		Type:     "context",
+		Elements: []slackText{{Type: "mrkdwn", Text: fmt.Sprintf("Test %d · run %d", event.TestID, event.RunID)}},
+	})
+
+	return message
+}
+
+// runDuration formats the duration of a finished run
+func runDuration(event state.Event) string {
+	if event.Created.IsZero() || event.Time.Before(event.Created) {
+		return "unknown"
+	}
+	return event.Time.Sub(event.Created).Round(time.Second).String()
+}
+
+// resultEmoji returns the emoji shown for a run result
+func resultEmoji(result string) string {
+	if result == "aborted" {
+		return ":warning:"
+	}
+	return ":x:"
+}
+
+// slackEscape escapes the characters Slack uses for mrkdwn control sequences
+func slackEscape(text string) string {
+	return strings.NewReplacer("&", "&amp;", "<", "&lt;", ">", "&gt;").Replace(text)
+}
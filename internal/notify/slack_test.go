@@ -0,0 +1,152 @@
+package notify
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap/zaptest"
+
+	"github.com/grafana-cloud-k6-prometheus-exporter/internal/config"
+	"github.com/grafana-cloud-k6-prometheus-exporter/internal/state"
+)
+
+func newTestSlackDispatcher(t *testing.T, url string) *Dispatcher {
+	cfg := &Config{Slack: &SlackConfig{
+		Routes:      []SlackRoute{{Name: "perf", WebhookURL: url, Projects: []int{100}}},
+		GroupWindow: 50 * time.Millisecond,
+		MinInterval: time.Millisecond,
+	}}
+	require.NoError(t, cfg.Validate())
+	return NewDispatcher(cfg, zaptest.NewLogger(t), prometheus.NewRegistry())
+}
+
+func finishedEvent(runID int, result string) state.Event {
+	created := time.Date(2024, 1, 1, 10, 0, 0, 0, time.UTC)
+	return state.Event{
+		Type:      state.EventRunFinished,
+		RunID:     runID,
+		TestID:    5,
+		TestName:  "checkout",
+		ProjectID: 100,
+		Status:    "completed",
+		Result:    result,
+		StartedBy: "alice@example.com",
+		VUH:       1.25,
+		Created:   created,
+		Time:      created.Add(5 * time.Minute),
+	}
+}
+
+func TestSlackSendsFailedRun(t *testing.T) {
+	recv := &receiver{}
+	server := httptest.NewServer(recv)
+	defer server.Close()
+
+	d := newTestSlackDispatcher(t, server.URL)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	d.Start(ctx)
+
+	event := finishedEvent(1, "failed")
+	event.FailedThresholds = []string{"http_req_duration"}
+	d.Handle(event)
+	d.Handle(finishedEvent(2, "passed"))
+
+	require.Eventually(t, func() bool {
+		return testutil.ToFloat64(d.metrics.DeliveriesTotal.WithLabelValues("slack/perf", OutcomeSuccess)) == 1
+	}, time.Second, 5*time.Millisecond)
+
+	recv.mu.Lock()
+	defer recv.mu.Unlock()
+	require.Len(t, recv.bodies, 1)
+
+	var message slackMessage
+	require.NoError(t, json.Unmarshal(recv.bodies[0], &message))
+	assert.Equal(t, "k6 test checkout failed", message.Text)
+	require.Len(t, message.Blocks, 4)
+	assert.Equal(t, "header", message.Blocks[0].Type)
+	assert.Contains(t, message.Blocks[1].Fields, slackText{Type: "mrkdwn", Text: "*Duration*\n5m0s"})
+	assert.Contains(t, message.Blocks[1].Fields, slackText{Type: "mrkdwn", Text: "*Started by*\nalice@example.com"})
+	assert.Contains(t, message.Blocks[1].Fields, slackText{Type: "mrkdwn", Text: "*VUH*\n1.25"})
+	assert.Equal(t, "*Failed thresholds*\n• http_req_duration", message.Blocks[2].Text.Text)
+}
+
+func TestSlackGroupsBursts(t *testing.T) {
+	recv := &receiver{}
+	server := httptest.NewServer(recv)
+	defer server.Close()
+
+	d := newTestSlackDispatcher(t, server.URL)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	d.Start(ctx)
+
+	for runID := 1; runID <= 12; runID++ {
+		d.Handle(finishedEvent(runID, "aborted"))
+	}
+
+	require.Eventually(t, func() bool {
+		return testutil.ToFloat64(d.metrics.DeliveriesTotal.WithLabelValues("slack/perf", OutcomeSuccess)) == 12
+	}, time.Second, 5*time.Millisecond)
+
+	recv.mu.Lock()
+	defer recv.mu.Unlock()
+	require.Len(t, recv.bodies, 1)
+
+	var message slackMessage
+	require.NoError(t, json.Unmarshal(recv.bodies[0], &message))
+	assert.Equal(t, "12 k6 test runs failed or were aborted", message.Text)
+	// Header, 10 runs and the summary of the others
+	require.Len(t, message.Blocks, 12)
+	assert.Equal(t, "and 2 more", message.Blocks[11].Elements[0].Text)
+}
+
+func TestSlackStarterName(t *testing.T) {
+	cfg := &config.Config{StartedByMode: config.StartedByRedact}
+	// Not started, so the runs stay queued
+	d := newTestSlackDispatcher(t, "http://127.0.0.1:1").WithStarterName(cfg.StarterName)
+
+	d.Handle(finishedEvent(1, "failed"))
+	d.Handle(finishedEvent(2, "aborted"))
+	events := []state.Event{<-d.slackRoutes[0].queue, <-d.slackRoutes[0].queue}
+
+	single, err := json.Marshal(newSlackMessage(events[:1]))
+	require.NoError(t, err)
+	assert.Contains(t, string(single), `*Started by*\nredacted`)
+	grouped, err := json.Marshal(newSlackMessage(events))
+	require.NoError(t, err)
+	assert.Contains(t, string(grouped), "by redacted")
+	for _, body := range [][]byte{single, grouped} {
+		assert.NotContains(t, string(body), "alice")
+	}
+}
+
+func TestSlackHonoursRetryAfter(t *testing.T) {
+	recv := &receiver{statuses: []int{http.StatusTooManyRequests}}
+	server := httptest.NewServer(recv)
+	defer server.Close()
+
+	d := newTestSlackDispatcher(t, server.URL)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	d.Start(ctx)
+
+	d.Handle(finishedEvent(1, "failed"))
+
+	require.Eventually(t, func() bool {
+		return testutil.ToFloat64(d.metrics.DeliveriesTotal.WithLabelValues("slack/perf", OutcomeSuccess)) == 1
+	}, 3*time.Second, 10*time.Millisecond)
+	assert.Equal(t, 2, recv.count())
+}
+
+func TestSlackEscape(t *testing.T) {
+	assert.Equal(t, "a &lt;b&gt; &amp; c", slackEscape("a <b> & c"))
+}
@@ -0,0 +1,309 @@
+package notify
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"go.uber.org/zap"
+
+	"github.com/grafana-cloud-k6-prometheus-exporter/internal/state"
+)
+
+// Headers set on webhook requests
+const (
+	SignatureHeader = "X-K6-Exporter-Signature"
+	EventHeader     = "X-K6-Exporter-Event"
+)
+
+// Delivery outcomes reported in metrics
+const (
+	OutcomeSuccess = "success"
+	OutcomeFailure = "failure"
+	OutcomeDropped = "dropped"
+)
+
+// Payload is the JSON body posted to webhooks
+type Payload struct {
+	Event          string    `json:"event"`
+	RunID          int       `json:"run_id"`
+	TestID         int       `json:"test_id"`
+	TestName       string    `json:"test_name"`
+	ProjectID      int       `json:"project_id"`
+	Stack          string    `json:"stack,omitempty"`
+	Status         string    `json:"status"`
+	PreviousStatus string    `json:"previous_status,omitempty"`
+	Result         string    `json:"result,omitempty"`
+	Reason         string    `json:"reason,omitempty"`
+	StartedBy      string    `json:"started_by,omitempty"`
+	VUH            float64   `json:"vuh"`
+	Time           time.Time `json:"time"`
+
+	FailedThresholds []string `json:"failed_thresholds,omitempty"`
+}
+
+// Metrics holds the notification metrics
+type Metrics struct {
+	DeliveriesTotal *prometheus.CounterVec
+	QueueLength     *prometheus.GaugeVec
+}
+
+// NewMetrics creates the notification metrics and registers them if a registerer is provided
+func NewMetrics(reg prometheus.Registerer) *Metrics {
+	metrics := &Metrics{
+		DeliveriesTotal: prometheus.NewCounterVec(
+			prometheus.CounterOpts{
+				Name: "k6_exporter_notification_deliveries_total",
+				Help: "Total number of notifications by receiver and outcome",
+			},
+			[]string{"receiver", "outcome"},
+		),
+		QueueLength: prometheus.NewGaugeVec(
+			prometheus.GaugeOpts{
+				Name: "k6_exporter_notification_queue_length",
+				Help: "Number of notifications waiting to be sent by receiver",
+			},
+			[]string{"receiver"},
+		),
+	}
+
+	if reg != nil {
+		reg.MustRegister(
+			metrics.DeliveriesTotal,
+			metrics.QueueLength,
+		)
+	}
+
+	return metrics
+}
+
+// Dispatcher sends test run events to the configured webhooks and Slack routes.
+// Every receiver has its own bounded queue, so a slow receiver never blocks the
+// collector or the other receivers.
+type Dispatcher struct {
+	webhooks    []*webhook
+	slackRoutes []*slackRoute
+	slack       *SlackConfig
+	starterName func(string) string
+	metrics     *Metrics
+	logger      *zap.Logger
+}
+
+// webhook is a configured receiver with its queue
+type webhook struct {
+	config *WebhookConfig
+	client *http.Client
+	queue  chan Payload
+}
+
+// NewDispatcher creates a dispatcher for the configured webhooks
+func NewDispatcher(cfg *Config, logger *zap.Logger, reg prometheus.Registerer) *Dispatcher {
+	d := &Dispatcher{
+		metrics: NewMetrics(reg),
+		logger:  logger.Named("notify"),
+	}
+
+	for i := range cfg.Webhooks {
+		webhookCfg := &cfg.Webhooks[i]
+		d.webhooks = append(d.webhooks, &webhook{
+			config: webhookCfg,
+			client: &http.Client{Timeout: webhookCfg.Timeout},
+			queue:  make(chan Payload, webhookCfg.QueueSize),
+		})
+	}
+
+	if cfg.Slack != nil {
+		d.slack = cfg.Slack
+		client := &http.Client{Timeout: defaultTimeout}
+		for i := range cfg.Slack.Routes {
+			routeCfg := &cfg.Slack.Routes[i]
+			d.slackRoutes = append(d.slackRoutes, &slackRoute{
+				config:   routeCfg,
+				receiver: "slack/" + routeCfg.Name,
+				client:   client,
+				queue:    make(chan state.Event, cfg.Slack.QueueSize),
+			})
+		}
+	}
+
+	return d
+}
+
+// WithStarterName sets how run starters appear in notifications, so they follow
+// STARTED_BY_MODE. Starters are sent unchanged by default.
+func (d *Dispatcher) WithStarterName(starterName func(string) string) *Dispatcher {
+	d.starterName = starterName
+	return d
+}
+
+// starter returns the run starter as it may appear in notifications
+func (d *Dispatcher) starter(startedBy string) string {
+	if d.starterName == nil {
+		return startedBy
+	}
+	return d.starterName(startedBy)
+}
+
+// Handle queues the event for every matching webhook. It never blocks and can
+// be registered as a state.Listener.
+func (d *Dispatcher) Handle(event state.Event) {
+	payload := newPayload(event)
+	payload.StartedBy = d.starter(event.StartedBy)
+
+	for _, wh := range d.webhooks {
+		if !wh.config.matches(event) {
+			continue
+		}
+
+		select {
+		case wh.queue <- payload:
+			d.metrics.QueueLength.WithLabelValues(wh.config.Name).Set(float64(len(wh.queue)))
+		default:
+			d.metrics.DeliveriesTotal.WithLabelValues(wh.config.Name, OutcomeDropped).Inc()
+			d.logger.Warn("webhook queue is full, dropping event",
+				zap.String("webhook", wh.config.Name),
+				zap.String("event", event.Type),
+				zap.Int("run_id", event.RunID),
+			)
+		}
+	}
+
+	d.handleSlack(event)
+}
+
+// Start sends the queued events until the context is cancelled
+func (d *Dispatcher) Start(ctx context.Context) {
+	for _, wh := range d.webhooks {
+		go d.run(ctx, wh)
+	}
+	for _, route := range d.slackRoutes {
+		go d.runSlack(ctx, route)
+	}
+}
+
+// run delivers the queued events of a webhook in order
+func (d *Dispatcher) run(ctx context.Context, wh *webhook) {
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case payload := <-wh.queue:
+			d.metrics.QueueLength.WithLabelValues(wh.config.Name).Set(float64(len(wh.queue)))
+
+			outcome := OutcomeSuccess
+			if err := d.deliver(ctx, wh, payload); err != nil {
+				outcome = OutcomeFailure
+				d.logger.Error("failed to deliver webhook",
+					zap.String("webhook", wh.config.Name),
+					zap.String("event", payload.Event),
+					zap.Int("run_id", payload.RunID),
+					zap.Error(err),
+				)
+			}
+			d.metrics.DeliveriesTotal.WithLabelValues(wh.config.Name, outcome).Inc()
+		}
+	}
+}
+
+// deliver posts the payload, retrying with exponential backoff on network
+// errors, rate limiting and server errors
+func (d *Dispatcher) deliver(ctx context.Context, wh *webhook, payload Payload) error {
+	body, err := json.Marshal(payload)
+	if err != nil {
+		return fmt.Errorf("encode payload: %w", err)
+	}
+
+	backoff := wh.config.RetryBackoff
+	for attempt := 0; ; attempt++ {
+		retry, err := wh.post(ctx, payload.Event, body)
+		if err == nil {
+			return nil
+		}
+		if !retry || attempt >= *wh.config.MaxRetries {
+			return err
+		}
+
+		d.logger.Debug("retrying webhook",
+			zap.String("webhook", wh.config.Name),
+			zap.Int("attempt", attempt+1),
+			zap.Duration("backoff", backoff),
+			zap.Error(err),
+		)
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(backoff):
+		}
+		backoff *= 2
+	}
+}
+
+// post sends the body once and returns whether a failure may be retried
+func (wh *webhook) post(ctx context.Context, eventType string, body []byte) (bool, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, wh.config.URL, bytes.NewReader(body))
+	if err != nil {
+		return false, fmt.Errorf("create request: %w", err)
+	}
+
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", "k6-prometheus-exporter")
+	req.Header.Set(EventHeader, eventType)
+	for key, value := range wh.config.Headers {
+		req.Header.Set(key, value)
+	}
+	if wh.config.Secret != "" {
+		req.Header.Set(SignatureHeader, Sign(wh.config.Secret, body))
+	}
+
+	resp, err := wh.client.Do(req)
+	if err != nil {
+		return true, fmt.Errorf("perform request: %w", err)
+	}
+	defer resp.Body.Close()
+	io.Copy(io.Discard, resp.Body)
+
+	if resp.StatusCode >= 200 && resp.StatusCode < 300 {
+		return false, nil
+	}
+
+	retry := resp.StatusCode == http.StatusTooManyRequests || resp.StatusCode >= 500
+	return retry, fmt.Errorf("unexpected status %d", resp.StatusCode)
+}
+
+// Sign returns the signature header value of the body, receivers recompute it
+// with the shared secret to verify the payload
+func Sign(secret string, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write(body)
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+// newPayload converts a state event
+func newPayload(event state.Event) Payload {
+	return Payload{
+		Event:          event.Type,
+		RunID:          event.RunID,
+		TestID:         event.TestID,
+		TestName:       event.TestName,
+		ProjectID:      event.ProjectID,
+		Stack:          event.Stack,
+		Status:         event.Status,
+		PreviousStatus: event.PreviousStatus,
+		Result:         event.Result,
+		Reason:         event.Reason,
+		StartedBy:      event.StartedBy,
+		VUH:            event.VUH,
+		Time:           event.Time,
+
+		FailedThresholds: event.FailedThresholds,
+	}
+}
@@ -0,0 +1,176 @@
+package notify
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap/zaptest"
+
+	"github.com/grafana-cloud-k6-prometheus-exporter/internal/config"
+	"github.com/grafana-cloud-k6-prometheus-exporter/internal/state"
+)
+
+// receiver records the requests of a test webhook receiver
+type receiver struct {
+	mu       sync.Mutex
+	requests []*http.Request
+	bodies   [][]byte
+	statuses []int // Status codes returned in order, 200 once exhausted
+}
+
+func (r *receiver) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+	body, _ := io.ReadAll(req.Body)
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.requests = append(r.requests, req)
+	r.bodies = append(r.bodies, body)
+
+	status := http.StatusOK
+	if len(r.statuses) > 0 {
+		status = r.statuses[0]
+		r.statuses = r.statuses[1:]
+	}
+	w.WriteHeader(status)
+}
+
+func (r *receiver) count() int {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return len(r.requests)
+}
+
+func newTestDispatcher(t *testing.T, webhooks ...WebhookConfig) *Dispatcher {
+	cfg := &Config{Webhooks: webhooks}
+	require.NoError(t, cfg.Validate())
+	return NewDispatcher(cfg, zaptest.NewLogger(t), prometheus.NewRegistry())
+}
+
+func intPtr(i int) *int {
+	return &i
+}
+
+func TestDispatcherDelivers(t *testing.T) {
+	recv := &receiver{}
+	server := httptest.NewServer(recv)
+	defer server.Close()
+
+	d := newTestDispatcher(t, WebhookConfig{Name: "ci", URL: server.URL, Secret: "s3cr3t", Headers: map[string]string{"X-Team": "perf"}})
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	d.Start(ctx)
+
+	d.Handle(state.Event{Type: state.EventRunFinished, RunID: 1, TestName: "checkout", ProjectID: 100, Status: "completed", Result: "failed"})
+
+	require.Eventually(t, func() bool {
+		return testutil.ToFloat64(d.metrics.DeliveriesTotal.WithLabelValues("ci", OutcomeSuccess)) == 1
+	}, time.Second, 5*time.Millisecond)
+
+	recv.mu.Lock()
+	defer recv.mu.Unlock()
+	require.Len(t, recv.requests, 1)
+	req := recv.requests[0]
+	assert.Equal(t, http.MethodPost, req.Method)
+	assert.Equal(t, "application/json", req.Header.Get("Content-Type"))
+	assert.Equal(t, state.EventRunFinished, req.Header.Get(EventHeader))
+	assert.Equal(t, "perf", req.Header.Get("X-Team"))
+	assert.Equal(t, Sign("s3cr3t", recv.bodies[0]), req.Header.Get(SignatureHeader))
+
+	var payload Payload
+	require.NoError(t, json.Unmarshal(recv.bodies[0], &payload))
+	assert.Equal(t, "checkout", payload.TestName)
+	assert.Equal(t, "failed", payload.Result)
+}
+
+func TestDispatcherRetries(t *testing.T) {
+	recv := &receiver{statuses: []int{http.StatusBadGateway, http.StatusTooManyRequests}}
+	server := httptest.NewServer(recv)
+	defer server.Close()
+
+	d := newTestDispatcher(t, WebhookConfig{Name: "ci", URL: server.URL, RetryBackoff: time.Millisecond})
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	d.Start(ctx)
+
+	d.Handle(state.Event{Type: state.EventRunStarted, RunID: 1})
+
+	require.Eventually(t, func() bool {
+		return testutil.ToFloat64(d.metrics.DeliveriesTotal.WithLabelValues("ci", OutcomeSuccess)) == 1
+	}, time.Second, 5*time.Millisecond)
+	assert.Equal(t, 3, recv.count())
+}
+
+func TestDispatcherGivesUp(t *testing.T) {
+	recv := &receiver{statuses: []int{http.StatusInternalServerError, http.StatusInternalServerError, http.StatusInternalServerError}}
+	server := httptest.NewServer(recv)
+	defer server.Close()
+
+	d := newTestDispatcher(t,
+		WebhookConfig{Name: "flaky", URL: server.URL, MaxRetries: intPtr(1), RetryBackoff: time.Millisecond},
+	)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	d.Start(ctx)
+
+	d.Handle(state.Event{Type: state.EventRunStarted, RunID: 1})
+
+	require.Eventually(t, func() bool {
+		return testutil.ToFloat64(d.metrics.DeliveriesTotal.WithLabelValues("flaky", OutcomeFailure)) == 1
+	}, time.Second, 5*time.Millisecond)
+	assert.Equal(t, 2, recv.count())
+}
+
+func TestDispatcherClientErrorsAreNotRetried(t *testing.T) {
+	recv := &receiver{statuses: []int{http.StatusBadRequest}}
+	server := httptest.NewServer(recv)
+	defer server.Close()
+
+	d := newTestDispatcher(t, WebhookConfig{Name: "ci", URL: server.URL, RetryBackoff: time.Millisecond})
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	d.Start(ctx)
+
+	d.Handle(state.Event{Type: state.EventRunStarted, RunID: 1})
+
+	require.Eventually(t, func() bool {
+		return testutil.ToFloat64(d.metrics.DeliveriesTotal.WithLabelValues("ci", OutcomeFailure)) == 1
+	}, time.Second, 5*time.Millisecond)
+	assert.Equal(t, 1, recv.count())
+}
+
+func TestDispatcherDropsWhenQueueIsFull(t *testing.T) {
+	// Not started, so nothing drains the queue
+	d := newTestDispatcher(t, WebhookConfig{Name: "slow", URL: "http://127.0.0.1:1", QueueSize: 2})
+
+	for runID := 1; runID <= 5; runID++ {
+		d.Handle(state.Event{Type: state.EventRunStarted, RunID: runID})
+	}
+
+	assert.Equal(t, float64(3), testutil.ToFloat64(d.metrics.DeliveriesTotal.WithLabelValues("slow", OutcomeDropped)))
+	assert.Equal(t, float64(2), testutil.ToFloat64(d.metrics.QueueLength.WithLabelValues("slow")))
+}
+
+func TestDispatcherStarterName(t *testing.T) {
+	cfg := &config.Config{StartedByMode: config.StartedByHash, StartedByHashSalt: "salt"}
+	// Not started, so the payloads stay queued
+	d := newTestDispatcher(t, WebhookConfig{Name: "ci", URL: "http://127.0.0.1:1"}).WithStarterName(cfg.StarterName)
+
+	d.Handle(state.Event{Type: state.EventRunStarted, RunID: 1, StartedBy: "alice@example.com"})
+	d.Handle(state.Event{Type: state.EventRunStarted, RunID: 2})
+
+	payload := <-d.webhooks[0].queue
+	assert.Equal(t, cfg.StarterName("alice@example.com"), payload.StartedBy)
+	assert.NotContains(t, payload.StartedBy, "alice")
+	payload = <-d.webhooks[0].queue
+	assert.Empty(t, payload.StartedBy)
+}
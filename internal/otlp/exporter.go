@@ -0,0 +1,189 @@
+package otlp
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"go.uber.org/zap"
+
+	"github.com/grafana-cloud-k6-prometheus-exporter/internal/config"
+)
+
+// Supported OTLP_PROTOCOL values
+const (
+	ProtocolProtobuf = "http/protobuf"
+	ProtocolJSON     = "http/json"
+)
+
+// Name reported as the service and instrumentation scope
+const serviceName = "k6-prometheus-exporter"
+
+// Outcomes of exports reported in metrics
+const (
+	OutcomeSuccess = "success"
+	OutcomeFailure = "failure"
+)
+
+// Metrics holds the OTLP export metrics
+type Metrics struct {
+	ExportsTotal *prometheus.CounterVec
+}
+
+// NewMetrics creates the OTLP export metrics and registers them if a registerer is provided
+func NewMetrics(reg prometheus.Registerer) *Metrics {
+	metrics := &Metrics{
+		ExportsTotal: prometheus.NewCounterVec(
+			prometheus.CounterOpts{
+				Name: "k6_exporter_otlp_exports_total",
+				Help: "Total number of OTLP metric exports by outcome",
+			},
+			[]string{"outcome"},
+		),
+	}
+
+	if reg != nil {
+		reg.MustRegister(metrics.ExportsTotal)
+	}
+
+	return metrics
+}
+
+// Exporter periodically gathers the registered metrics, the same series served on
+// /metrics, and exports them to an OTLP/HTTP endpoint. Every export carries the
+// cumulative values, so a failed export is not retried and the next one catches up.
+type Exporter struct {
+	config   *config.Config
+	endpoint string
+	gatherer prometheus.Gatherer
+	resource Resource
+	scope    Scope
+	start    time.Time
+	client   *http.Client
+	metrics  *Metrics
+	logger   *zap.Logger
+}
+
+// NewExporter creates an exporter for the OTLP endpoint of the configuration
+func NewExporter(cfg *config.Config, gatherer prometheus.Gatherer, version string, logger *zap.Logger, reg prometheus.Registerer) *Exporter {
+	attributes := []KeyValue{
+		{Key: "service.name", Value: AnyValue{StringValue: serviceName}},
+		{Key: "service.version", Value: AnyValue{StringValue: version}},
+	}
+	// With multiple stacks the stack is a label of the series instead
+	if cfg.GrafanaStackID != "" {
+		attributes = append(attributes, KeyValue{Key: "grafana.stack_id", Value: AnyValue{StringValue: cfg.GrafanaStackID}})
+	}
+
+	return &Exporter{
+		config:   cfg,
+		endpoint: metricsEndpoint(cfg.OTLPEndpoint),
+		gatherer: gatherer,
+		resource: Resource{Attributes: attributes},
+		scope:    Scope{Name: serviceName, Version: version},
+		start:    time.Now(),
+		client:   &http.Client{Timeout: cfg.OTLPTimeout},
+		metrics:  NewMetrics(reg),
+		logger:   logger.Named("otlp"),
+	}
+}
+
+// Start exports the metrics every interval until the context is cancelled
+func (e *Exporter) Start(ctx context.Context) {
+	e.logger.Info("otlp export enabled",
+		zap.String("endpoint", e.endpoint),
+		zap.String("protocol", e.config.OTLPProtocol),
+		zap.Duration("interval", e.config.OTLPInterval),
+	)
+
+	go func() {
+		ticker := time.NewTicker(e.config.OTLPInterval)
+		defer ticker.Stop()
+
+		for {
+			outcome := OutcomeSuccess
+			if err := e.Export(ctx); err != nil {
+				outcome = OutcomeFailure
+				e.logger.Error("failed to export metrics over otlp", zap.Error(err))
+			}
+			e.metrics.ExportsTotal.WithLabelValues(outcome).Inc()
+
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+			}
+		}
+	}()
+}
+
+// Export gathers the metrics once and sends them
+func (e *Exporter) Export(ctx context.Context) error {
+	families, err := e.gatherer.Gather()
+	if err != nil && len(families) == 0 {
+		return fmt.Errorf("gather metrics: %w", err)
+	}
+	if err != nil {
+		// Partial results are still worth sending
+		e.logger.Warn("some metrics could not be gathered", zap.Error(err))
+	}
+
+	request := ExportRequest{ResourceMetrics: []ResourceMetrics{{
+		Resource: e.resource,
+		ScopeMetrics: []ScopeMetrics{{
+			Scope:   e.scope,
+			Metrics: Convert(families, uint64(e.start.UnixNano()), uint64(time.Now().UnixNano())),
+		}},
+	}}}
+
+	var body []byte
+	contentType := "application/x-protobuf"
+	if e.config.OTLPProtocol == ProtocolJSON {
+		contentType = "application/json"
+		if body, err = json.Marshal(request); err != nil {
+			return fmt.Errorf("encode request: %w", err)
+		}
+	} else {
+		body = request.Marshal()
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, e.endpoint, bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("create request: %w", err)
+	}
+	req.Header.Set("Content-Type", contentType)
+	req.Header.Set("User-Agent", serviceName)
+	for key, value := range e.config.OTLPHeaders {
+		req.Header.Set(key, value)
+	}
+
+	resp, err := e.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("perform request: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		message, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+		return fmt.Errorf("unexpected status %d: %s", resp.StatusCode, strings.TrimSpace(string(message)))
+	}
+	io.Copy(io.Discard, resp.Body)
+	return nil
+}
+
+// metricsEndpoint appends the OTLP metrics path to endpoints given without a path
+func metricsEndpoint(endpoint string) string {
+	u, err := url.Parse(endpoint)
+	if err != nil || (u.Path != "" && u.Path != "/") {
+		return endpoint
+	}
+	u.Path = "/v1/metrics"
+	return u.String()
+}
@@ -0,0 +1,165 @@
+package otlp
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap/zaptest"
+	"google.golang.org/protobuf/encoding/protowire"
+
+	"github.com/grafana-cloud-k6-prometheus-exporter/internal/config"
+)
+
+// receiver is a local OTLP/HTTP receiver stub
+type receiver struct {
+	mu      sync.Mutex
+	path    string
+	headers http.Header
+	body    []byte
+	status  int
+}
+
+func (r *receiver) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+	body, _ := io.ReadAll(req.Body)
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.path = req.URL.Path
+	r.headers = req.Header
+	r.body = body
+
+	if r.status != 0 {
+		w.WriteHeader(r.status)
+		return
+	}
+	w.Header().Set("Content-Type", req.Header.Get("Content-Type"))
+	w.WriteHeader(http.StatusOK)
+}
+
+func newTestExporter(t *testing.T, endpoint, protocol string) *Exporter {
+	cfg := &config.Config{
+		GrafanaStackID: "12345",
+		OTLPEndpoint:   endpoint,
+		OTLPProtocol:   protocol,
+		OTLPHeaders:    map[string]string{"Authorization": "Bearer token"},
+		OTLPInterval:   time.Hour,
+		OTLPTimeout:    time.Second,
+	}
+	return NewExporter(cfg, testRegistry(), "1.2.3", zaptest.NewLogger(t), prometheus.NewRegistry())
+}
+
+func TestExportJSON(t *testing.T) {
+	recv := &receiver{}
+	server := httptest.NewServer(recv)
+	defer server.Close()
+
+	exporter := newTestExporter(t, server.URL, ProtocolJSON)
+	require.NoError(t, exporter.Export(context.Background()))
+
+	recv.mu.Lock()
+	defer recv.mu.Unlock()
+	assert.Equal(t, "/v1/metrics", recv.path)
+	assert.Equal(t, "application/json", recv.headers.Get("Content-Type"))
+	assert.Equal(t, "Bearer token", recv.headers.Get("Authorization"))
+
+	var request ExportRequest
+	require.NoError(t, json.Unmarshal(recv.body, &request))
+	require.Len(t, request.ResourceMetrics, 1)
+	assert.Contains(t, request.ResourceMetrics[0].Resource.Attributes, KeyValue{Key: "grafana.stack_id", Value: AnyValue{StringValue: "12345"}})
+	assert.Contains(t, request.ResourceMetrics[0].Resource.Attributes, KeyValue{Key: "service.version", Value: AnyValue{StringValue: "1.2.3"}})
+	require.Len(t, request.ResourceMetrics[0].ScopeMetrics, 1)
+	assert.Len(t, request.ResourceMetrics[0].ScopeMetrics[0].Metrics, 3)
+
+	// 64-bit integers are strings in OTLP/JSON
+	assert.Contains(t, string(recv.body), `"count":"3"`)
+	assert.Contains(t, string(recv.body), `"bucketCounts":["1","1","1"]`)
+}
+
+func TestExportProtobuf(t *testing.T) {
+	recv := &receiver{}
+	server := httptest.NewServer(recv)
+	defer server.Close()
+
+	exporter := newTestExporter(t, server.URL+"/otlp/v1/metrics", ProtocolProtobuf)
+	require.NoError(t, exporter.Export(context.Background()))
+
+	recv.mu.Lock()
+	defer recv.mu.Unlock()
+	assert.Equal(t, "/otlp/v1/metrics", recv.path, "endpoints with a path are used as is")
+	assert.Equal(t, "application/x-protobuf", recv.headers.Get("Content-Type"))
+
+	request := fields(t, recv.body)
+	require.Len(t, request[1], 1)
+	resourceMetrics := fields(t, request[1][0].([]byte))
+
+	resource := fields(t, resourceMetrics[1][0].([]byte))
+	attributes := make(map[string]string)
+	for _, attribute := range resource[1] {
+		kv := fields(t, attribute.([]byte))
+		value := fields(t, kv[2][0].([]byte))
+		attributes[string(kv[1][0].([]byte))] = string(value[1][0].([]byte))
+	}
+	assert.Equal(t, map[string]string{"service.name": serviceName, "service.version": "1.2.3", "grafana.stack_id": "12345"}, attributes)
+
+	scopeMetrics := fields(t, resourceMetrics[2][0].([]byte))
+	kinds := make(map[string]protowire.Number)
+	for _, m := range scopeMetrics[2] {
+		metric := fields(t, m.([]byte))
+		for _, kind := range []protowire.Number{5, 7, 9, 11} {
+			if _, ok := metric[kind]; ok {
+				kinds[string(metric[1][0].([]byte))] = kind
+			}
+		}
+	}
+	assert.Equal(t, map[string]protowire.Number{
+		"k6_test_runs_total":           7, // Sum
+		"k6_test_runs_active":          5, // Gauge
+		"k6_test_run_duration_seconds": 9, // Histogram
+	}, kinds)
+}
+
+func TestExportFailure(t *testing.T) {
+	recv := &receiver{status: http.StatusServiceUnavailable}
+	server := httptest.NewServer(recv)
+	defer server.Close()
+
+	exporter := newTestExporter(t, server.URL, ProtocolProtobuf)
+	err := exporter.Export(context.Background())
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "unexpected status 503")
+}
+
+// fields decodes the fields of a protobuf message by number, embedded messages are returned as bytes
+func fields(t *testing.T, data []byte) map[protowire.Number][]interface{} {
+	result := make(map[protowire.Number][]interface{})
+	for len(data) > 0 {
+		num, typ, n := protowire.ConsumeTag(data)
+		require.GreaterOrEqual(t, n, 0)
+		data = data[n:]
+
+		n = protowire.ConsumeFieldValue(num, typ, data)
+		require.GreaterOrEqual(t, n, 0)
+		switch typ {
+		case protowire.BytesType:
+			v, _ := protowire.ConsumeBytes(data)
+			result[num] = append(result[num], v)
+		case protowire.Fixed64Type:
+			v, _ := protowire.ConsumeFixed64(data)
+			result[num] = append(result[num], v)
+		case protowire.VarintType:
+			v, _ := protowire.ConsumeVarint(data)
+			result[num] = append(result[num], v)
+		}
+		data = data[n:]
+	}
+	return result
+}
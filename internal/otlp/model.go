@@ -0,0 +1,245 @@
+package otlp
+
+import (
+	"encoding/json"
+	"math"
+	"strconv"
+
+	dto "github.com/prometheus/client_model/go"
+)
+
+// Aggregation temporality of sums and histograms, Prometheus metrics are cumulative
+const temporalityCumulative = 2
+
+// The types below mirror the OTLP metrics protobuf messages. Their JSON encoding
+// follows the OTLP/JSON mapping: camelCase names and 64-bit integers as strings.
+
+// ExportRequest is an opentelemetry.proto.collector.metrics.v1.ExportMetricsServiceRequest
+type ExportRequest struct {
+	ResourceMetrics []ResourceMetrics `json:"resourceMetrics"`
+}
+
+// ResourceMetrics holds the metrics of a resource
+type ResourceMetrics struct {
+	Resource     Resource       `json:"resource"`
+	ScopeMetrics []ScopeMetrics `json:"scopeMetrics"`
+}
+
+// Resource describes the exporter instance
+type Resource struct {
+	Attributes []KeyValue `json:"attributes"`
+}
+
+// ScopeMetrics holds the metrics of an instrumentation scope
+type ScopeMetrics struct {
+	Scope   Scope    `json:"scope"`
+	Metrics []Metric `json:"metrics"`
+}
+
+// Scope is the instrumentation scope
+type Scope struct {
+	Name    string `json:"name"`
+	Version string `json:"version,omitempty"`
+}
+
+// KeyValue is an attribute with a string value
+type KeyValue struct {
+	Key   string   `json:"key"`
+	Value AnyValue `json:"value"`
+}
+
+// AnyValue is an attribute value, only strings are used
+type AnyValue struct {
+	StringValue string `json:"stringValue"`
+}
+
+// Metric is a named metric with exactly one of its data fields set
+type Metric struct {
+	Name        string     `json:"name"`
+	Description string     `json:"description,omitempty"`
+	Gauge       *Gauge     `json:"gauge,omitempty"`
+	Sum         *Sum       `json:"sum,omitempty"`
+	Histogram   *Histogram `json:"histogram,omitempty"`
+	Summary     *Summary   `json:"summary,omitempty"`
+}
+
+// Gauge is a metric of current values
+type Gauge struct {
+	DataPoints []NumberDataPoint `json:"dataPoints"`
+}
+
+// Sum is a cumulative, monotonic metric for Prometheus counters
+type Sum struct {
+	DataPoints             []NumberDataPoint `json:"dataPoints"`
+	AggregationTemporality int               `json:"aggregationTemporality"`
+	IsMonotonic            bool              `json:"isMonotonic"`
+}
+
+// Histogram is a cumulative histogram with explicit bounds
+type Histogram struct {
+	DataPoints             []HistogramDataPoint `json:"dataPoints"`
+	AggregationTemporality int                  `json:"aggregationTemporality"`
+}
+
+// Summary is a metric of quantiles
+type Summary struct {
+	DataPoints []SummaryDataPoint `json:"dataPoints"`
+}
+
+// NumberDataPoint is a single gauge or sum value
+type NumberDataPoint struct {
+	Attributes        []KeyValue `json:"attributes,omitempty"`
+	StartTimeUnixNano uint64     `json:"startTimeUnixNano,string,omitempty"`
+	TimeUnixNano      uint64     `json:"timeUnixNano,string"`
+	AsDouble          float64    `json:"asDouble"`
+}
+
+// HistogramDataPoint is a single histogram. BucketCounts has one more entry
+// than ExplicitBounds, the last bucket counts the values above the last bound.
+type HistogramDataPoint struct {
+	Attributes        []KeyValue `json:"attributes,omitempty"`
+	StartTimeUnixNano uint64     `json:"startTimeUnixNano,string,omitempty"`
+	TimeUnixNano      uint64     `json:"timeUnixNano,string"`
+	Count             uint64     `json:"count,string"`
+	Sum               float64    `json:"sum"`
+	BucketCounts      uint64List `json:"bucketCounts"`
+	ExplicitBounds    []float64  `json:"explicitBounds"`
+}
+
+// SummaryDataPoint is a single summary
+type SummaryDataPoint struct {
+	Attributes        []KeyValue        `json:"attributes,omitempty"`
+	StartTimeUnixNano uint64            `json:"startTimeUnixNano,string,omitempty"`
+	TimeUnixNano      uint64            `json:"timeUnixNano,string"`
+	Count             uint64            `json:"count,string"`
+	Sum               float64           `json:"sum"`
+	QuantileValues    []ValueAtQuantile `json:"quantileValues"`
+}
+
+// ValueAtQuantile is a quantile of a summary
+type ValueAtQuantile struct {
+	Quantile float64 `json:"quantile"`
+	Value    float64 `json:"value"`
+}
+
+// uint64List encodes its values as JSON strings, as OTLP/JSON requires for 64-bit integers
+type uint64List []uint64
+
+// MarshalJSON implements json.Marshaler
+func (l uint64List) MarshalJSON() ([]byte, error) {
+	values := make([]string, len(l))
+	for i, v := range l {
+		values[i] = strconv.FormatUint(v, 10)
+	}
+	return json.Marshal(values)
+}
+
+// UnmarshalJSON implements json.Unmarshaler
+func (l *uint64List) UnmarshalJSON(data []byte) error {
+	var values []string
+	if err := json.Unmarshal(data, &values); err != nil {
+		return err
+	}
+	*l = make(uint64List, len(values))
+	for i, value := range values {
+		v, err := strconv.ParseUint(value, 10, 64)
+		if err != nil {
+			return err
+		}
+		(*l)[i] = v
+	}
+	return nil
+}
+
+// Convert maps gathered Prometheus metric families to OTLP metrics: counters to
+// monotonic cumulative sums, gauges and untyped metrics to gauges, histograms to
+// explicit bucket histograms and summaries to summaries. Cumulative points start
+// at startNano, points without a timestamp get nowNano.
+func Convert(families []*dto.MetricFamily, startNano, nowNano uint64) []Metric {
+	metrics := make([]Metric, 0, len(families))
+
+	for _, family := range families {
+		metric := Metric{Name: family.GetName(), Description: family.GetHelp()}
+
+		switch family.GetType() {
+		case dto.MetricType_COUNTER:
+			metric.Sum = &Sum{AggregationTemporality: temporalityCumulative, IsMonotonic: true}
+		case dto.MetricType_GAUGE, dto.MetricType_UNTYPED:
+			metric.Gauge = &Gauge{}
+		case dto.MetricType_HISTOGRAM:
+			metric.Histogram = &Histogram{AggregationTemporality: temporalityCumulative}
+		case dto.MetricType_SUMMARY:
+			metric.Summary = &Summary{}
+		default:
+			continue
+		}
+
+		for _, m := range family.GetMetric() {
+			attributes := make([]KeyValue, 0, len(m.GetLabel()))
+			for _, label := range m.GetLabel() {
+				attributes = append(attributes, KeyValue{Key: label.GetName(), Value: AnyValue{StringValue: label.GetValue()}})
+			}
+			ts := nowNano
+			if m.TimestampMs != nil {
+				ts = uint64(m.GetTimestampMs()) * 1e6
+			}
+
+			switch {
+			case metric.Sum != nil:
+				metric.Sum.DataPoints = append(metric.Sum.DataPoints, NumberDataPoint{
+					Attributes: attributes, StartTimeUnixNano: startNano, TimeUnixNano: ts, AsDouble: m.GetCounter().GetValue(),
+				})
+			case metric.Gauge != nil:
+				value := m.GetGauge().GetValue()
+				if family.GetType() == dto.MetricType_UNTYPED {
+					value = m.GetUntyped().GetValue()
+				}
+				metric.Gauge.DataPoints = append(metric.Gauge.DataPoints, NumberDataPoint{
+					Attributes: attributes, TimeUnixNano: ts, AsDouble: value,
+				})
+			case metric.Histogram != nil:
+				point := newHistogramPoint(m.GetHistogram())
+				point.Attributes, point.StartTimeUnixNano, point.TimeUnixNano = attributes, startNano, ts
+				metric.Histogram.DataPoints = append(metric.Histogram.DataPoints, point)
+			case metric.Summary != nil:
+				summary := m.GetSummary()
+				point := SummaryDataPoint{
+					Attributes: attributes, StartTimeUnixNano: startNano, TimeUnixNano: ts,
+					Count: summary.GetSampleCount(), Sum: summary.GetSampleSum(),
+				}
+				for _, quantile := range summary.GetQuantile() {
+					point.QuantileValues = append(point.QuantileValues, ValueAtQuantile{Quantile: quantile.GetQuantile(), Value: quantile.GetValue()})
+				}
+				metric.Summary.DataPoints = append(metric.Summary.DataPoints, point)
+			}
+		}
+
+		metrics = append(metrics, metric)
+	}
+
+	return metrics
+}
+
+// newHistogramPoint converts the cumulative Prometheus buckets to OTLP bucket counts
+func newHistogramPoint(histogram *dto.Histogram) HistogramDataPoint {
+	point := HistogramDataPoint{
+		Count:          histogram.GetSampleCount(),
+		Sum:            histogram.GetSampleSum(),
+		ExplicitBounds: make([]float64, 0, len(histogram.GetBucket())),
+		BucketCounts:   make(uint64List, 0, len(histogram.GetBucket())+1),
+	}
+
+	var previous uint64
+	for _, bucket := range histogram.GetBucket() {
+		if math.IsInf(bucket.GetUpperBound(), +1) {
+			continue
+		}
+		point.ExplicitBounds = append(point.ExplicitBounds, bucket.GetUpperBound())
+		point.BucketCounts = append(point.BucketCounts, bucket.GetCumulativeCount()-previous)
+		previous = bucket.GetCumulativeCount()
+	}
+	// Values above the last bound
+	point.BucketCounts = append(point.BucketCounts, histogram.GetSampleCount()-previous)
+
+	return point
+}
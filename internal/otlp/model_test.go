@@ -0,0 +1,60 @@
+package otlp
+
+import (
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func testRegistry() *prometheus.Registry {
+	reg := prometheus.NewRegistry()
+
+	runs := prometheus.NewCounterVec(prometheus.CounterOpts{Name: "k6_test_runs_total", Help: "Total test runs"}, []string{"project_id"})
+	runs.WithLabelValues("100").Add(3)
+	active := prometheus.NewGauge(prometheus.GaugeOpts{Name: "k6_test_runs_active", Help: "Active test runs"})
+	active.Set(2)
+	duration := prometheus.NewHistogram(prometheus.HistogramOpts{Name: "k6_test_run_duration_seconds", Help: "Duration", Buckets: []float64{60, 600}})
+	duration.Observe(30)
+	duration.Observe(120)
+	duration.Observe(1200)
+
+	reg.MustRegister(runs, active, duration)
+	return reg
+}
+
+func TestConvert(t *testing.T) {
+	families, err := testRegistry().Gather()
+	require.NoError(t, err)
+
+	metrics := Convert(families, 1000, 2000)
+	require.Len(t, metrics, 3)
+
+	byName := make(map[string]Metric)
+	for _, metric := range metrics {
+		byName[metric.Name] = metric
+	}
+
+	sum := byName["k6_test_runs_total"].Sum
+	require.NotNil(t, sum)
+	assert.True(t, sum.IsMonotonic)
+	assert.Equal(t, temporalityCumulative, sum.AggregationTemporality)
+	require.Len(t, sum.DataPoints, 1)
+	assert.Equal(t, 3.0, sum.DataPoints[0].AsDouble)
+	assert.Equal(t, uint64(1000), sum.DataPoints[0].StartTimeUnixNano)
+	assert.Equal(t, []KeyValue{{Key: "project_id", Value: AnyValue{StringValue: "100"}}}, sum.DataPoints[0].Attributes)
+
+	gauge := byName["k6_test_runs_active"].Gauge
+	require.NotNil(t, gauge)
+	assert.Equal(t, 2.0, gauge.DataPoints[0].AsDouble)
+	assert.Equal(t, uint64(2000), gauge.DataPoints[0].TimeUnixNano)
+
+	histogram := byName["k6_test_run_duration_seconds"].Histogram
+	require.NotNil(t, histogram)
+	point := histogram.DataPoints[0]
+	assert.Equal(t, uint64(3), point.Count)
+	assert.Equal(t, 1350.0, point.Sum)
+	assert.Equal(t, []float64{60, 600}, point.ExplicitBounds)
+	assert.Equal(t, uint64List{1, 1, 1}, point.BucketCounts)
+}
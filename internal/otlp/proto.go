@@ -0,0 +1,185 @@
+package otlp
+
+import (
+	"math"
+
+	"google.golang.org/protobuf/encoding/protowire"
+)
+
+// Marshal encodes the request in the OTLP protobuf format
+func (r *ExportRequest) Marshal() []byte {
+	var buf []byte
+	for i := range r.ResourceMetrics {
+		buf = appendMessage(buf, 1, r.ResourceMetrics[i].marshal())
+	}
+	return buf
+}
+
+func (r *ResourceMetrics) marshal() []byte {
+	var buf []byte
+	buf = appendMessage(buf, 1, r.Resource.marshal())
+	for i := range r.ScopeMetrics {
+		buf = appendMessage(buf, 2, r.ScopeMetrics[i].marshal())
+	}
+	return buf
+}
+
+func (r *Resource) marshal() []byte {
+	return appendAttributes(nil, 1, r.Attributes)
+}
+
+func (s *ScopeMetrics) marshal() []byte {
+	var scope []byte
+	scope = appendString(scope, 1, s.Scope.Name)
+	scope = appendString(scope, 2, s.Scope.Version)
+
+	buf := appendMessage(nil, 1, scope)
+	for i := range s.Metrics {
+		buf = appendMessage(buf, 2, s.Metrics[i].marshal())
+	}
+	return buf
+}
+
+func (m *Metric) marshal() []byte {
+	var buf []byte
+	buf = appendString(buf, 1, m.Name)
+	buf = appendString(buf, 2, m.Description)
+
+	switch {
+	case m.Gauge != nil:
+		var gauge []byte
+		for i := range m.Gauge.DataPoints {
+			gauge = appendMessage(gauge, 1, m.Gauge.DataPoints[i].marshal())
+		}
+		buf = appendMessage(buf, 5, gauge)
+	case m.Sum != nil:
+		var sum []byte
+		for i := range m.Sum.DataPoints {
+			sum = appendMessage(sum, 1, m.Sum.DataPoints[i].marshal())
+		}
+		sum = appendVarint(sum, 2, uint64(m.Sum.AggregationTemporality))
+		if m.Sum.IsMonotonic {
+			sum = appendVarint(sum, 3, 1)
+		}
+		buf = appendMessage(buf, 7, sum)
+	case m.Histogram != nil:
+		var histogram []byte
+		for i := range m.Histogram.DataPoints {
+			histogram = appendMessage(histogram, 1, m.Histogram.DataPoints[i].marshal())
+		}
+		histogram = appendVarint(histogram, 2, uint64(m.Histogram.AggregationTemporality))
+		buf = appendMessage(buf, 9, histogram)
+	case m.Summary != nil:
+		var summary []byte
+		for i := range m.Summary.DataPoints {
+			summary = appendMessage(summary, 1, m.Summary.DataPoints[i].marshal())
+		}
+		buf = appendMessage(buf, 11, summary)
+	}
+	return buf
+}
+
+func (p *NumberDataPoint) marshal() []byte {
+	var buf []byte
+	buf = appendFixed64(buf, 2, p.StartTimeUnixNano)
+	buf = appendFixed64(buf, 3, p.TimeUnixNano)
+	buf = protowire.AppendTag(buf, 4, protowire.Fixed64Type)
+	buf = protowire.AppendFixed64(buf, math.Float64bits(p.AsDouble))
+	buf = appendAttributes(buf, 7, p.Attributes)
+	return buf
+}
+
+func (p *HistogramDataPoint) marshal() []byte {
+	var buf []byte
+	buf = appendFixed64(buf, 2, p.StartTimeUnixNano)
+	buf = appendFixed64(buf, 3, p.TimeUnixNano)
+	buf = protowire.AppendTag(buf, 4, protowire.Fixed64Type)
+	buf = protowire.AppendFixed64(buf, p.Count)
+	buf = protowire.AppendTag(buf, 5, protowire.Fixed64Type)
+	buf = protowire.AppendFixed64(buf, math.Float64bits(p.Sum))
+
+	var counts []byte
+	for _, count := range p.BucketCounts {
+		counts = protowire.AppendFixed64(counts, count)
+	}
+	buf = appendMessage(buf, 6, counts)
+
+	if len(p.ExplicitBounds) > 0 {
+		var bounds []byte
+		for _, bound := range p.ExplicitBounds {
+			bounds = protowire.AppendFixed64(bounds, math.Float64bits(bound))
+		}
+		buf = appendMessage(buf, 7, bounds)
+	}
+
+	buf = appendAttributes(buf, 9, p.Attributes)
+	return buf
+}
+
+func (p *SummaryDataPoint) marshal() []byte {
+	var buf []byte
+	buf = appendFixed64(buf, 2, p.StartTimeUnixNano)
+	buf = appendFixed64(buf, 3, p.TimeUnixNano)
+	buf = protowire.AppendTag(buf, 4, protowire.Fixed64Type)
+	buf = protowire.AppendFixed64(buf, p.Count)
+	buf = protowire.AppendTag(buf, 5, protowire.Fixed64Type)
+	buf = protowire.AppendFixed64(buf, math.Float64bits(p.Sum))
+
+	for _, quantile := range p.QuantileValues {
+		var q []byte
+		q = protowire.AppendTag(q, 1, protowire.Fixed64Type)
+		q = protowire.AppendFixed64(q, math.Float64bits(quantile.Quantile))
+		q = protowire.AppendTag(q, 2, protowire.Fixed64Type)
+		q = protowire.AppendFixed64(q, math.Float64bits(quantile.Value))
+		buf = appendMessage(buf, 6, q)
+	}
+
+	buf = appendAttributes(buf, 7, p.Attributes)
+	return buf
+}
+
+// appendAttributes appends KeyValue messages with string values
+func appendAttributes(buf []byte, num protowire.Number, attributes []KeyValue) []byte {
+	for _, attribute := range attributes {
+		value := appendString(nil, 1, attribute.Value.StringValue)
+
+		var kv []byte
+		kv = appendString(kv, 1, attribute.Key)
+		kv = appendMessage(kv, 2, value)
+		buf = appendMessage(buf, num, kv)
+	}
+	return buf
+}
+
+// appendMessage appends an embedded message or packed repeated field
+func appendMessage(buf []byte, num protowire.Number, message []byte) []byte {
+	buf = protowire.AppendTag(buf, num, protowire.BytesType)
+	return protowire.AppendBytes(buf, message)
+}
+
+// appendString appends a string field, omitting the default empty string
+func appendString(buf []byte, num protowire.Number, value string) []byte {
+	if value == "" {
+		return buf
+	}
+	buf = protowire.AppendTag(buf, num, protowire.BytesType)
+	return protowire.AppendString(buf, value)
+}
+
+// appendVarint appends a varint field, omitting the default zero
+func appendVarint(buf []byte, num protowire.Number, value uint64) []byte {
+	if value == 0 {
+		return buf
+	}
+	buf = protowire.AppendTag(buf, num, protowire.VarintType)
+	return protowire.AppendVarint(buf, value)
+}
+
+// appendFixed64 appends a fixed64 field, omitting the default zero
+func appendFixed64(buf []byte, num protowire.Number, value uint64) []byte {
+	if value == 0 {
+		return buf
+	}
+	buf = protowire.AppendTag(buf, num, protowire.Fixed64Type)
+	return protowire.AppendFixed64(buf, value)
+}
@@ -0,0 +1,110 @@
+package probe
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+	"go.uber.org/zap"
+
+	"github.com/grafana-cloud-k6-prometheus-exporter/internal/collector"
+)
+
+// Path the probe endpoint is served on
+const Path = "/probe"
+
+// Handler serves the metrics of a single target, selected with the stack and
+// project query parameters, in the style of the blackbox exporter. Every request
+// is collected into its own registry from the shared collector of the stack.
+type Handler struct {
+	collectors map[string]*collector.Collector
+	logger     *zap.Logger
+}
+
+// NewHandler creates the probe handler for the collectors of all stacks
+func NewHandler(collectors []*collector.Collector, logger *zap.Logger) *Handler {
+	h := &Handler{
+		collectors: make(map[string]*collector.Collector, len(collectors)),
+		logger:     logger.Named("probe"),
+	}
+	for _, c := range collectors {
+		h.collectors[c.Stack()] = c
+	}
+	return h
+}
+
+// ServeHTTP implements http.Handler
+func (h *Handler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+
+	c, status, err := h.target(query.Get("stack"))
+	if err != nil {
+		http.Error(w, err.Error(), status)
+		return
+	}
+
+	projects, err := parseProjects(query["project"])
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	probe, err := c.NewProbe(r.Context(), projects)
+	if errors.Is(err, collector.ErrProjectNotMonitored) || errors.Is(err, collector.ErrProjectNotFound) {
+		http.Error(w, fmt.Sprintf("unknown target: %v", err), http.StatusNotFound)
+		return
+	}
+	if err != nil {
+		h.logger.Warn("failed to look up probe target", zap.Error(err))
+		http.Error(w, err.Error(), http.StatusServiceUnavailable)
+		return
+	}
+
+	registry := prometheus.NewRegistry()
+	registry.MustRegister(probe)
+	promhttp.HandlerFor(registry, promhttp.HandlerOpts{ErrorLog: zap.NewStdLog(h.logger)}).ServeHTTP(w, r)
+}
+
+// target returns the collector of the stack. The stack may be omitted with a single stack.
+func (h *Handler) target(stack string) (*collector.Collector, int, error) {
+	if stack == "" {
+		if c, ok := h.collectors[""]; ok {
+			return c, 0, nil
+		}
+		if len(h.collectors) == 1 {
+			for _, c := range h.collectors {
+				return c, 0, nil
+			}
+		}
+		return nil, http.StatusBadRequest, fmt.Errorf("stack parameter is required")
+	}
+
+	c, ok := h.collectors[stack]
+	if !ok {
+		return nil, http.StatusNotFound, fmt.Errorf("unknown target: stack %q is not configured", stack)
+	}
+	return c, 0, nil
+}
+
+// parseProjects parses the project parameters, comma-separated or repeated
+func parseProjects(values []string) ([]int, error) {
+	var projects []int
+	for _, value := range values {
+		for _, project := range strings.Split(value, ",") {
+			project = strings.TrimSpace(project)
+			if project == "" {
+				continue
+			}
+			id, err := strconv.Atoi(project)
+			if err != nil {
+				return nil, fmt.Errorf("invalid project %q", project)
+			}
+			projects = append(projects, id)
+		}
+	}
+	return projects, nil
+}
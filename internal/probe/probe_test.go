@@ -0,0 +1,114 @@
+package probe
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap/zaptest"
+
+	"github.com/grafana-cloud-k6-prometheus-exporter/internal/collector"
+	"github.com/grafana-cloud-k6-prometheus-exporter/internal/config"
+	"github.com/grafana-cloud-k6-prometheus-exporter/internal/k6client"
+	"github.com/grafana-cloud-k6-prometheus-exporter/internal/state"
+)
+
+func newTestCollector(t *testing.T, stack string, projects []string) *collector.Collector {
+	logger := zaptest.NewLogger(t)
+	now := time.Now()
+
+	client := k6client.NewMockClient()
+	client.Projects = []k6client.Project{{ID: 100}, {ID: 200}, {ID: 300}}
+	client.Tests = []k6client.Test{
+		{ID: 1, Name: "Smoke", ProjectID: 100},
+		{ID: 2, Name: "Soak", ProjectID: 200},
+	}
+	client.TestRuns[1] = []k6client.TestRun{
+		{ID: 10, TestID: 1, ProjectID: 100, Status: k6client.StatusRunning, Created: now.Add(-10 * time.Minute)},
+	}
+	client.TestRuns[2] = []k6client.TestRun{
+		{ID: 20, TestID: 2, ProjectID: 200, Status: k6client.StatusRunning, Created: now.Add(-time.Minute)},
+	}
+
+	cfg := &config.Config{
+		TestCacheTTL:   60 * time.Second,
+		APITimeout:     30 * time.Second,
+		ScrapeInterval: time.Minute,
+		Projects:       projects,
+		StackName:      stack,
+	}
+	return collector.NewCollectorWithRegistry(client, state.NewManager(logger), cfg, logger, prometheus.NewRegistry())
+}
+
+func probe(h http.Handler, target string) *httptest.ResponseRecorder {
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, target, nil))
+	return rec
+}
+
+func TestProbe(t *testing.T) {
+	h := NewHandler([]*collector.Collector{newTestCollector(t, "", nil)}, zaptest.NewLogger(t))
+
+	rec := probe(h, "/probe")
+	require.Equal(t, http.StatusOK, rec.Code)
+	assert.Contains(t, rec.Body.String(), "k6_probe_success 1")
+	assert.Contains(t, rec.Body.String(), `run_id="10"`)
+	assert.Contains(t, rec.Body.String(), `run_id="20"`)
+
+	rec = probe(h, "/probe?project=200")
+	require.Equal(t, http.StatusOK, rec.Code)
+	assert.Contains(t, rec.Body.String(), "k6_probe_success 1")
+	assert.NotContains(t, rec.Body.String(), `project_id="100"`)
+	assert.Contains(t, rec.Body.String(), `run_id="20"`)
+
+	// Operational metrics of the exporter are not part of a probe
+	assert.NotContains(t, rec.Body.String(), "k6_exporter_")
+}
+
+func TestProbeTargets(t *testing.T) {
+	h := NewHandler([]*collector.Collector{
+		newTestCollector(t, "prod", []string{"100"}),
+		newTestCollector(t, "staging", nil),
+	}, zaptest.NewLogger(t))
+
+	tests := []struct {
+		name   string
+		target string
+		want   int
+	}{
+		{name: "stack", target: "/probe?stack=prod", want: http.StatusOK},
+		{name: "stack_and_project", target: "/probe?stack=prod&project=100", want: http.StatusOK},
+		{name: "project_with_tests_of_unrestricted_stack", target: "/probe?stack=staging&project=200", want: http.StatusOK},
+		{name: "project_without_tests_of_unrestricted_stack", target: "/probe?stack=staging&project=300", want: http.StatusOK},
+		{name: "unknown_project_of_unrestricted_stack", target: "/probe?stack=staging&project=999", want: http.StatusNotFound},
+		{name: "missing_stack", target: "/probe?project=100", want: http.StatusBadRequest},
+		{name: "invalid_project", target: "/probe?stack=prod&project=abc", want: http.StatusBadRequest},
+		{name: "unknown_stack", target: "/probe?stack=dev", want: http.StatusNotFound},
+		{name: "project_not_monitored", target: "/probe?stack=prod&project=200", want: http.StatusNotFound},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rec := probe(h, tt.target)
+			assert.Equal(t, tt.want, rec.Code, rec.Body.String())
+		})
+	}
+}
+
+func TestProbeProjectLookupFailure(t *testing.T) {
+	logger := zaptest.NewLogger(t)
+	client := k6client.NewMockClient()
+	client.ListProjectsError = fmt.Errorf("connection refused")
+	cfg := &config.Config{TestCacheTTL: 60 * time.Second, ScrapeInterval: time.Minute}
+	c := collector.NewCollectorWithRegistry(client, state.NewManager(logger), cfg, logger, prometheus.NewRegistry())
+	h := NewHandler([]*collector.Collector{c}, logger)
+
+	rec := probe(h, "/probe?project=100")
+	assert.Equal(t, http.StatusServiceUnavailable, rec.Code)
+	assert.Contains(t, rec.Body.String(), "list projects")
+}
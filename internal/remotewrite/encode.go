@@ -0,0 +1,151 @@
+package remotewrite
+
+import (
+	"math"
+	"sort"
+	"strconv"
+
+	dto "github.com/prometheus/client_model/go"
+	"google.golang.org/protobuf/encoding/protowire"
+)
+
+// Label is a name and value pair of a time series
+type Label struct {
+	Name  string
+	Value string
+}
+
+// Sample is a value at a time in Unix milliseconds
+type Sample struct {
+	Value     float64
+	Timestamp int64
+}
+
+// TimeSeries is a series with its labels, including __name__, sorted by name
+type TimeSeries struct {
+	Labels  []Label
+	Samples []Sample
+}
+
+// Convert flattens gathered metric families into time series the way Prometheus
+// stores them: summaries and histograms become their quantile or bucket, _sum and
+// _count series. Samples without a timestamp get nowMs.
+func Convert(families []*dto.MetricFamily, nowMs int64) []TimeSeries {
+	var series []TimeSeries
+
+	for _, family := range families {
+		name := family.GetName()
+		for _, metric := range family.GetMetric() {
+			ts := nowMs
+			if metric.TimestampMs != nil {
+				ts = metric.GetTimestampMs()
+			}
+			add := func(suffix string, value float64, extra ...Label) {
+				series = append(series, newSeries(name+suffix, metric.GetLabel(), extra, Sample{Value: value, Timestamp: ts}))
+			}
+
+			switch family.GetType() {
+			case dto.MetricType_COUNTER:
+				add("", metric.GetCounter().GetValue())
+			case dto.MetricType_GAUGE:
+				add("", metric.GetGauge().GetValue())
+			case dto.MetricType_UNTYPED:
+				add("", metric.GetUntyped().GetValue())
+			case dto.MetricType_SUMMARY:
+				summary := metric.GetSummary()
+				for _, quantile := range summary.GetQuantile() {
+					add("", quantile.GetValue(), Label{Name: "quantile", Value: formatFloat(quantile.GetQuantile())})
+				}
+				add("_sum", summary.GetSampleSum())
+				add("_count", float64(summary.GetSampleCount()))
+			case dto.MetricType_HISTOGRAM:
+				histogram := metric.GetHistogram()
+				infSeen := false
+				for _, bucket := range histogram.GetBucket() {
+					if math.IsInf(bucket.GetUpperBound(), +1) {
+						infSeen = true
+					}
+					add("_bucket", float64(bucket.GetCumulativeCount()), Label{Name: "le", Value: formatFloat(bucket.GetUpperBound())})
+				}
+				if !infSeen {
+					add("_bucket", float64(histogram.GetSampleCount()), Label{Name: "le", Value: "+Inf"})
+				}
+				add("_sum", histogram.GetSampleSum())
+				add("_count", float64(histogram.GetSampleCount()))
+			}
+		}
+	}
+
+	return series
+}
+
+// newSeries builds a series with its labels sorted by name
+func newSeries(name string, pairs []*dto.LabelPair, extra []Label, sample Sample) TimeSeries {
+	labels := make([]Label, 0, len(pairs)+len(extra)+1)
+	labels = append(labels, Label{Name: "__name__", Value: name})
+	for _, pair := range pairs {
+		labels = append(labels, Label{Name: pair.GetName(), Value: pair.GetValue()})
+	}
+	labels = append(labels, extra...)
+	sort.Slice(labels, func(i, j int) bool { return labels[i].Name < labels[j].Name })
+
+	return TimeSeries{Labels: labels, Samples: []Sample{sample}}
+}
+
+// formatFloat formats a quantile or bucket bound like the Prometheus text format
+func formatFloat(f float64) string {
+	if math.IsInf(f, +1) {
+		return "+Inf"
+	}
+	if math.IsInf(f, -1) {
+		return "-Inf"
+	}
+	return strconv.FormatFloat(f, 'g', -1, 64)
+}
+
+// Field numbers of the remote-write protobuf messages
+const (
+	writeRequestTimeseries = 1 // prometheus.WriteRequest.timeseries
+	timeSeriesLabels       = 1 // prometheus.TimeSeries.labels
+	timeSeriesSamples      = 2 // prometheus.TimeSeries.samples
+	labelName              = 1 // prometheus.Label.name
+	labelValue             = 2 // prometheus.Label.value
+	sampleValue            = 1 // prometheus.Sample.value
+	sampleTimestamp        = 2 // prometheus.Sample.timestamp
+)
+
+// Marshal encodes the series as a remote-write 1.0 prometheus.WriteRequest
+func Marshal(series []TimeSeries) []byte {
+	var buf []byte
+	for _, s := range series {
+		buf = protowire.AppendTag(buf, writeRequestTimeseries, protowire.BytesType)
+		buf = protowire.AppendBytes(buf, marshalSeries(s))
+	}
+	return buf
+}
+
+// marshalSeries encodes a prometheus.TimeSeries
+func marshalSeries(s TimeSeries) []byte {
+	var buf []byte
+	for _, label := range s.Labels {
+		var l []byte
+		l = protowire.AppendTag(l, labelName, protowire.BytesType)
+		l = protowire.AppendString(l, label.Name)
+		l = protowire.AppendTag(l, labelValue, protowire.BytesType)
+		l = protowire.AppendString(l, label.Value)
+
+		buf = protowire.AppendTag(buf, timeSeriesLabels, protowire.BytesType)
+		buf = protowire.AppendBytes(buf, l)
+	}
+	for _, sample := range s.Samples {
+		var smp []byte
+		smp = protowire.AppendTag(smp, sampleValue, protowire.Fixed64Type)
+		smp = protowire.AppendFixed64(smp, math.Float64bits(sample.Value))
+		smp = protowire.AppendTag(smp, sampleTimestamp, protowire.VarintType)
+		smp = protowire.AppendVarint(smp, uint64(sample.Timestamp))
+
+		buf = protowire.AppendTag(buf, timeSeriesSamples, protowire.BytesType)
+		buf = protowire.AppendBytes(buf, smp)
+	}
+	return buf
+}
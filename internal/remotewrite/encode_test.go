@@ -0,0 +1,115 @@
+package remotewrite
+
+import (
+	"math"
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/protobuf/encoding/protowire"
+)
+
+// unmarshal decodes a prometheus.WriteRequest encoded by Marshal
+func unmarshal(t *testing.T, data []byte) []TimeSeries {
+	var series []TimeSeries
+	for len(data) > 0 {
+		_, _, n := protowire.ConsumeTag(data)
+		require.GreaterOrEqual(t, n, 0)
+		seriesData, m := protowire.ConsumeBytes(data[n:])
+		require.GreaterOrEqual(t, m, 0)
+		data = data[n+m:]
+
+		var s TimeSeries
+		for len(seriesData) > 0 {
+			num, _, n := protowire.ConsumeTag(seriesData)
+			field, m := protowire.ConsumeBytes(seriesData[n:])
+			seriesData = seriesData[n+m:]
+
+			fields := consumeFields(t, field)
+			if num == timeSeriesLabels {
+				s.Labels = append(s.Labels, Label{Name: string(fields[labelName].([]byte)), Value: string(fields[labelValue].([]byte))})
+			} else {
+				s.Samples = append(s.Samples, Sample{
+					Value:     math.Float64frombits(fields[sampleValue].(uint64)),
+					Timestamp: int64(fields[sampleTimestamp].(uint64)),
+				})
+			}
+		}
+		series = append(series, s)
+	}
+	return series
+}
+
+// consumeFields decodes the scalar fields of a message
+func consumeFields(t *testing.T, data []byte) map[protowire.Number]interface{} {
+	fields := make(map[protowire.Number]interface{})
+	for len(data) > 0 {
+		num, typ, n := protowire.ConsumeTag(data)
+		require.GreaterOrEqual(t, n, 0)
+		data = data[n:]
+		switch typ {
+		case protowire.BytesType:
+			v, m := protowire.ConsumeBytes(data)
+			fields[num], data = v, data[m:]
+		case protowire.Fixed64Type:
+			v, m := protowire.ConsumeFixed64(data)
+			fields[num], data = v, data[m:]
+		case protowire.VarintType:
+			v, m := protowire.ConsumeVarint(data)
+			fields[num], data = v, data[m:]
+		default:
+			t.Fatalf("unexpected wire type %d", typ)
+		}
+	}
+	return fields
+}
+
+func TestConvert(t *testing.T) {
+	reg := prometheus.NewRegistry()
+	runs := prometheus.NewGaugeVec(prometheus.GaugeOpts{Name: "k6_test_runs_active", Help: "h"}, []string{"project_id", "status"})
+	runs.WithLabelValues("100", "running").Set(2)
+	duration := prometheus.NewHistogram(prometheus.HistogramOpts{Name: "k6_test_run_duration_seconds", Help: "h", Buckets: []float64{60, 600}})
+	duration.Observe(120)
+	reg.MustRegister(runs, duration)
+
+	families, err := reg.Gather()
+	require.NoError(t, err)
+	series := Convert(families, 1700000000000)
+
+	// 3 buckets, _sum and _count for the histogram and the gauge
+	require.Len(t, series, 6)
+
+	bySeries := make(map[string]float64)
+	for _, s := range series {
+		key := ""
+		for _, label := range s.Labels {
+			key += label.Name + "=" + label.Value + ","
+		}
+		require.Len(t, s.Samples, 1)
+		assert.Equal(t, int64(1700000000000), s.Samples[0].Timestamp)
+		bySeries[key] = s.Samples[0].Value
+	}
+
+	assert.Equal(t, 2.0, bySeries["__name__=k6_test_runs_active,project_id=100,status=running,"])
+	assert.Equal(t, 0.0, bySeries["__name__=k6_test_run_duration_seconds_bucket,le=60,"])
+	assert.Equal(t, 1.0, bySeries["__name__=k6_test_run_duration_seconds_bucket,le=600,"])
+	assert.Equal(t, 1.0, bySeries["__name__=k6_test_run_duration_seconds_bucket,le=+Inf,"])
+	assert.Equal(t, 120.0, bySeries["__name__=k6_test_run_duration_seconds_sum,"])
+	assert.Equal(t, 1.0, bySeries["__name__=k6_test_run_duration_seconds_count,"])
+}
+
+func TestMarshal(t *testing.T) {
+	series := []TimeSeries{
+		{
+			Labels:  []Label{{Name: "__name__", Value: "up"}, {Name: "job", Value: "k6"}},
+			Samples: []Sample{{Value: 1, Timestamp: 1700000000000}},
+		},
+		{
+			Labels:  []Label{{Name: "__name__", Value: "k6_vuh"}},
+			Samples: []Sample{{Value: 12.5, Timestamp: 1700000000001}},
+		},
+	}
+
+	assert.Equal(t, series, unmarshal(t, Marshal(series)))
+}
@@ -0,0 +1,254 @@
+package remotewrite
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/golang/snappy"
+	"github.com/prometheus/client_golang/prometheus"
+	"go.uber.org/zap"
+
+	"github.com/grafana-cloud-k6-prometheus-exporter/internal/config"
+)
+
+// Series sent per request, larger collections are split
+const maxSeriesPerRequest = 2000
+
+// Outcomes of write requests reported in metrics
+const (
+	OutcomeSuccess = "success"
+	OutcomeFailure = "failure"
+	OutcomeDropped = "dropped"
+)
+
+// Metrics holds the remote-write metrics
+type Metrics struct {
+	RequestsTotal *prometheus.CounterVec
+	SamplesTotal  prometheus.Counter
+	QueueLength   prometheus.Gauge
+}
+
+// NewMetrics creates the remote-write metrics and registers them if a registerer is provided
+func NewMetrics(reg prometheus.Registerer) *Metrics {
+	metrics := &Metrics{
+		RequestsTotal: prometheus.NewCounterVec(
+			prometheus.CounterOpts{
+				Name: "k6_exporter_remote_write_requests_total",
+				Help: "Total number of remote-write requests by outcome",
+			},
+			[]string{"outcome"},
+		),
+		SamplesTotal: prometheus.NewCounter(
+			prometheus.CounterOpts{
+				Name: "k6_exporter_remote_write_samples_total",
+				Help: "Total number of samples written successfully",
+			},
+		),
+		QueueLength: prometheus.NewGauge(
+			prometheus.GaugeOpts{
+				Name: "k6_exporter_remote_write_queue_length",
+				Help: "Number of remote-write requests waiting to be sent",
+			},
+		),
+	}
+
+	if reg != nil {
+		reg.MustRegister(
+			metrics.RequestsTotal,
+			metrics.SamplesTotal,
+			metrics.QueueLength,
+		)
+	}
+
+	return metrics
+}
+
+// write is a compressed request waiting to be sent
+type write struct {
+	body    []byte
+	samples int
+}
+
+// Pusher periodically gathers the registered metrics, the same series served on
+// /metrics, and pushes them to a Prometheus remote-write endpoint. Pending writes
+// are kept in a bounded in-memory queue, the oldest is dropped when it is full.
+type Pusher struct {
+	config   *config.Config
+	gatherer prometheus.Gatherer
+	client   *http.Client
+	queue    chan write
+	metrics  *Metrics
+	logger   *zap.Logger
+}
+
+// NewPusher creates a pusher for the remote-write endpoint of the configuration
+func NewPusher(cfg *config.Config, gatherer prometheus.Gatherer, logger *zap.Logger, reg prometheus.Registerer) *Pusher {
+	return &Pusher{
+		config:   cfg,
+		gatherer: gatherer,
+		client:   &http.Client{Timeout: cfg.RemoteWriteTimeout},
+		queue:    make(chan write, cfg.RemoteWriteQueueSize),
+		metrics:  NewMetrics(reg),
+		logger:   logger.Named("remotewrite"),
+	}
+}
+
+// Start gathers and pushes the metrics every interval until the context is cancelled
+func (p *Pusher) Start(ctx context.Context) {
+	p.logger.Info("remote-write enabled",
+		zap.String("url", p.config.RemoteWriteURL),
+		zap.Duration("interval", p.config.RemoteWriteInterval),
+	)
+
+	go p.send(ctx)
+	go func() {
+		ticker := time.NewTicker(p.config.RemoteWriteInterval)
+		defer ticker.Stop()
+
+		for {
+			if err := p.Collect(); err != nil {
+				p.logger.Error("failed to gather metrics for remote-write", zap.Error(err))
+			}
+
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+			}
+		}
+	}()
+}
+
+// Collect gathers the metrics once and queues them for sending
+func (p *Pusher) Collect() error {
+	families, err := p.gatherer.Gather()
+	if err != nil && len(families) == 0 {
+		return fmt.Errorf("gather metrics: %w", err)
+	}
+	if err != nil {
+		// Partial results are still worth sending
+		p.logger.Warn("some metrics could not be gathered", zap.Error(err))
+	}
+
+	series := Convert(families, time.Now().UnixMilli())
+	for start := 0; start < len(series); start += maxSeriesPerRequest {
+		end := start + maxSeriesPerRequest
+		if end > len(series) {
+			end = len(series)
+		}
+		p.enqueue(write{
+			body:    snappy.Encode(nil, Marshal(series[start:end])),
+			samples: end - start,
+		})
+	}
+	return nil
+}
+
+// enqueue adds a write, dropping the oldest one if the queue is full
+func (p *Pusher) enqueue(w write) {
+	for {
+		select {
+		case p.queue <- w:
+			p.metrics.QueueLength.Set(float64(len(p.queue)))
+			return
+		default:
+		}
+
+		select {
+		case <-p.queue:
+			p.metrics.RequestsTotal.WithLabelValues(OutcomeDropped).Inc()
+			p.logger.Warn("remote-write queue is full, dropping the oldest write")
+		default:
+		}
+	}
+}
+
+// send delivers the queued writes in order
+func (p *Pusher) send(ctx context.Context) {
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case w := <-p.queue:
+			p.metrics.QueueLength.Set(float64(len(p.queue)))
+
+			if err := p.deliver(ctx, w); err != nil {
+				p.metrics.RequestsTotal.WithLabelValues(OutcomeFailure).Inc()
+				p.logger.Error("failed to push metrics",
+					zap.Int("samples", w.samples),
+					zap.Error(err),
+				)
+				continue
+			}
+			p.metrics.RequestsTotal.WithLabelValues(OutcomeSuccess).Inc()
+			p.metrics.SamplesTotal.Add(float64(w.samples))
+		}
+	}
+}
+
+// deliver posts the write, retrying with exponential backoff on network errors,
+// rate limiting and server errors
+func (p *Pusher) deliver(ctx context.Context, w write) error {
+	backoff := p.config.RemoteWriteRetryBackoff
+	for attempt := 0; ; attempt++ {
+		retry, err := p.post(ctx, w.body)
+		if err == nil {
+			return nil
+		}
+		if !retry || attempt >= p.config.RemoteWriteMaxRetries {
+			return err
+		}
+
+		p.logger.Debug("retrying remote-write",
+			zap.Int("attempt", attempt+1),
+			zap.Duration("backoff", backoff),
+			zap.Error(err),
+		)
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(backoff):
+		}
+		backoff *= 2
+	}
+}
+
+// post sends the body once and returns whether a failure may be retried
+func (p *Pusher) post(ctx context.Context, body []byte) (bool, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, p.config.RemoteWriteURL, bytes.NewReader(body))
+	if err != nil {
+		return false, fmt.Errorf("create request: %w", err)
+	}
+
+	req.Header.Set("Content-Type", "application/x-protobuf")
+	req.Header.Set("Content-Encoding", "snappy")
+	req.Header.Set("X-Prometheus-Remote-Write-Version", "0.1.0")
+	req.Header.Set("User-Agent", "k6-prometheus-exporter")
+	for key, value := range p.config.RemoteWriteHeaders {
+		req.Header.Set(key, value)
+	}
+	if p.config.RemoteWriteUsername != "" {
+		req.SetBasicAuth(p.config.RemoteWriteUsername, p.config.RemoteWritePassword)
+	}
+
+	resp, err := p.client.Do(req)
+	if err != nil {
+		return true, fmt.Errorf("perform request: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= 200 && resp.StatusCode < 300 {
+		io.Copy(io.Discard, resp.Body)
+		return false, nil
+	}
+
+	message, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+	retry := resp.StatusCode == http.StatusTooManyRequests || resp.StatusCode >= 500
+	return retry, fmt.Errorf("unexpected status %d: %s", resp.StatusCode, strings.TrimSpace(string(message)))
+}
@@ -0,0 +1,135 @@
+package remotewrite
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/golang/snappy"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap/zaptest"
+
+	"github.com/grafana-cloud-k6-prometheus-exporter/internal/config"
+)
+
+// receiver records the requests of a test remote-write endpoint
+type receiver struct {
+	mu       sync.Mutex
+	requests []*http.Request
+	series   [][]TimeSeries
+	statuses []int // Status codes returned in order, 204 once exhausted
+}
+
+func (r *receiver) handler(t *testing.T) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		body, _ := io.ReadAll(req.Body)
+		data, err := snappy.Decode(nil, body)
+		require.NoError(t, err)
+
+		r.mu.Lock()
+		defer r.mu.Unlock()
+		r.requests = append(r.requests, req)
+		r.series = append(r.series, unmarshal(t, data))
+
+		status := http.StatusNoContent
+		if len(r.statuses) > 0 {
+			status = r.statuses[0]
+			r.statuses = r.statuses[1:]
+		}
+		w.WriteHeader(status)
+	})
+}
+
+func newTestPusher(t *testing.T, url string, gatherer prometheus.Gatherer) *Pusher {
+	cfg := &config.Config{
+		RemoteWriteURL:          url,
+		RemoteWriteInterval:     time.Hour,
+		RemoteWriteUsername:     "12345",
+		RemoteWritePassword:     "glc_token",
+		RemoteWriteHeaders:      map[string]string{"X-Scope-OrgID": "team-a"},
+		RemoteWriteTimeout:      time.Second,
+		RemoteWriteMaxRetries:   2,
+		RemoteWriteRetryBackoff: time.Millisecond,
+		RemoteWriteQueueSize:    2,
+	}
+	return NewPusher(cfg, gatherer, zaptest.NewLogger(t), prometheus.NewRegistry())
+}
+
+func testGatherer() prometheus.Gatherer {
+	reg := prometheus.NewRegistry()
+	gauge := prometheus.NewGauge(prometheus.GaugeOpts{Name: "k6_test_runs_active", Help: "h"})
+	gauge.Set(3)
+	reg.MustRegister(gauge)
+	return reg
+}
+
+func TestPusherPushes(t *testing.T) {
+	recv := &receiver{statuses: []int{http.StatusServiceUnavailable}}
+	server := httptest.NewServer(recv.handler(t))
+	defer server.Close()
+
+	pusher := newTestPusher(t, server.URL, testGatherer())
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	pusher.Start(ctx)
+
+	require.Eventually(t, func() bool {
+		return testutil.ToFloat64(pusher.metrics.RequestsTotal.WithLabelValues(OutcomeSuccess)) == 1
+	}, time.Second, 5*time.Millisecond)
+	assert.Equal(t, float64(1), testutil.ToFloat64(pusher.metrics.SamplesTotal))
+
+	recv.mu.Lock()
+	defer recv.mu.Unlock()
+	require.Len(t, recv.requests, 2, "the 503 is retried")
+	req := recv.requests[1]
+	assert.Equal(t, "snappy", req.Header.Get("Content-Encoding"))
+	assert.Equal(t, "application/x-protobuf", req.Header.Get("Content-Type"))
+	assert.Equal(t, "0.1.0", req.Header.Get("X-Prometheus-Remote-Write-Version"))
+	assert.Equal(t, "team-a", req.Header.Get("X-Scope-OrgID"))
+	username, password, ok := req.BasicAuth()
+	assert.True(t, ok)
+	assert.Equal(t, "12345", username)
+	assert.Equal(t, "glc_token", password)
+
+	require.Len(t, recv.series[1], 1)
+	assert.Equal(t, []Label{{Name: "__name__", Value: "k6_test_runs_active"}}, recv.series[1][0].Labels)
+	assert.Equal(t, 3.0, recv.series[1][0].Samples[0].Value)
+}
+
+func TestPusherClientErrorsAreNotRetried(t *testing.T) {
+	recv := &receiver{statuses: []int{http.StatusBadRequest}}
+	server := httptest.NewServer(recv.handler(t))
+	defer server.Close()
+
+	pusher := newTestPusher(t, server.URL, testGatherer())
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	pusher.Start(ctx)
+
+	require.Eventually(t, func() bool {
+		return testutil.ToFloat64(pusher.metrics.RequestsTotal.WithLabelValues(OutcomeFailure)) == 1
+	}, time.Second, 5*time.Millisecond)
+
+	recv.mu.Lock()
+	defer recv.mu.Unlock()
+	assert.Len(t, recv.requests, 1)
+}
+
+func TestPusherDropsOldestWhenQueueIsFull(t *testing.T) {
+	// Not started, so nothing drains the queue
+	pusher := newTestPusher(t, "http://127.0.0.1:1", testGatherer())
+
+	for i := 0; i < 5; i++ {
+		require.NoError(t, pusher.Collect())
+	}
+
+	assert.Equal(t, float64(3), testutil.ToFloat64(pusher.metrics.RequestsTotal.WithLabelValues(OutcomeDropped)))
+	assert.Equal(t, float64(2), testutil.ToFloat64(pusher.metrics.QueueLength))
+}
@@ -0,0 +1,36 @@
+package state
+
+// SetAnnotationID records the Grafana annotation created for a test run on a
+// dashboard, an empty dashboard UID is an organization-wide annotation
+func (m *Manager) SetAnnotationID(stack string, runID int, dashboardUID string, id int64) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	key := runKey{stack, runID}
+	ids, exists := m.annotations[key]
+	if !exists {
+		ids = make(map[string]int64)
+		m.annotations[key] = ids
+	}
+	ids[dashboardUID] = id
+}
+
+// GetAnnotationIDs returns the Grafana annotations of a test run by dashboard UID
+func (m *Manager) GetAnnotationIDs(stack string, runID int) map[string]int64 {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	key := runKey{stack, runID}
+	ids := make(map[string]int64, len(m.annotations[key]))
+	for dashboardUID, id := range m.annotations[key] {
+		ids[dashboardUID] = id
+	}
+	return ids
+}
+
+// RemoveAnnotationIDs forgets the Grafana annotations of a test run
+func (m *Manager) RemoveAnnotationIDs(stack string, runID int) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	delete(m.annotations, runKey{stack, runID})
+}
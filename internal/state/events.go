@@ -0,0 +1,95 @@
+package state
+
+import (
+	"time"
+)
+
+// Event types emitted by the Manager
+const (
+	EventRunStarted    = "run_started"
+	EventStatusChanged = "status_changed"
+	EventRunFinished   = "run_finished"
+	EventRunStuck      = "run_stuck"
+)
+
+// Event describes a lifecycle change of a test run
+type Event struct {
+	Type           string
+	RunID          int
+	TestID         int
+	ProjectID      int
+	TestName       string
+	Status         string
+	PreviousStatus string
+	Result         string
+	StartedBy      string
+	VUH            float64
+	Reason         string // Why the run is stuck, for run_stuck events
+	Stack          string // Name of the stack, empty with a single stack
+	Created        time.Time
+	Time           time.Time
+
+	// Names of failed thresholds, if reported, for run_finished events
+	FailedThresholds []string
+}
+
+// Listener receives test run events. Listeners are called synchronously
+// without holding the Manager lock and must not block.
+type Listener func(Event)
+
+// Subscribe registers a listener for test run events
+func (m *Manager) Subscribe(listener Listener) {
+	m.listenersMu.Lock()
+	defer m.listenersMu.Unlock()
+	m.listeners = append(m.listeners, listener)
+}
+
+// notify sends the event to all listeners
+func (m *Manager) notify(event Event) {
+	m.listenersMu.RLock()
+	defer m.listenersMu.RUnlock()
+
+	for _, listener := range m.listeners {
+		listener(event)
+	}
+}
+
+// newEvent creates an event for the test run
+func newEvent(eventType string, state *TestRunState, previousStatus string, at time.Time) *Event {
+	event := &Event{
+		Type:           eventType,
+		RunID:          state.TestRunID,
+		TestID:         state.TestID,
+		ProjectID:      state.ProjectID,
+		TestName:       state.TestName,
+		Status:         state.CurrentStatus,
+		PreviousStatus: previousStatus,
+		StartedBy:      state.StartedBy,
+		VUH:            state.VUH,
+		Stack:          state.Stack,
+		Created:        state.Created,
+		Time:           at,
+	}
+	if state.Result != nil {
+		event.Result = *state.Result
+	}
+	return event
+}
+
+// newFinishedEvent creates a run_finished event, aborted runs without a result report "aborted"
+func newFinishedEvent(state *TestRunState, previousStatus string) *Event {
+	at := time.Now()
+	if state.Ended != nil {
+		at = *state.Ended
+	}
+
+	event := newEvent(EventRunFinished, state, previousStatus, at)
+	event.FailedThresholds = state.FailedThresholds
+	if event.Result == "" {
+		event.Result = "unknown"
+		if state.CurrentStatus == "aborted" {
+			event.Result = "aborted"
+		}
+	}
+	return event
+}
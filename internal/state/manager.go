@@ -21,29 +21,67 @@ type TestRunState struct {
 	Result        *string
 	StartedBy     string
 	VUH           float64
+	StuckStatus   string // Status the run was last reported stuck in
+	Stack         string // Name of the stack the run belongs to, empty with a single stack
+
+	// Names of failed thresholds, if reported
+	FailedThresholds []string
+}
+
+// runKey identifies a test run, run IDs are only unique within a stack
+type runKey struct {
+	stack string
+	runID int
 }
 
 // Manager manages test run states to prevent duplicate counting
 type Manager struct {
-	mu     sync.RWMutex
-	states map[int]*TestRunState // Key is TestRunID
-	logger *zap.Logger
+	mu       sync.RWMutex
+	states   map[runKey]*TestRunState
+	finished map[runKey]time.Time // Finished runs already accounted for, run -> Ended
+	started  time.Time
+	logger   *zap.Logger
+
+	// Grafana annotation IDs of test runs, run -> dashboard UID ("" for organization-wide) -> ID.
+	// Kept apart from states since finished runs leave the state before their annotation is closed.
+	annotations map[runKey]map[string]int64
+
+	listenersMu sync.RWMutex
+	listeners   []Listener
 }
 
 // NewManager creates a new state manager
 func NewManager(logger *zap.Logger) *Manager {
 	return &Manager{
-		states: make(map[int]*TestRunState),
-		logger: logger,
+		states:   make(map[runKey]*TestRunState),
+		finished: make(map[runKey]time.Time),
+		started:  time.Now(),
+		logger:   logger,
+
+		annotations: make(map[runKey]map[string]int64),
 	}
 }
 
+// MarkRunFinished records that a finished test run of a stack has been accounted for
+// and returns true if this is the first time the run is marked
+func (m *Manager) MarkRunFinished(stack string, runID int, ended time.Time) bool {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	key := runKey{stack, runID}
+	if _, seen := m.finished[key]; seen {
+		return false
+	}
+	m.finished[key] = ended
+	return true
+}
+
 // RecordTestRunStatus records a test run status and returns true if this is a new status
-func (m *Manager) RecordTestRunStatus(runID int, status string) bool {
+func (m *Manager) RecordTestRunStatus(stack string, runID int, status string) bool {
 	m.mu.Lock()
 	defer m.mu.Unlock()
 
-	state, exists := m.states[runID]
+	state, exists := m.states[runKey{stack, runID}]
 	if !exists {
 		// This is a new test run we haven't seen before
 		m.logger.Debug("recording new test run",
@@ -72,39 +110,69 @@ func (m *Manager) RecordTestRunStatus(runID int, status string) bool {
 	return true
 }
 
-// UpdateTestRun updates or creates a test run state
+// UpdateTestRun updates or creates a test run state and notifies the listeners of any lifecycle change
 func (m *Manager) UpdateTestRun(state *TestRunState) {
 	m.mu.Lock()
-	defer m.mu.Unlock()
+	event := m.updateTestRun(state)
+	m.mu.Unlock()
 
+	if event != nil {
+		m.notify(*event)
+	}
+}
+
+// updateTestRun applies the update and returns the resulting event, if any. The caller must hold the lock.
+func (m *Manager) updateTestRun(state *TestRunState) *Event {
 	// Skip storing completed or aborted runs
+	key := runKey{state.Stack, state.TestRunID}
 	if state.CurrentStatus == "completed" || state.CurrentStatus == "aborted" {
 		// If we already have this run in state, remove it
-		if _, exists := m.states[state.TestRunID]; exists {
-			delete(m.states, state.TestRunID)
+		existing, exists := m.states[key]
+		if exists {
+			delete(m.states, key)
 			m.logger.Debug("removed completed test run from state",
 				zap.Int("run_id", state.TestRunID),
 				zap.String("status", state.CurrentStatus),
 			)
+			return newFinishedEvent(state, existing.CurrentStatus)
 		}
-		return
+
+		// Runs that started and finished between two updates are only reported
+		// if they ended while the manager was running
+		if state.Ended != nil && state.Ended.After(m.started) {
+			return newFinishedEvent(state, "")
+		}
+		return nil
 	}
 
-	existing, exists := m.states[state.TestRunID]
+	existing, exists := m.states[key]
 	if !exists {
-		// Initialize status history
-		state.StatusHistory = make(map[string]time.Time)
-		state.StatusHistory[state.CurrentStatus] = state.Created
+		// Initialize status history, keeping any status times reported by the API
+		history := make(map[string]time.Time, len(state.StatusHistory)+1)
+		for status, entered := range state.StatusHistory {
+			history[status] = entered
+		}
+		if _, known := history[state.CurrentStatus]; !known {
+			history[state.CurrentStatus] = state.Created
+		}
+		state.StatusHistory = history
 		state.LastUpdated = time.Now()
-		m.states[state.TestRunID] = state
+		m.states[key] = state
 		
 		m.logger.Debug("created new test run state",
 			zap.Int("run_id", state.TestRunID),
 			zap.String("status", state.CurrentStatus),
 		)
-		return
+
+		// Runs that were already active when the manager started are not announced
+		if !state.Created.After(m.started) {
+			return nil
+		}
+		return newEvent(EventRunStarted, state, "", state.Created)
 	}
 
+	previousStatus := existing.CurrentStatus
+
 	// Update existing state
 	existing.CurrentStatus = state.CurrentStatus
 	existing.LastUpdated = time.Now()
@@ -112,18 +180,55 @@ func (m *Manager) UpdateTestRun(state *TestRunState) {
 	existing.Result = state.Result
 	existing.VUH = state.VUH
 
-	// Record new status if we haven't seen it
+	// Record new status if we haven't seen it, preferring the time reported by the API
 	if _, seen := existing.StatusHistory[state.CurrentStatus]; !seen {
-		existing.StatusHistory[state.CurrentStatus] = time.Now()
+		if entered, reported := state.StatusHistory[state.CurrentStatus]; reported {
+			existing.StatusHistory[state.CurrentStatus] = entered
+		} else {
+			existing.StatusHistory[state.CurrentStatus] = time.Now()
+		}
+	}
+
+	if state.CurrentStatus == previousStatus {
+		return nil
 	}
+	return newEvent(EventStatusChanged, existing, previousStatus, existing.StatusHistory[state.CurrentStatus])
 }
 
-// GetTestRunState returns the state of a test run
-func (m *Manager) GetTestRunState(runID int) *TestRunState {
+// MarkRunStuck records that the tracked run exceeded the limit of its current status
+// and emits a run_stuck event. It returns false if the run is not tracked or was
+// already reported stuck in its current status.
+func (m *Manager) MarkRunStuck(stack string, runID int, reason string) bool {
+	m.mu.Lock()
+	state, exists := m.states[runKey{stack, runID}]
+	if !exists || state.StuckStatus == state.CurrentStatus {
+		m.mu.Unlock()
+		return false
+	}
+	state.StuckStatus = state.CurrentStatus
+	event := newEvent(EventRunStuck, state, "", time.Now())
+	event.Reason = reason
+	m.mu.Unlock()
+
+	m.notify(*event)
+	return true
+}
+
+// TimeInStatus returns how long the test run has been in its current status
+func (s *TestRunState) TimeInStatus(now time.Time) time.Duration {
+	entered, ok := s.StatusHistory[s.CurrentStatus]
+	if !ok {
+		entered = s.Created
+	}
+	return now.Sub(entered)
+}
+
+// GetTestRunState returns the state of a test run of a stack
+func (m *Manager) GetTestRunState(stack string, runID int) *TestRunState {
 	m.mu.RLock()
 	defer m.mu.RUnlock()
 
-	state, exists := m.states[runID]
+	state, exists := m.states[runKey{stack, runID}]
 	if !exists {
 		return nil
 	}
@@ -165,7 +270,7 @@ func (m *Manager) Cleanup(maxAge time.Duration) int {
 	cutoff := time.Now().Add(-maxAge)
 	removed := 0
 
-	for runID, state := range m.states {
+	for key, state := range m.states {
 		// Remove if:
 		// 1. The test run ended and it's older than maxAge
 		// 2. The test run hasn't been updated in maxAge (likely stuck/abandoned)
@@ -178,15 +283,31 @@ func (m *Manager) Cleanup(maxAge time.Duration) int {
 		}
 
 		if shouldRemove {
-			delete(m.states, runID)
+			delete(m.states, key)
 			removed++
 			m.logger.Debug("removed old test run state",
-				zap.Int("run_id", runID),
+				zap.Int("run_id", key.runID),
 				zap.Time("last_updated", state.LastUpdated),
 			)
 		}
 	}
 
+	// Forget finished runs once they are older than maxAge, they have left the fetch window
+	for key, ended := range m.finished {
+		if ended.Before(cutoff) {
+			delete(m.finished, key)
+		}
+	}
+
+	// Forget annotations of runs that are neither tracked nor recently finished
+	for key := range m.annotations {
+		_, tracked := m.states[key]
+		_, finished := m.finished[key]
+		if !tracked && !finished {
+			delete(m.annotations, key)
+		}
+	}
+
 	if removed > 0 {
 		m.logger.Info("cleaned up old test run states",
 			zap.Int("removed", removed),
@@ -204,12 +325,26 @@ func (m *Manager) GetStateCount() int {
 	return len(m.states)
 }
 
+// GetStateCountByStack returns the number of tracked test runs of a stack
+func (m *Manager) GetStateCountByStack(stack string) int {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	count := 0
+	for _, state := range m.states {
+		if state.Stack == stack {
+			count++
+		}
+	}
+	return count
+}
+
 // HasSeenStatus checks if we've already recorded a specific status for a test run
-func (m *Manager) HasSeenStatus(runID int, status string) bool {
+func (m *Manager) HasSeenStatus(stack string, runID int, status string) bool {
 	m.mu.RLock()
 	defer m.mu.RUnlock()
 
-	state, exists := m.states[runID]
+	state, exists := m.states[runKey{stack, runID}]
 	if !exists {
 		return false
 	}
@@ -237,13 +372,13 @@ func (m *Manager) CleanupCompletedRuns() int {
 	defer m.mu.Unlock()
 
 	removed := 0
-	for runID, state := range m.states {
+	for key, state := range m.states {
 		// Remove completed and aborted runs
 		if state.CurrentStatus == "completed" || state.CurrentStatus == "aborted" {
-			delete(m.states, runID)
+			delete(m.states, key)
 			removed++
 			m.logger.Debug("removed completed test run state",
-				zap.Int("run_id", runID),
+				zap.Int("run_id", key.runID),
 				zap.String("status", state.CurrentStatus),
 			)
 		}
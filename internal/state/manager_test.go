@@ -74,13 +74,13 @@ func TestRecordTestRunStatus(t *testing.T) {
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			gotNew := manager.RecordTestRunStatus(tt.runID, tt.status)
+			gotNew := manager.RecordTestRunStatus("", tt.runID, tt.status)
 			assert.Equal(t, tt.wantNew, gotNew, tt.desc)
 		})
 	}
 
 	// Verify state was properly recorded for run 1
-	state1 := manager.GetTestRunState(1)
+	state1 := manager.GetTestRunState("", 1)
 	require.NotNil(t, state1)
 	assert.Contains(t, state1.StatusHistory, "created")
 	assert.Contains(t, state1.StatusHistory, "running")
@@ -109,7 +109,7 @@ func TestUpdateTestRun(t *testing.T) {
 	manager.UpdateTestRun(state1)
 
 	// Verify initial state
-	retrieved := manager.GetTestRunState(1)
+	retrieved := manager.GetTestRunState("", 1)
 	require.NotNil(t, retrieved)
 	assert.Equal(t, "created", retrieved.CurrentStatus)
 	assert.Contains(t, retrieved.StatusHistory, "created")
@@ -129,7 +129,7 @@ func TestUpdateTestRun(t *testing.T) {
 	manager.UpdateTestRun(state2)
 
 	// Verify update
-	retrieved = manager.GetTestRunState(1)
+	retrieved = manager.GetTestRunState("", 1)
 	require.NotNil(t, retrieved)
 	assert.Equal(t, "running", retrieved.CurrentStatus)
 	assert.Contains(t, retrieved.StatusHistory, "created")
@@ -153,7 +153,7 @@ func TestUpdateTestRun(t *testing.T) {
 	manager.UpdateTestRun(state3)
 
 	// Verify the test run was removed from state (completed runs are not tracked)
-	retrieved = manager.GetTestRunState(1)
+	retrieved = manager.GetTestRunState("", 1)
 	assert.Nil(t, retrieved, "Completed test runs should be removed from state")
 	assert.Equal(t, 0, manager.GetStateCount(), "Manager should have no states after completing the only test run")
 }
@@ -182,7 +182,7 @@ func TestGetAllStates(t *testing.T) {
 
 	// Verify each state is a copy (not same reference)
 	for _, state := range states {
-		original := manager.states[state.TestRunID]
+		original := manager.states[runKey{"", state.TestRunID}]
 		assert.NotSame(t, state, original, "returned state should be a copy")
 		// Verify the map is also a copy by checking the addresses are different
 		assert.NotEqual(t, fmt.Sprintf("%p", state.StatusHistory), fmt.Sprintf("%p", original.StatusHistory), "status history should be a copy")
@@ -212,7 +212,7 @@ func TestCleanup(t *testing.T) {
 	manager.UpdateTestRun(state2)
 	// Manually set the LastUpdated to old time after UpdateTestRun
 	manager.mu.Lock()
-	manager.states[2].LastUpdated = oldTime
+	manager.states[runKey{"", 2}].LastUpdated = oldTime
 	manager.mu.Unlock()
 
 	// Add recent test run
@@ -237,8 +237,8 @@ func TestCleanup(t *testing.T) {
 	assert.Equal(t, 1, manager.GetStateCount())
 
 	// Verify only recent test run remains
-	assert.Nil(t, manager.GetTestRunState(2))
-	assert.NotNil(t, manager.GetTestRunState(3))
+	assert.Nil(t, manager.GetTestRunState("", 2))
+	assert.NotNil(t, manager.GetTestRunState("", 3))
 }
 
 func TestHasSeenStatus(t *testing.T) {
@@ -246,7 +246,7 @@ func TestHasSeenStatus(t *testing.T) {
 	manager := NewManager(logger)
 
 	// Test non-existent run
-	assert.False(t, manager.HasSeenStatus(1, "created"))
+	assert.False(t, manager.HasSeenStatus("", 1, "created"))
 
 	// Add a test run
 	state := &TestRunState{
@@ -259,16 +259,16 @@ func TestHasSeenStatus(t *testing.T) {
 	manager.UpdateTestRun(state)
 
 	// Test existing status
-	assert.True(t, manager.HasSeenStatus(1, "created"))
-	assert.False(t, manager.HasSeenStatus(1, "running"))
+	assert.True(t, manager.HasSeenStatus("", 1, "created"))
+	assert.False(t, manager.HasSeenStatus("", 1, "running"))
 
 	// Update to new status
 	state.CurrentStatus = "running"
 	manager.UpdateTestRun(state)
 
 	// Both statuses should be seen
-	assert.True(t, manager.HasSeenStatus(1, "created"))
-	assert.True(t, manager.HasSeenStatus(1, "running"))
+	assert.True(t, manager.HasSeenStatus("", 1, "created"))
+	assert.True(t, manager.HasSeenStatus("", 1, "running"))
 }
 
 func TestCleanupCompletedRuns(t *testing.T) {
@@ -377,4 +377,188 @@ func TestConcurrentAccess(t *testing.T) {
 
 	// Verify state
 	assert.Equal(t, 1000, manager.GetStateCount())
-}
\ No newline at end of file
+}
+
+func TestMarkRunFinished(t *testing.T) {
+	logger := zaptest.NewLogger(t)
+	manager := NewManager(logger)
+
+	now := time.Now()
+
+	assert.True(t, manager.MarkRunFinished("", 1, now), "first mark should be new")
+	assert.False(t, manager.MarkRunFinished("", 1, now), "second mark should be a duplicate")
+	assert.True(t, manager.MarkRunFinished("", 2, now.Add(-25*time.Hour)), "other run should be new")
+
+	// Cleanup forgets finished runs older than maxAge
+	manager.Cleanup(24 * time.Hour)
+	assert.False(t, manager.MarkRunFinished("", 1, now), "recent run should still be remembered")
+	assert.True(t, manager.MarkRunFinished("", 2, now.Add(-25*time.Hour)), "old run should have been forgotten")
+}
+
+func TestTimeInStatus(t *testing.T) {
+	logger := zaptest.NewLogger(t)
+	manager := NewManager(logger)
+
+	now := time.Now()
+	created := now.Add(-30 * time.Minute)
+	runningSince := now.Add(-10 * time.Minute)
+
+	// Status times reported by the API take precedence over the creation time
+	manager.UpdateTestRun(&TestRunState{
+		TestRunID:     1,
+		CurrentStatus: "running",
+		Created:       created,
+		StatusHistory: map[string]time.Time{
+			"created": created,
+			"running": runningSince,
+		},
+	})
+
+	state := manager.GetTestRunState("", 1)
+	require.NotNil(t, state)
+	assert.Equal(t, 10*time.Minute, state.TimeInStatus(now))
+
+	// Without a reported time the run is assumed to be in the status since it was created
+	manager.UpdateTestRun(&TestRunState{
+		TestRunID:     2,
+		CurrentStatus: "initializing",
+		Created:       created,
+	})
+
+	state = manager.GetTestRunState("", 2)
+	require.NotNil(t, state)
+	assert.Equal(t, 30*time.Minute, state.TimeInStatus(now))
+
+	// A status change picks up the time reported by the API
+	processingSince := now.Add(-1 * time.Minute)
+	manager.UpdateTestRun(&TestRunState{
+		TestRunID:     2,
+		CurrentStatus: "processing_metrics",
+		Created:       created,
+		StatusHistory: map[string]time.Time{"processing_metrics": processingSince},
+	})
+
+	state = manager.GetTestRunState("", 2)
+	require.NotNil(t, state)
+	assert.Equal(t, time.Minute, state.TimeInStatus(now))
+}
+
+func TestUpdateTestRunEvents(t *testing.T) {
+	logger := zaptest.NewLogger(t)
+	manager := NewManager(logger)
+
+	var events []Event
+	manager.Subscribe(func(event Event) {
+		events = append(events, event)
+	})
+
+	now := time.Now()
+	ended := now.Add(time.Minute)
+	result := "failed"
+
+	// Runs already active before the manager started are not announced
+	manager.UpdateTestRun(&TestRunState{TestRunID: 1, TestID: 10, CurrentStatus: "running", Created: now.Add(-time.Hour)})
+	assert.Empty(t, events)
+
+	manager.UpdateTestRun(&TestRunState{TestRunID: 2, TestID: 20, ProjectID: 200, TestName: "Smoke", CurrentStatus: "created", Created: now.Add(time.Second)})
+	manager.UpdateTestRun(&TestRunState{TestRunID: 2, TestID: 20, ProjectID: 200, TestName: "Smoke", CurrentStatus: "created", Created: now.Add(time.Second)})
+	manager.UpdateTestRun(&TestRunState{TestRunID: 2, TestID: 20, ProjectID: 200, TestName: "Smoke", CurrentStatus: "running", Created: now.Add(time.Second)})
+	manager.UpdateTestRun(&TestRunState{TestRunID: 2, TestID: 20, ProjectID: 200, TestName: "Smoke", CurrentStatus: "completed", Created: now.Add(time.Second), Ended: &ended, Result: &result})
+
+	// Untracked runs that ended while the manager was running are reported as finished
+	manager.UpdateTestRun(&TestRunState{TestRunID: 3, TestID: 30, CurrentStatus: "aborted", Created: now, Ended: &ended})
+
+	// Untracked runs that ended before are not
+	old := now.Add(-time.Hour)
+	manager.UpdateTestRun(&TestRunState{TestRunID: 4, TestID: 40, CurrentStatus: "completed", Created: old, Ended: &old})
+
+	require.Len(t, events, 4)
+	assert.Equal(t, EventRunStarted, events[0].Type)
+	assert.Equal(t, "Smoke", events[0].TestName)
+	assert.Equal(t, EventStatusChanged, events[1].Type)
+	assert.Equal(t, "created", events[1].PreviousStatus)
+	assert.Equal(t, "running", events[1].Status)
+	assert.Equal(t, EventRunFinished, events[2].Type)
+	assert.Equal(t, "running", events[2].PreviousStatus)
+	assert.Equal(t, "failed", events[2].Result)
+	assert.Equal(t, ended, events[2].Time)
+	assert.Equal(t, EventRunFinished, events[3].Type)
+	assert.Equal(t, 3, events[3].RunID)
+	assert.Equal(t, "aborted", events[3].Result)
+}
+
+func TestMarkRunStuck(t *testing.T) {
+	logger := zaptest.NewLogger(t)
+	manager := NewManager(logger)
+
+	var events []Event
+	manager.Subscribe(func(event Event) {
+		events = append(events, event)
+	})
+
+	manager.UpdateTestRun(&TestRunState{TestRunID: 1, CurrentStatus: "initializing", Created: time.Now().Add(-time.Hour)})
+
+	assert.False(t, manager.MarkRunStuck("", 2, "initializing_timeout"), "untracked runs cannot be stuck")
+	assert.True(t, manager.MarkRunStuck("", 1, "initializing_timeout"))
+	assert.False(t, manager.MarkRunStuck("", 1, "initializing_timeout"), "each status is only reported once")
+
+	// A new status can be reported stuck again
+	manager.UpdateTestRun(&TestRunState{TestRunID: 1, CurrentStatus: "running", Created: time.Now().Add(-time.Hour)})
+	assert.True(t, manager.MarkRunStuck("", 1, "running_timeout"))
+
+	require.Len(t, events, 3)
+	assert.Equal(t, EventRunStuck, events[0].Type)
+	assert.Equal(t, "initializing_timeout", events[0].Reason)
+	assert.Equal(t, EventStatusChanged, events[1].Type)
+	assert.Equal(t, EventRunStuck, events[2].Type)
+	assert.Equal(t, "running", events[2].Status)
+}
+
+func TestAnnotationIDs(t *testing.T) {
+	logger := zaptest.NewLogger(t)
+	manager := NewManager(logger)
+
+	manager.UpdateTestRun(&TestRunState{TestRunID: 1, CurrentStatus: "running", Created: time.Now()})
+	manager.SetAnnotationID("", 1, "", 10)
+	manager.SetAnnotationID("", 1, "checkout", 11)
+	manager.SetAnnotationID("", 2, "", 20)
+
+	assert.Equal(t, map[string]int64{"": 10, "checkout": 11}, manager.GetAnnotationIDs("", 1))
+
+	// Run 2 is neither tracked nor finished
+	manager.Cleanup(time.Hour)
+	assert.Len(t, manager.GetAnnotationIDs("", 1), 2)
+	assert.Empty(t, manager.GetAnnotationIDs("", 2))
+
+	manager.RemoveAnnotationIDs("", 1)
+	assert.Empty(t, manager.GetAnnotationIDs("", 1))
+}
+
+func TestRunsOfStacksAreSeparate(t *testing.T) {
+	logger := zaptest.NewLogger(t)
+	manager := NewManager(logger)
+	created := time.Now().Add(-time.Minute)
+
+	// Run IDs are only unique within a stack
+	manager.UpdateTestRun(&TestRunState{TestRunID: 1, Stack: "prod", CurrentStatus: "running", Created: created})
+	manager.UpdateTestRun(&TestRunState{TestRunID: 1, Stack: "staging", CurrentStatus: "initializing", Created: created})
+	assert.Equal(t, 2, manager.GetStateCount())
+	assert.Equal(t, "running", manager.GetTestRunState("prod", 1).CurrentStatus)
+	assert.Equal(t, "initializing", manager.GetTestRunState("staging", 1).CurrentStatus)
+	assert.Nil(t, manager.GetTestRunState("", 1))
+
+	assert.True(t, manager.MarkRunStuck("prod", 1, "running_timeout"))
+	assert.True(t, manager.MarkRunStuck("staging", 1, "initializing_timeout"))
+
+	assert.True(t, manager.MarkRunFinished("prod", 2, time.Now()))
+	assert.True(t, manager.MarkRunFinished("staging", 2, time.Now()))
+
+	manager.SetAnnotationID("prod", 1, "", 10)
+	manager.SetAnnotationID("staging", 1, "", 20)
+	assert.Equal(t, map[string]int64{"": 10}, manager.GetAnnotationIDs("prod", 1))
+	assert.Equal(t, map[string]int64{"": 20}, manager.GetAnnotationIDs("staging", 1))
+
+	manager.UpdateTestRun(&TestRunState{TestRunID: 1, Stack: "prod", CurrentStatus: "completed", Created: created})
+	assert.Nil(t, manager.GetTestRunState("prod", 1))
+	assert.NotNil(t, manager.GetTestRunState("staging", 1))
+}
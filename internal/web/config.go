@@ -0,0 +1,123 @@
+package web
+
+import (
+	"crypto/tls"
+	"fmt"
+	"os"
+
+	"golang.org/x/crypto/bcrypt"
+	"gopkg.in/yaml.v3"
+)
+
+// Config is the web configuration file, in the format used by the Prometheus exporter-toolkit
+type Config struct {
+	TLSServerConfig *TLSConfig        `yaml:"tls_server_config"`
+	BasicAuthUsers  map[string]string `yaml:"basic_auth_users"` // Username to bcrypt password hash
+}
+
+// TLSConfig configures TLS for the HTTP server
+type TLSConfig struct {
+	CertFile       string `yaml:"cert_file"`
+	KeyFile        string `yaml:"key_file"`
+	ClientCAFile   string `yaml:"client_ca_file"`
+	ClientAuthType string `yaml:"client_auth_type"`
+	MinVersion     string `yaml:"min_version"`
+}
+
+// Supported client_auth_type values
+var clientAuthTypes = map[string]tls.ClientAuthType{
+	"NoClientCert":               tls.NoClientCert,
+	"RequestClientCert":          tls.RequestClientCert,
+	"RequireAnyClientCert":       tls.RequireAnyClientCert,
+	"VerifyClientCertIfGiven":    tls.VerifyClientCertIfGiven,
+	"RequireAndVerifyClientCert": tls.RequireAndVerifyClientCert,
+}
+
+// Supported min_version values
+var tlsVersions = map[string]uint16{
+	"TLS12": tls.VersionTLS12,
+	"TLS13": tls.VersionTLS13,
+}
+
+// LoadConfig reads and validates a web configuration file
+func LoadConfig(path string) (*Config, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("read web config: %w", err)
+	}
+
+	var cfg Config
+	if err := yaml.Unmarshal(data, &cfg); err != nil {
+		return nil, fmt.Errorf("parse web config: %w", err)
+	}
+
+	if err := cfg.Validate(); err != nil {
+		return nil, fmt.Errorf("invalid web config: %w", err)
+	}
+
+	return &cfg, nil
+}
+
+// Validate checks the web configuration
+func (c *Config) Validate() error {
+	for user, hash := range c.BasicAuthUsers {
+		if _, err := bcrypt.Cost([]byte(hash)); err != nil {
+			return fmt.Errorf("basic_auth_users: password of %q is not a bcrypt hash", user)
+		}
+	}
+
+	if c.TLSServerConfig == nil {
+		return nil
+	}
+
+	tlsCfg := c.TLSServerConfig
+	if tlsCfg.CertFile == "" || tlsCfg.KeyFile == "" {
+		return fmt.Errorf("tls_server_config: cert_file and key_file are required")
+	}
+	if _, err := tlsCfg.clientAuth(); err != nil {
+		return err
+	}
+	if _, err := tlsCfg.minVersion(); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// TLSEnabled returns true if the server must serve TLS
+func (c *Config) TLSEnabled() bool {
+	return c.TLSServerConfig != nil
+}
+
+// clientAuth returns the client certificate policy, client certificates are
+// verified by default when a client CA is configured
+func (t *TLSConfig) clientAuth() (tls.ClientAuthType, error) {
+	if t.ClientAuthType == "" {
+		if t.ClientCAFile != "" {
+			return tls.RequireAndVerifyClientCert, nil
+		}
+		return tls.NoClientCert, nil
+	}
+
+	authType, ok := clientAuthTypes[t.ClientAuthType]
+	if !ok {
+		return tls.NoClientCert, fmt.Errorf("tls_server_config: invalid client_auth_type %q", t.ClientAuthType)
+	}
+	if t.ClientCAFile == "" && (authType == tls.VerifyClientCertIfGiven || authType == tls.RequireAndVerifyClientCert) {
+		return tls.NoClientCert, fmt.Errorf("tls_server_config: client_ca_file is required for client_auth_type %q", t.ClientAuthType)
+	}
+	return authType, nil
+}
+
+// minVersion returns the minimum TLS version, TLS 1.2 by default
+func (t *TLSConfig) minVersion() (uint16, error) {
+	if t.MinVersion == "" {
+		return tls.VersionTLS12, nil
+	}
+
+	version, ok := tlsVersions[t.MinVersion]
+	if !ok {
+		return 0, fmt.Errorf("tls_server_config: invalid min_version %q", t.MinVersion)
+	}
+	return version, nil
+}
@@ -0,0 +1,117 @@
+package web
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// bcrypt hash of "secret"
+const secretHash = "$2a$04$HsdS4B4eqKZdPw83lhuoxuYs0N6/8Vi3MlwMvNLOXWB6w6aIjCEOq"
+
+func TestLoadConfig(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+		wantErr bool
+		errMsg  string
+		verify  func(t *testing.T, cfg *Config)
+	}{
+		{
+			name: "tls_and_basic_auth",
+			content: `
+tls_server_config:
+  cert_file: /etc/exporter/tls.crt
+  key_file: /etc/exporter/tls.key
+  client_ca_file: /etc/exporter/ca.crt
+basic_auth_users:
+  prometheus: ` + secretHash + `
+`,
+			verify: func(t *testing.T, cfg *Config) {
+				assert.True(t, cfg.TLSEnabled())
+				assert.Equal(t, "/etc/exporter/tls.crt", cfg.TLSServerConfig.CertFile)
+				assert.Equal(t, secretHash, cfg.BasicAuthUsers["prometheus"])
+			},
+		},
+		{
+			name: "basic_auth_only",
+			content: `
+basic_auth_users:
+  prometheus: ` + secretHash + `
+`,
+			verify: func(t *testing.T, cfg *Config) {
+				assert.False(t, cfg.TLSEnabled())
+			},
+		},
+		{
+			name: "plain_password",
+			content: `
+basic_auth_users:
+  prometheus: secret
+`,
+			wantErr: true,
+			errMsg:  "is not a bcrypt hash",
+		},
+		{
+			name: "missing_key_file",
+			content: `
+tls_server_config:
+  cert_file: /etc/exporter/tls.crt
+`,
+			wantErr: true,
+			errMsg:  "cert_file and key_file are required",
+		},
+		{
+			name: "invalid_client_auth_type",
+			content: `
+tls_server_config:
+  cert_file: /etc/exporter/tls.crt
+  key_file: /etc/exporter/tls.key
+  client_auth_type: Sometimes
+`,
+			wantErr: true,
+			errMsg:  "invalid client_auth_type",
+		},
+		{
+			name: "verify_without_client_ca",
+			content: `
+tls_server_config:
+  cert_file: /etc/exporter/tls.crt
+  key_file: /etc/exporter/tls.key
+  client_auth_type: RequireAndVerifyClientCert
+`,
+			wantErr: true,
+			errMsg:  "client_ca_file is required",
+		},
+		{
+			name: "unknown_min_version",
+			content: `
+tls_server_config:
+  cert_file: /etc/exporter/tls.crt
+  key_file: /etc/exporter/tls.key
+  min_version: TLS10
+`,
+			wantErr: true,
+			errMsg:  "invalid min_version",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "web.yaml")
+			require.NoError(t, os.WriteFile(path, []byte(tt.content), 0o600))
+
+			cfg, err := LoadConfig(path)
+			if tt.wantErr {
+				require.Error(t, err)
+				assert.Contains(t, err.Error(), tt.errMsg)
+				return
+			}
+			require.NoError(t, err)
+			tt.verify(t, cfg)
+		})
+	}
+}
@@ -0,0 +1,226 @@
+package web
+
+import (
+	"crypto/sha256"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/hex"
+	"fmt"
+	"net/http"
+	"os"
+	"sync"
+	"time"
+
+	"go.uber.org/zap"
+	"golang.org/x/crypto/bcrypt"
+)
+
+// Hash compared against for unknown users, so they take as long to reject as wrong passwords
+const dummyHash = "$2a$10$S4dYSbRi0P1MOEWrsqVc6.N4z.LbkX475urzLu8Bx5kyoYT.d9SqS"
+
+// Apply configures TLS on the server and wraps its handler with basic auth.
+// A nil config leaves the server unchanged.
+func Apply(srv *http.Server, cfg *Config, logger *zap.Logger) error {
+	if cfg == nil {
+		return nil
+	}
+
+	if len(cfg.BasicAuthUsers) > 0 {
+		srv.Handler = newBasicAuth(cfg.BasicAuthUsers, srv.Handler)
+	}
+
+	if !cfg.TLSEnabled() {
+		return nil
+	}
+
+	tlsConfig, err := newTLSConfig(cfg.TLSServerConfig, logger)
+	if err != nil {
+		return err
+	}
+	srv.TLSConfig = tlsConfig
+
+	return nil
+}
+
+// ListenAndServe starts the server, serving TLS if the config enables it
+func ListenAndServe(srv *http.Server, cfg *Config) error {
+	if cfg != nil && cfg.TLSEnabled() {
+		// Certificates are provided by the TLS config
+		return srv.ListenAndServeTLS("", "")
+	}
+	return srv.ListenAndServe()
+}
+
+// newTLSConfig builds the server TLS config
+func newTLSConfig(cfg *TLSConfig, logger *zap.Logger) (*tls.Config, error) {
+	clientAuth, err := cfg.clientAuth()
+	if err != nil {
+		return nil, err
+	}
+	minVersion, err := cfg.minVersion()
+	if err != nil {
+		return nil, err
+	}
+
+	reloader, err := newCertReloader(cfg.CertFile, cfg.KeyFile, logger)
+	if err != nil {
+		return nil, err
+	}
+
+	tlsConfig := &tls.Config{
+		MinVersion:     minVersion,
+		ClientAuth:     clientAuth,
+		GetCertificate: reloader.GetCertificate,
+	}
+
+	if cfg.ClientCAFile != "" {
+		caPEM, err := os.ReadFile(cfg.ClientCAFile)
+		if err != nil {
+			return nil, fmt.Errorf("read client CA file: %w", err)
+		}
+		pool := x509.NewCertPool()
+		if !pool.AppendCertsFromPEM(caPEM) {
+			return nil, fmt.Errorf("no certificates found in client CA file %s", cfg.ClientCAFile)
+		}
+		tlsConfig.ClientCAs = pool
+	}
+
+	return tlsConfig, nil
+}
+
+// certReloader serves the TLS certificate and reloads it when the files change
+type certReloader struct {
+	certFile string
+	keyFile  string
+	logger   *zap.Logger
+
+	mu      sync.Mutex
+	cert    *tls.Certificate
+	certMod time.Time
+	keyMod  time.Time
+}
+
+// newCertReloader loads the certificate and key
+func newCertReloader(certFile, keyFile string, logger *zap.Logger) (*certReloader, error) {
+	r := &certReloader{
+		certFile: certFile,
+		keyFile:  keyFile,
+		logger:   logger,
+	}
+
+	certMod, keyMod, err := r.modTimes()
+	if err != nil {
+		return nil, err
+	}
+	if err := r.load(certMod, keyMod); err != nil {
+		return nil, err
+	}
+	return r, nil
+}
+
+// GetCertificate returns the current certificate, reloading it first if the files changed.
+// The previous certificate is kept if the new files cannot be loaded.
+func (r *certReloader) GetCertificate(*tls.ClientHelloInfo) (*tls.Certificate, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	certMod, keyMod, err := r.modTimes()
+	if err != nil {
+		r.logger.Error("failed to check TLS certificate", zap.Error(err))
+		return r.cert, nil
+	}
+
+	if !certMod.Equal(r.certMod) || !keyMod.Equal(r.keyMod) {
+		if err := r.load(certMod, keyMod); err != nil {
+			r.logger.Error("failed to reload TLS certificate", zap.Error(err))
+		} else {
+			r.logger.Info("reloaded TLS certificate", zap.String("cert_file", r.certFile))
+		}
+	}
+
+	return r.cert, nil
+}
+
+// load reads the certificate and key pair
+func (r *certReloader) load(certMod, keyMod time.Time) error {
+	cert, err := tls.LoadX509KeyPair(r.certFile, r.keyFile)
+	if err != nil {
+		return fmt.Errorf("load TLS certificate: %w", err)
+	}
+
+	r.cert = &cert
+	r.certMod = certMod
+	r.keyMod = keyMod
+	return nil
+}
+
+// modTimes returns the modification times of the certificate and key files
+func (r *certReloader) modTimes() (time.Time, time.Time, error) {
+	certInfo, err := os.Stat(r.certFile)
+	if err != nil {
+		return time.Time{}, time.Time{}, fmt.Errorf("stat cert file: %w", err)
+	}
+	keyInfo, err := os.Stat(r.keyFile)
+	if err != nil {
+		return time.Time{}, time.Time{}, fmt.Errorf("stat key file: %w", err)
+	}
+	return certInfo.ModTime(), keyInfo.ModTime(), nil
+}
+
+// basicAuth requires HTTP basic auth against bcrypt password hashes
+type basicAuth struct {
+	users map[string]string
+	next  http.Handler
+
+	// Verified credentials, bcrypt is too slow to run on every scrape
+	mu       sync.Mutex
+	verified map[string]bool
+}
+
+// newBasicAuth wraps the handler with basic auth
+func newBasicAuth(users map[string]string, next http.Handler) *basicAuth {
+	return &basicAuth{
+		users:    users,
+		next:     next,
+		verified: make(map[string]bool),
+	}
+}
+
+// ServeHTTP implements http.Handler
+func (a *basicAuth) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	user, password, ok := r.BasicAuth()
+	if !ok || !a.authenticate(user, password) {
+		w.Header().Set("WWW-Authenticate", `Basic realm="k6-exporter"`)
+		http.Error(w, http.StatusText(http.StatusUnauthorized), http.StatusUnauthorized)
+		return
+	}
+
+	a.next.ServeHTTP(w, r)
+}
+
+// authenticate checks the credentials against the configured users
+func (a *basicAuth) authenticate(user, password string) bool {
+	sum := sha256.Sum256([]byte(user + ":" + password))
+	key := hex.EncodeToString(sum[:])
+
+	a.mu.Lock()
+	verified := a.verified[key]
+	a.mu.Unlock()
+	if verified {
+		return true
+	}
+
+	hash, exists := a.users[user]
+	if !exists {
+		hash = dummyHash
+	}
+	err := bcrypt.CompareHashAndPassword([]byte(hash), []byte(password))
+	if !exists || err != nil {
+		return false
+	}
+
+	a.mu.Lock()
+	a.verified[key] = true
+	a.mu.Unlock()
+	return true
+}